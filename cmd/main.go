@@ -2,50 +2,354 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
+	"flag"
+	"fmt"
 	"log"
+	"log/slog"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
+	"github.com/Ant-Tab-Shift/todos-service/internal/config"
 	"github.com/Ant-Tab-Shift/todos-service/internal/domain"
+	"github.com/Ant-Tab-Shift/todos-service/internal/health"
+	"github.com/Ant-Tab-Shift/todos-service/internal/infrastructure/eventbus"
+	"github.com/Ant-Tab-Shift/todos-service/internal/infrastructure/outbox"
 	"github.com/Ant-Tab-Shift/todos-service/internal/infrastructure/storage"
+	"github.com/Ant-Tab-Shift/todos-service/internal/logging"
+	"github.com/Ant-Tab-Shift/todos-service/internal/metrics"
+	"github.com/Ant-Tab-Shift/todos-service/internal/tracing"
+	"github.com/Ant-Tab-Shift/todos-service/internal/transport/graphql"
 	"github.com/Ant-Tab-Shift/todos-service/internal/transport/http/handlers"
+	"github.com/Ant-Tab-Shift/todos-service/internal/transport/http/openapi"
 	"github.com/Ant-Tab-Shift/todos-service/internal/transport/http/server"
+	"github.com/Ant-Tab-Shift/todos-service/internal/transport/jsonrpc"
+	"github.com/Ant-Tab-Shift/todos-service/internal/transport/ws"
 	"github.com/Ant-Tab-Shift/todos-service/internal/usecases"
+	"github.com/Ant-Tab-Shift/todos-service/internal/usecases/rules"
 )
 
 func main() {
-	storage := storage.NewInMemory[domain.TaskSchema]()
+	args := os.Args[1:]
+	// в distroless-образе нет curl, поэтому проба контейнера — сам бинарник
+	if len(args) > 0 && args[0] == "healthcheck" {
+		os.Exit(healthcheck(args[1:]))
+	}
+
+	cfg, configPath, err := config.Load(args, os.LookupEnv)
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		log.Fatalf("Invalid configuration:\n%v", err)
+	}
+
+	// уровень логирования меняется при перезагрузке конфигурации
+	var level slog.LevelVar
+	parsedLevel, _ := logging.ParseLevel(cfg.Log.Level)
+	level.Set(parsedLevel)
+	logger, err := logging.New(os.Stdout, &level, cfg.Log.Format)
+	if err != nil {
+		log.Fatalf("Invalid log format: %v", err)
+	}
+	slog.SetDefault(logger)
+	slog.Info("configuration loaded", "path", configPath, "addr", cfg.HTTP.Addr, "storage", cfg.Storage.Backend)
+
+	tracer, err := newTracer(cfg.Tracing)
+	if err != nil {
+		fatal("failed to configure tracing", err)
+	}
+	tracing.SetDefault(tracer)
+
+	events, err := outbox.Open(cfg.Storage.OutboxPath)
+	if err != nil {
+		fatal("failed to open outbox", err)
+	}
+	defer events.Close()
+
+	registry := metrics.NewRegistry()
+	metrics.RegisterRuntime(registry)
+
+	memory := storage.NewCountingInMemory(func(task domain.TaskSchema) bool { return task.IsDone })
+	store := storage.NewInstrumented[domain.TaskSchema](memory, registry)
+	registerTaskMetrics(registry, memory)
+
+	bus := eventbus.New()
+	hub := ws.NewHub()
+	if err := bus.SubscribeAsync("websocket", hub.HandleEvent, 256, eventbus.DropOldest); err != nil {
+		fatal("failed to subscribe websocket hub", err)
+	}
 
-	service := usecases.NewTaskService(storage)
+	engine, err := newRulesEngine(cfg.Validation, store)
+	if err != nil {
+		fatal("failed to build validation rules", err)
+	}
+
+	service := usecases.NewTaskServiceWithRules(store, events, engine)
 
 	handler := handlers.NewTaskHandler(service)
+	// рукопожатие WebSocket браузер по CORS не проверяет, поэтому origin сверяет сам обработчик
+	cors := server.NewCORS(newCORSConfig(cfg.CORS))
+	wsHandler := ws.NewHandler(service, hub, cors.AllowsOrigin)
+	rpcHandler := jsonrpc.NewHandler(service)
+	graphqlHandler := graphql.NewHandler(service)
+	metricsHandler := metrics.NewHandler(registry)
+	// снимок берётся под блокировкой самого хранилища, поэтому без обёртки с метриками
+	adminHandler := handlers.NewAdminHandler(memory)
+
+	relay := outbox.NewRelay(events, bus, time.Second)
+
+	checker := health.NewChecker(2 * time.Second)
+	checker.Register("storage", memory.Ping)
+	checker.Register("event_bus", bus.Check)
+	checker.Register("outbox_relay", relay.Check)
+	healthHandler := health.NewHandler(checker)
+
+	openapiHandler, err := openapi.NewHandler(
+		openapi.Info{Title: "todos-service", Version: "1.0.0"},
+		handler, wsHandler, rpcHandler, graphqlHandler, metricsHandler, healthHandler, adminHandler,
+	)
+	if err != nil {
+		fatal("failed to generate OpenAPI specification", err)
+	}
 
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer cancel()
+	// readiness падает сразу по сигналу, не дожидаясь основной горутины
+	context.AfterFunc(ctx, checker.Drain)
+
+	rateLimitConfig, err := newRateLimitConfig(cfg.RateLimit)
+	if err != nil {
+		fatal("invalid rate limit configuration", err)
+	}
+	limiter := server.NewRateLimiter(rateLimitConfig)
+	admin := server.NewAdminAccess(cfg.Admin.Identities)
+
+	serverConfig := server.Config{
+		Addr:               cfg.HTTP.Addr,
+		ReadTimeout:        time.Duration(cfg.HTTP.ReadTimeout),
+		WriteTimeout:       time.Duration(cfg.HTTP.WriteTimeout),
+		IdleTimeout:        time.Duration(cfg.HTTP.IdleTimeout),
+		RateLimiter:        limiter,
+		CORS:               cors,
+		Timeouts:           newTimeoutConfig(cfg.RequestTimeout),
+		CompressionMinSize: cfg.Compression.MinSize,
+		CacheControl:       cfg.CacheControl,
+		Admin:              admin,
+	}
+	if cfg.TLS.Enabled() {
+		serverConfig.TLS, err = server.NewTLSConfig(server.TLSConfig{
+			CertFile:       cfg.TLS.CertFile,
+			KeyFile:        cfg.TLS.KeyFile,
+			ClientCAFile:   cfg.TLS.ClientCAFile,
+			ReloadInterval: time.Duration(cfg.TLS.ReloadInterval),
+		})
+		if err != nil {
+			fatal("failed to configure TLS", err)
+		}
+		serverConfig.RequireClientCert = cfg.TLS.ClientCAFile != ""
+		serverConfig.ClientCertExempt = []string{"/healthz", "/readyz"}
+	}
+
+	server := server.New(ctx, serverConfig, registry)
+	server.RegisterHandlers(handler, wsHandler, rpcHandler, graphqlHandler, metricsHandler, healthHandler, adminHandler, openapiHandler)
+
+	reloader := config.NewReloader(cfg, func() (config.Config, error) {
+		next, _, err := config.Load(args, os.LookupEnv)
+		return next, err
+	})
+	reloader.Register("log", func(next config.Config) (func(), error) {
+		parsed, err := logging.ParseLevel(next.Log.Level)
+		if err != nil {
+			return nil, err
+		}
+		return func() { level.Set(parsed) }, nil
+	})
+	reloader.Register("validation", func(next config.Config) (func(), error) {
+		engine, err := newRulesEngine(next.Validation, store)
+		if err != nil {
+			return nil, err
+		}
+		return func() { service.SetRules(engine) }, nil
+	})
+	reloader.Register("rate_limit", func(next config.Config) (func(), error) {
+		rateLimitConfig, err := newRateLimitConfig(next.RateLimit)
+		if err != nil {
+			return nil, err
+		}
+		return func() { limiter.SetConfig(rateLimitConfig) }, nil
+	})
+	reloader.Register("cors", func(next config.Config) (func(), error) {
+		corsConfig := newCORSConfig(next.CORS)
+		return func() { cors.SetConfig(corsConfig) }, nil
+	})
+	reloader.Register("admin", func(next config.Config) (func(), error) {
+		return func() { admin.SetIdentities(next.Admin.Identities) }, nil
+	})
+
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	go reloader.Run(ctx, hup)
 
-	server := server.New(ctx, ":8080")
-	server.RegisterHandlers(handler)
+	relayDone := make(chan struct{})
+	go func() {
+		defer close(relayDone)
+		relay.Run(ctx)
+	}()
 
 	go func() {
-		log.Println("Starting server")
+		slog.Info("starting server", "addr", cfg.HTTP.Addr, "tls", cfg.TLS.Enabled(), "mtls", cfg.TLS.ClientCAFile != "")
 		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("Server error: %v", err)
+			fatal("server error", err)
 		}
 	}()
 
 	<-ctx.Done()
-	log.Println("Received signal to start graceful shutdown...")
+	drainDelay := time.Duration(cfg.HTTP.DrainDelay)
+	slog.Info("received signal, starting graceful shutdown", "drain_delay", drainDelay)
+	time.Sleep(drainDelay)
 
-	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 10*time.Second)
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), time.Duration(cfg.HTTP.ShutdownTimeout))
 	defer shutdownCancel()
 
 	if err := server.Shutdown(shutdownCtx); err != nil {
-		log.Printf("Error during shutdown: %v", err)
+		slog.Error("error during shutdown", logging.Err(err))
+		return
+	}
+
+	<-relayDone
+	if err := relay.Flush(shutdownCtx); err != nil {
+		slog.Error("error while relaying pending events", logging.Err(err))
+	}
+
+	if err := bus.Close(shutdownCtx); err != nil {
+		slog.Error("error while draining events", logging.Err(err))
 		return
 	}
 
-	log.Println("Server stopped gracefully")
+	if err := tracer.Shutdown(shutdownCtx); err != nil {
+		slog.Error("error while exporting spans", logging.Err(err))
+	}
+
+	slog.Info("server stopped gracefully")
+}
+
+func newRulesEngine(cfg config.Validation, tasks rules.TaskLister) (*rules.Engine, error) {
+	var rulesConfig rules.Config
+	if cfg.RulesPath != "" {
+		var err error
+		rulesConfig, err = rules.Load(cfg.RulesPath)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return rules.New(rulesConfig, tasks)
+}
+
+func newRateLimitConfig(cfg config.RateLimit) (server.RateLimitConfig, error) {
+	proxies, err := config.ParsePrefixes(cfg.TrustedProxies)
+	if err != nil {
+		return server.RateLimitConfig{}, err
+	}
+
+	routes := make(map[string]server.Limit, len(cfg.Routes))
+	for route, limit := range cfg.Routes {
+		routes[route] = server.Limit{Rate: limit.Rate, Burst: limit.Burst}
+	}
+
+	return server.RateLimitConfig{
+		Default:        server.Limit{Rate: cfg.Default.Rate, Burst: cfg.Default.Burst},
+		Routes:         routes,
+		TrustedProxies: proxies,
+		IdleTimeout:    time.Duration(cfg.IdleTimeout),
+	}, nil
+}
+
+func newTimeoutConfig(cfg config.RequestTimeout) server.TimeoutConfig {
+	routes := make(map[string]time.Duration, len(cfg.Routes))
+	for route, timeout := range cfg.Routes {
+		routes[route] = time.Duration(timeout)
+	}
+
+	return server.TimeoutConfig{Default: time.Duration(cfg.Default), Routes: routes}
+}
+
+func newCORSConfig(cfg config.CORS) server.CORSConfig {
+	return server.CORSConfig{
+		AllowedOrigins:   cfg.AllowedOrigins,
+		AllowedMethods:   cfg.AllowedMethods,
+		AllowedHeaders:   cfg.AllowedHeaders,
+		ExposedHeaders:   cfg.ExposedHeaders,
+		AllowCredentials: cfg.AllowCredentials,
+		MaxAge:           time.Duration(cfg.MaxAge),
+	}
+}
+
+func newTracer(cfg config.Tracing) (*tracing.Tracer, error) {
+	switch cfg.Exporter {
+	case config.ExporterNone:
+		return tracing.NewTracer(nil), nil
+	case config.ExporterStdout:
+		return tracing.NewTracer(tracing.NewStdoutExporter(os.Stdout)), nil
+	case config.ExporterOTLP:
+		return tracing.NewTracer(tracing.NewOTLPExporter(cfg.OTLPEndpoint, "todos-service", nil)), nil
+	default:
+		return nil, fmt.Errorf("unknown trace exporter %q", cfg.Exporter)
+	}
+}
+
+func healthcheck(args []string) int {
+	cfg, _, err := config.Load(args, os.LookupEnv)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	host, port, _ := net.SplitHostPort(cfg.HTTP.Addr)
+	if host == "" || net.ParseIP(host).IsUnspecified() {
+		host = "localhost"
+	}
+	client := &http.Client{Timeout: 3 * time.Second}
+	scheme := "http"
+	if cfg.TLS.Enabled() {
+		scheme = "https"
+		// проба обращается к самому себе, проверять имя в сертификате незачем
+		client.Transport = &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}
+	}
+
+	resp, err := client.Get(scheme + "://" + net.JoinHostPort(host, port) + "/readyz")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		fmt.Fprintln(os.Stderr, "unhealthy:", resp.Status)
+		return 1
+	}
+	return 0
+}
+
+func fatal(msg string, err error) {
+	slog.Error(msg, logging.Err(err))
+	os.Exit(1)
+}
+
+func registerTaskMetrics(registry *metrics.Registry, store *storage.InMemory[domain.TaskSchema]) {
+	registry.NewGaugeFunc("todos_tasks", "Number of stored tasks by done state.", []string{"state"}, func() []metrics.Sample {
+		// хранилище само считает выполненные задачи, поэтому сбор метрик его не обходит
+		total, done := store.Count()
+
+		return []metrics.Sample{
+			{LabelValues: []string{"done"}, Value: float64(done)},
+			{LabelValues: []string{"open"}, Value: float64(total - done)},
+		}
+	})
 }
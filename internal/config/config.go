@@ -0,0 +1,468 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Ant-Tab-Shift/todos-service/internal/logging"
+)
+
+const (
+	BackendMemory = "memory"
+
+	ExporterNone   = "none"
+	ExporterStdout = "stdout"
+	ExporterOTLP   = "otlp"
+)
+
+type Config struct {
+	HTTP       HTTP       `json:"http"`
+	Storage    Storage    `json:"storage"`
+	Log        Log        `json:"log"`
+	Tracing    Tracing    `json:"tracing"`
+	Validation Validation `json:"validation"`
+	RateLimit  RateLimit  `json:"rate_limit"`
+	CORS       CORS       `json:"cors"`
+	TLS        TLS        `json:"tls"`
+	// RequestTimeout ограничивает обработку запроса, в отличие от сетевых таймаутов HTTP.
+	RequestTimeout RequestTimeout `json:"request_timeout"`
+	Compression    Compression    `json:"compression"`
+	// CacheControl задаёт заголовок Cache-Control успешных ответов по шаблону маршрута.
+	CacheControl map[string]string `json:"cache_control"`
+	Admin        Admin             `json:"admin"`
+}
+
+type HTTP struct {
+	Addr            string   `json:"addr"`
+	ReadTimeout     Duration `json:"read_timeout"`
+	WriteTimeout    Duration `json:"write_timeout"`
+	IdleTimeout     Duration `json:"idle_timeout"`
+	ShutdownTimeout Duration `json:"shutdown_timeout"`
+	DrainDelay      Duration `json:"drain_delay"`
+}
+
+type Storage struct {
+	Backend    string `json:"backend"`
+	OutboxPath string `json:"outbox_path"`
+}
+
+type Log struct {
+	Level  string `json:"level"`
+	Format string `json:"format"`
+}
+
+type Tracing struct {
+	Exporter     string `json:"exporter"`
+	OTLPEndpoint string `json:"otlp_endpoint"`
+}
+
+type Validation struct {
+	RulesPath string `json:"rules_path"`
+}
+
+type RequestTimeout struct {
+	Default Duration            `json:"default"`
+	Routes  map[string]Duration `json:"routes"`
+}
+
+// Compression.MinSize — минимальный размер ответа для сжатия, 0 отключает сжатие.
+type Compression struct {
+	MinSize int `json:"min_size"`
+}
+
+type Limit struct {
+	Rate  float64 `json:"rate"`
+	Burst int     `json:"burst"`
+}
+
+type RateLimit struct {
+	Default        Limit            `json:"default"`
+	Routes         map[string]Limit `json:"routes"`
+	TrustedProxies []string         `json:"trusted_proxies"`
+	IdleTimeout    Duration         `json:"idle_timeout"`
+}
+
+type CORS struct {
+	AllowedOrigins   []string `json:"allowed_origins"`
+	AllowedMethods   []string `json:"allowed_methods"`
+	AllowedHeaders   []string `json:"allowed_headers"`
+	ExposedHeaders   []string `json:"exposed_headers"`
+	AllowCredentials bool     `json:"allow_credentials"`
+	MaxAge           Duration `json:"max_age"`
+}
+
+type TLS struct {
+	CertFile       string   `json:"cert_file"`
+	KeyFile        string   `json:"key_file"`
+	ClientCAFile   string   `json:"client_ca_file"`
+	ReloadInterval Duration `json:"reload_interval"`
+}
+
+// Admin.Identities — субъекты клиентских сертификатов с доступом к /admin/, например "CN=admin,O=Acme".
+// Задаются только в файле: субъекты содержат запятые.
+type Admin struct {
+	Identities []string `json:"identities"`
+}
+
+func (t TLS) Enabled() bool {
+	return t.CertFile != ""
+}
+
+// Duration читается из JSON строкой в формате time.ParseDuration ("5s", "1m30s").
+type Duration time.Duration
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("duration must be a string like \"5s\"")
+	}
+
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+
+	return nil
+}
+
+func Default() Config {
+	return Config{
+		HTTP: HTTP{
+			Addr:            ":8080",
+			ReadTimeout:     Duration(5 * time.Second),
+			WriteTimeout:    Duration(5 * time.Second),
+			IdleTimeout:     Duration(10 * time.Second),
+			ShutdownTimeout: Duration(10 * time.Second),
+		},
+		Storage: Storage{
+			Backend:    BackendMemory,
+			OutboxPath: "data/outbox.jsonl",
+		},
+		Log: Log{
+			Level:  "info",
+			Format: logging.FormatJSON,
+		},
+		Tracing: Tracing{
+			Exporter:     ExporterNone,
+			OTLPEndpoint: "http://localhost:4318/v1/traces",
+		},
+		RateLimit: RateLimit{
+			IdleTimeout: Duration(5 * time.Minute),
+		},
+		CORS: CORS{
+			AllowedHeaders: []string{"Content-Type", "X-Request-ID", "traceparent", "tracestate"},
+			ExposedHeaders: []string{
+				"X-Request-ID", "traceparent", "Retry-After",
+				"RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset",
+			},
+			MaxAge: Duration(10 * time.Minute),
+		},
+		TLS: TLS{
+			ReloadInterval: Duration(30 * time.Second),
+		},
+		RequestTimeout: RequestTimeout{
+			Default: Duration(4 * time.Second),
+			// потоки длятся, пока клиент передаёт данные; простой ограничивают дедлайны соединения
+			Routes: map[string]Duration{
+				"GET /todos/export":   0,
+				"POST /todos/import":  0,
+				"POST /admin/backup":  0,
+				"POST /admin/restore": 0,
+			},
+		},
+		Compression: Compression{
+			MinSize: 1024,
+		},
+		// no-cache разрешает хранить ответ, но требует сверки по ETag перед использованием
+		CacheControl: map[string]string{
+			"GET /todos":      "private, no-cache",
+			"GET /todos/{id}": "private, no-cache",
+		},
+	}
+}
+
+// Load собирает конфигурацию слоями: значения по умолчанию, файл, переменные
+// окружения и флаги командной строки — каждый следующий слой перекрывает предыдущий.
+func Load(args []string, lookupEnv func(string) (string, bool)) (Config, string, error) {
+	fs := flag.NewFlagSet("todos-service", flag.ContinueOnError)
+	configPath := fs.String("config", "", "path to JSON config file (env CONFIG_PATH)")
+	for _, s := range settings {
+		fs.String(s.flag, "", s.usage+" (env "+s.env+")")
+	}
+	if err := fs.Parse(args); err != nil {
+		return Config{}, "", fmt.Errorf("invalid command line: %w", err)
+	}
+	if fs.NArg() > 0 {
+		return Config{}, "", fmt.Errorf("invalid command line: unexpected argument %q", fs.Arg(0))
+	}
+
+	path := *configPath
+	if path == "" {
+		path, _ = lookupEnv("CONFIG_PATH")
+	}
+
+	cfg := Default()
+	if path != "" {
+		if err := cfg.loadFile(path); err != nil {
+			return Config{}, "", err
+		}
+	}
+
+	var errs []error
+	for _, s := range settings {
+		if value, ok := lookupEnv(s.env); ok {
+			if err := s.apply(&cfg, value); err != nil {
+				errs = append(errs, fmt.Errorf("env %s: %w", s.env, err))
+			}
+		}
+	}
+
+	// Visit обходит флаги по алфавиту, а применять их нужно в порядке settings,
+	// как и переменные: иначе -port перекрывал бы более точный -addr
+	flags := make(map[string]string)
+	fs.Visit(func(f *flag.Flag) {
+		flags[f.Name] = f.Value.String()
+	})
+	for _, s := range settings {
+		if value, ok := flags[s.flag]; ok {
+			if err := s.apply(&cfg, value); err != nil {
+				errs = append(errs, fmt.Errorf("flag -%s: %w", s.flag, err))
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return Config{}, "", errors.Join(errs...)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return Config{}, "", err
+	}
+
+	return cfg, path, nil
+}
+
+// LoadFile читает только файл поверх значений по умолчанию.
+func LoadFile(path string) (Config, error) {
+	cfg := Default()
+	if err := cfg.loadFile(path); err != nil {
+		return Config{}, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return Config{}, err
+	}
+
+	return cfg, nil
+}
+
+func (c *Config) loadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config: %w", err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err = decoder.Decode(c); err != nil {
+		return fmt.Errorf("invalid config in %s: %w", path, err)
+	}
+
+	return nil
+}
+
+func (c Config) Validate() error {
+	var errs []error
+	fail := func(field, format string, args ...any) {
+		errs = append(errs, fmt.Errorf("%s: %s", field, fmt.Sprintf(format, args...)))
+	}
+
+	if _, port, err := net.SplitHostPort(c.HTTP.Addr); err != nil {
+		fail("http.addr", "must be host:port, got %q", c.HTTP.Addr)
+	} else if n, err := strconv.Atoi(port); err != nil || n < 0 || n > 65535 {
+		fail("http.addr", "invalid port %q", port)
+	}
+
+	for _, d := range []struct {
+		field string
+		value Duration
+	}{
+		{"http.read_timeout", c.HTTP.ReadTimeout},
+		{"http.write_timeout", c.HTTP.WriteTimeout},
+		{"http.idle_timeout", c.HTTP.IdleTimeout},
+		{"http.drain_delay", c.HTTP.DrainDelay},
+	} {
+		if d.value < 0 {
+			fail(d.field, "must not be negative")
+		}
+	}
+	if c.HTTP.ShutdownTimeout <= 0 {
+		fail("http.shutdown_timeout", "must be positive")
+	}
+
+	if c.Storage.Backend != BackendMemory {
+		fail("storage.backend", "unknown backend %q, want %s", c.Storage.Backend, BackendMemory)
+	}
+	if c.Storage.OutboxPath == "" {
+		fail("storage.outbox_path", "must not be empty")
+	}
+
+	if _, err := logging.ParseLevel(c.Log.Level); err != nil {
+		fail("log.level", "%v", err)
+	}
+	if c.Log.Format != logging.FormatJSON && c.Log.Format != logging.FormatText {
+		fail("log.format", "unknown format %q, want %s or %s", c.Log.Format, logging.FormatJSON, logging.FormatText)
+	}
+
+	switch c.Tracing.Exporter {
+	case ExporterNone, ExporterStdout:
+	case ExporterOTLP:
+		if u, err := url.Parse(c.Tracing.OTLPEndpoint); err != nil || u.Scheme == "" || u.Host == "" {
+			fail("tracing.otlp_endpoint", "must be an absolute URL, got %q", c.Tracing.OTLPEndpoint)
+		}
+	default:
+		fail("tracing.exporter", "unknown exporter %q, want none, stdout or otlp", c.Tracing.Exporter)
+	}
+
+	validateTimeout := func(field string, d Duration) {
+		if d < 0 {
+			fail(field, "must not be negative")
+		}
+		// иначе сервер оборвёт соединение раньше, чем клиент получит 504
+		if c.HTTP.WriteTimeout > 0 && d >= c.HTTP.WriteTimeout {
+			fail(field, "must be less than http.write_timeout (%s)", time.Duration(c.HTTP.WriteTimeout))
+		}
+	}
+	validateTimeout("request_timeout.default", c.RequestTimeout.Default)
+	for _, route := range sortedRoutes(c.RequestTimeout.Routes) {
+		if !validRoute(route) {
+			fail("request_timeout.routes", "route %q must look like \"POST /todos\"", route)
+		}
+		validateTimeout(fmt.Sprintf("request_timeout.routes[%q]", route), c.RequestTimeout.Routes[route])
+	}
+
+	if c.Compression.MinSize < 0 {
+		fail("compression.min_size", "must not be negative")
+	}
+
+	for _, route := range sortedRoutes(c.CacheControl) {
+		method, _, _ := strings.Cut(route, " ")
+		if !validRoute(route) || (method != "GET" && method != "HEAD") {
+			fail("cache_control", "route %q must look like \"GET /todos\"", route)
+		}
+		if strings.TrimSpace(c.CacheControl[route]) == "" {
+			fail(fmt.Sprintf("cache_control[%q]", route), "must not be empty")
+		}
+	}
+
+	validateLimit := func(field string, l Limit) {
+		if l.Rate < 0 {
+			fail(field+".rate", "must not be negative")
+		}
+		if l.Rate > 0 && l.Burst < 1 {
+			fail(field+".burst", "must be at least 1 when rate is set")
+		}
+	}
+	validateLimit("rate_limit.default", c.RateLimit.Default)
+	for _, route := range sortedRoutes(c.RateLimit.Routes) {
+		if !validRoute(route) {
+			fail("rate_limit.routes", "route %q must look like \"POST /todos\"", route)
+		}
+		validateLimit(fmt.Sprintf("rate_limit.routes[%q]", route), c.RateLimit.Routes[route])
+	}
+	if _, err := ParsePrefixes(c.RateLimit.TrustedProxies); err != nil {
+		fail("rate_limit.trusted_proxies", "%v", err)
+	}
+	if c.RateLimit.IdleTimeout <= 0 {
+		fail("rate_limit.idle_timeout", "must be positive")
+	}
+
+	for _, origin := range c.CORS.AllowedOrigins {
+		if origin == "*" {
+			if c.CORS.AllowCredentials {
+				fail("cors.allowed_origins", `"*" cannot be combined with allow_credentials`)
+			}
+			continue
+		}
+		if u, err := url.Parse(origin); err != nil || u.Scheme == "" || u.Host == "" || u.Path != "" || u.RawQuery != "" {
+			fail("cors.allowed_origins", "origin %q must look like \"https://example.com\"", origin)
+		}
+	}
+	for _, method := range c.CORS.AllowedMethods {
+		if method == "" || method != strings.ToUpper(method) || strings.ContainsAny(method, " ,") {
+			fail("cors.allowed_methods", "invalid method %q", method)
+		}
+	}
+	if c.CORS.MaxAge < 0 {
+		fail("cors.max_age", "must not be negative")
+	}
+
+	if (c.TLS.CertFile == "") != (c.TLS.KeyFile == "") {
+		fail("tls", "cert_file and key_file must be set together")
+	}
+	if c.TLS.ClientCAFile != "" && !c.TLS.Enabled() {
+		fail("tls.client_ca_file", "requires cert_file and key_file")
+	}
+	if c.TLS.ReloadInterval <= 0 {
+		fail("tls.reload_interval", "must be positive")
+	}
+
+	for _, id := range c.Admin.Identities {
+		if strings.TrimSpace(id) == "" {
+			fail("admin.identities", "must not contain empty entries")
+		}
+	}
+	// без mTLS у запроса нет субъекта, и список администраторов ничего бы не разрешил
+	if len(c.Admin.Identities) > 0 && c.TLS.ClientCAFile == "" {
+		fail("admin.identities", "requires tls.client_ca_file")
+	}
+
+	return errors.Join(errs...)
+}
+
+func validRoute(route string) bool {
+	method, path, ok := strings.Cut(route, " ")
+	return ok && method != "" && strings.HasPrefix(path, "/")
+}
+
+func sortedRoutes[V any](routes map[string]V) []string {
+	keys := make([]string, 0, len(routes))
+	for route := range routes {
+		keys = append(keys, route)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// ParsePrefixes разбирает адреса и подсети CIDR; адрес без маски — подсеть из одного адреса.
+func ParsePrefixes(values []string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(values))
+	for _, value := range values {
+		if addr, err := netip.ParseAddr(value); err == nil {
+			prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+
+		prefix, err := netip.ParsePrefix(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid address or CIDR %q", value)
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+
+	return prefixes, nil
+}
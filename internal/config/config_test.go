@@ -0,0 +1,267 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func env(values map[string]string) func(string) (string, bool) {
+	return func(key string) (string, bool) {
+		value, ok := values[key]
+		return value, ok
+	}
+}
+
+func writeFile(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	return path
+}
+
+func TestLoad_Defaults(t *testing.T) {
+	cfg, path, err := Load(nil, env(nil))
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	if path != "" {
+		t.Errorf("path = %q, want empty", path)
+	}
+	if !reflect.DeepEqual(cfg, Default()) {
+		t.Errorf("cfg = %+v, want defaults", cfg)
+	}
+}
+
+func TestLoad_Layering(t *testing.T) {
+	path := writeFile(t, `{
+		"http": {"addr": ":9000", "read_timeout": "7s", "shutdown_timeout": "30s"},
+		"log": {"level": "debug", "format": "text"}
+	}`)
+
+	cfg, gotPath, err := Load(
+		[]string{"-log-level", "error", "-read-timeout", "1s"},
+		env(map[string]string{"CONFIG_PATH": path, "LOG_LEVEL": "warn", "HTTP_ADDR": ":9100"}),
+	)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	if gotPath != path {
+		t.Errorf("path = %q, want %q", gotPath, path)
+	}
+	tests := []struct {
+		name string
+		got  any
+		want any
+	}{
+		{"default survives", cfg.HTTP.IdleTimeout, Duration(10 * time.Second)},
+		{"file overrides default", cfg.HTTP.ShutdownTimeout, Duration(30 * time.Second)},
+		{"file value kept", cfg.Log.Format, "text"},
+		{"env overrides file", cfg.HTTP.Addr, ":9100"},
+		{"flag overrides env", cfg.Log.Level, "error"},
+		{"flag overrides file", cfg.HTTP.ReadTimeout, Duration(time.Second)},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestLoad_PortAndAddr(t *testing.T) {
+	cfg, _, err := Load(nil, env(map[string]string{"PORT": "3000"}))
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.HTTP.Addr != ":3000" {
+		t.Errorf("Addr = %q, want :3000", cfg.HTTP.Addr)
+	}
+
+	cfg, _, err = Load(nil, env(map[string]string{"PORT": "3000", "HTTP_ADDR": "127.0.0.1:4000"}))
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.HTTP.Addr != "127.0.0.1:4000" {
+		t.Errorf("Addr = %q, HTTP_ADDR must win over PORT", cfg.HTTP.Addr)
+	}
+	// флаги применяются в том же порядке, что и переменные, а не по алфавиту
+	cfg, _, err = Load([]string{"-addr", "127.0.0.1:4000", "-port", "3000"}, env(nil))
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.HTTP.Addr != "127.0.0.1:4000" {
+		t.Errorf("Addr = %q, -addr must win over -port", cfg.HTTP.Addr)
+	}
+}
+
+func TestLoad_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		env      map[string]string
+		file     string
+		wantErrs []string
+	}{
+		{
+			name:     "unknown file field",
+			file:     `{"http": {"port": 8080}}`,
+			wantErrs: []string{`unknown field "port"`},
+		},
+		{
+			name:     "bad file duration",
+			file:     `{"http": {"read_timeout": 5}}`,
+			wantErrs: []string{"duration must be a string"},
+		},
+		{
+			name:     "bad env duration",
+			env:      map[string]string{"SHUTDOWN_TIMEOUT": "soon"},
+			wantErrs: []string{`env SHUTDOWN_TIMEOUT: invalid duration "soon"`},
+		},
+		{
+			name:     "unknown flag",
+			args:     []string{"-listen", ":80"},
+			wantErrs: []string{"flag provided but not defined: -listen"},
+		},
+		{
+			name: "invalid rate limits",
+			file: `{"rate_limit": {
+				"default": {"rate": 5},
+				"routes": {"/todos": {"rate": -1}},
+				"trusted_proxies": ["10.0.0.0/33"]
+			}}`,
+			wantErrs: []string{
+				"rate_limit.default.burst: must be at least 1 when rate is set",
+				`route "/todos" must look like "POST /todos"`,
+				`rate_limit.routes["/todos"].rate: must not be negative`,
+				`invalid address or CIDR "10.0.0.0/33"`,
+			},
+		},
+		{
+			name: "invalid cors",
+			file: `{"cors": {
+				"allowed_origins": ["*", "https://app.example.com/path"],
+				"allowed_methods": ["get"],
+				"allow_credentials": true
+			}}`,
+			wantErrs: []string{
+				`cors.allowed_origins: "*" cannot be combined with allow_credentials`,
+				`origin "https://app.example.com/path" must look like`,
+				`cors.allowed_methods: invalid method "get"`,
+			},
+		},
+		{
+			name:     "incomplete tls",
+			env:      map[string]string{"TLS_KEY_FILE": "server.key", "TLS_CLIENT_CA_FILE": "ca.pem"},
+			wantErrs: []string{"tls: cert_file and key_file must be set together", "tls.client_ca_file: requires cert_file and key_file"},
+		},
+		{
+			name:     "admin without mtls",
+			file:     `{"admin": {"identities": ["CN=admin,O=todos", " "]}}`,
+			wantErrs: []string{"admin.identities: must not contain empty entries", "admin.identities: requires tls.client_ca_file"},
+		},
+		{
+			name: "request timeout beyond write timeout",
+			file: `{"request_timeout": {"default": "5s", "routes": {"GET /todos": "-1s"}}}`,
+			wantErrs: []string{
+				"request_timeout.default: must be less than http.write_timeout (5s)",
+				`request_timeout.routes["GET /todos"]: must not be negative`,
+			},
+		},
+		{
+			name: "invalid compression and cache control",
+			file: `{"compression": {"min_size": -1}, "cache_control": {"POST /todos": "no-store", "GET /todos": " "}}`,
+			wantErrs: []string{
+				"compression.min_size: must not be negative",
+				`cache_control: route "POST /todos" must look like "GET /todos"`,
+				`cache_control["GET /todos"]: must not be empty`,
+			},
+		},
+		{
+			name: "all validation errors at once",
+			env: map[string]string{
+				"PORT":             "70000",
+				"STORAGE_BACKEND":  "postgres",
+				"LOG_LEVEL":        "loud",
+				"TRACE_EXPORTER":   "otlp",
+				"OTLP_ENDPOINT":    "collector",
+				"SHUTDOWN_TIMEOUT": "0s",
+			},
+			wantErrs: []string{
+				`http.addr: invalid port "70000"`,
+				"http.shutdown_timeout: must be positive",
+				`storage.backend: unknown backend "postgres"`,
+				"log.level:",
+				`tracing.otlp_endpoint: must be an absolute URL, got "collector"`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values := tt.env
+			if tt.file != "" {
+				values = map[string]string{"CONFIG_PATH": writeFile(t, tt.file)}
+			}
+
+			_, _, err := Load(tt.args, env(values))
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			for _, want := range tt.wantErrs {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("error %q does not contain %q", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestLoad_Help(t *testing.T) {
+	_, _, err := Load([]string{"-h"}, env(nil))
+	if !errors.Is(err, flag.ErrHelp) {
+		t.Errorf("error = %v, want flag.ErrHelp", err)
+	}
+}
+
+// TestEnvExample ловит склеенные строки вроде LOG_FORMAT=jsonTRACE_EXPORTER=none,
+// которые получаются, если в файле нет перевода строки в конце.
+func TestEnvExample(t *testing.T) {
+	data, err := os.ReadFile("../../env.example")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(string(data), "\n") {
+		t.Error("env.example must end with a newline")
+	}
+
+	known := map[string]bool{"CONFIG_PATH": true}
+	for _, s := range settings {
+		known[s.env] = true
+	}
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		key, value, _ := strings.Cut(line, "=")
+		if !known[key] || strings.Contains(value, "=") {
+			t.Errorf("unexpected line %q", line)
+		}
+	}
+}
+
+func TestLoadFile_ExampleConfig(t *testing.T) {
+	cfg, err := LoadFile("../../configs/config.json")
+	if err != nil {
+		t.Fatalf("LoadFile failed: %v", err)
+	}
+	if cfg.Validation.RulesPath != "configs/validation.json" {
+		t.Errorf("RulesPath = %q", cfg.Validation.RulesPath)
+	}
+}
@@ -0,0 +1,144 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"os"
+	"reflect"
+	"sync"
+
+	"github.com/Ant-Tab-Shift/todos-service/internal/logging"
+)
+
+// Applier готовит применение новой конфигурации: проверяет её и возвращает
+// commit, который только переключает уже подготовленное состояние и не может
+// завершиться ошибкой. Так перезагрузка применяется целиком или не применяется вовсе.
+type Applier func(next Config) (commit func(), err error)
+
+type Reloader struct {
+	mu       sync.Mutex
+	current  Config
+	load     func() (Config, error)
+	appliers []namedApplier
+}
+
+type namedApplier struct {
+	name  string
+	apply Applier
+}
+
+func NewReloader(current Config, load func() (Config, error)) *Reloader {
+	return &Reloader{current: current, load: load}
+}
+
+func (r *Reloader) Register(name string, apply Applier) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.appliers = append(r.appliers, namedApplier{name: name, apply: apply})
+}
+
+func (r *Reloader) Current() Config {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.current
+}
+
+// Reload перечитывает конфигурацию. Параметры, которые нельзя сменить без
+// перезапуска, остаются прежними, а их изменение возвращается в списке ignored.
+func (r *Reloader) Reload() (ignored []string, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	next, err := r.load()
+	if err != nil {
+		return nil, err
+	}
+	next, ignored = next.keepStatic(r.current)
+
+	commits := make([]func(), 0, len(r.appliers))
+	var errs []error
+	for _, a := range r.appliers {
+		commit, err := a.apply(next)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", a.name, err))
+			continue
+		}
+		commits = append(commits, commit)
+	}
+	if len(errs) > 0 {
+		return ignored, errors.Join(errs...)
+	}
+
+	for _, commit := range commits {
+		commit()
+	}
+	r.current = next
+
+	return ignored, nil
+}
+
+// Run перезагружает конфигурацию на каждый сигнал до отмены контекста.
+func (r *Reloader) Run(ctx context.Context, signals <-chan os.Signal) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-signals:
+		}
+
+		ignored, err := r.Reload()
+		if len(ignored) > 0 {
+			slog.Warn("configuration changes require restart and were ignored", "fields", ignored)
+		}
+		if err != nil {
+			slog.Error("configuration reload rejected, keeping previous configuration", logging.Err(err))
+			continue
+		}
+		slog.Info("configuration reloaded", "log_level", r.Current().Log.Level)
+	}
+}
+
+// keepStatic возвращает копию c с параметрами, требующими перезапуска, взятыми из current.
+func (c Config) keepStatic(current Config) (Config, []string) {
+	var ignored []string
+	if c.HTTP != current.HTTP {
+		ignored = append(ignored, "http")
+	}
+	if c.Storage != current.Storage {
+		ignored = append(ignored, "storage")
+	}
+	if c.Log.Format != current.Log.Format {
+		ignored = append(ignored, "log.format")
+	}
+	if c.Tracing != current.Tracing {
+		ignored = append(ignored, "tracing")
+	}
+	if c.TLS != current.TLS {
+		ignored = append(ignored, "tls")
+	}
+	if !reflect.DeepEqual(c.RequestTimeout, current.RequestTimeout) {
+		ignored = append(ignored, "request_timeout")
+	}
+	if c.Compression != current.Compression {
+		ignored = append(ignored, "compression")
+	}
+	if !maps.Equal(c.CacheControl, current.CacheControl) {
+		ignored = append(ignored, "cache_control")
+	}
+
+	c.HTTP = current.HTTP
+	c.Storage = current.Storage
+	c.Log.Format = current.Log.Format
+	c.Tracing = current.Tracing
+	c.TLS = current.TLS
+	c.RequestTimeout = current.RequestTimeout
+	c.Compression = current.Compression
+	c.CacheControl = current.CacheControl
+
+	return c, ignored
+}
@@ -0,0 +1,87 @@
+package config
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestReloader_AppliesAllOrNothing(t *testing.T) {
+	current := Default()
+	next := Default()
+	next.Log.Level = "debug"
+
+	var loadErr error
+	reloader := NewReloader(current, func() (Config, error) { return next, loadErr })
+
+	var applied []string
+	failValidation := false
+	reloader.Register("log", func(cfg Config) (func(), error) {
+		return func() { applied = append(applied, "log="+cfg.Log.Level) }, nil
+	})
+	reloader.Register("validation", func(cfg Config) (func(), error) {
+		if failValidation {
+			return nil, errors.New("bad rules")
+		}
+		return func() { applied = append(applied, "validation") }, nil
+	})
+
+	failValidation = true
+	if _, err := reloader.Reload(); err == nil {
+		t.Fatal("expected error from failing applier")
+	}
+	if len(applied) != 0 {
+		t.Errorf("applied = %v, want nothing committed after rejected reload", applied)
+	}
+	if reloader.Current().Log.Level != "info" {
+		t.Errorf("current level = %q, want previous", reloader.Current().Log.Level)
+	}
+
+	loadErr = errors.New("invalid config")
+	failValidation = false
+	if _, err := reloader.Reload(); !errors.Is(err, loadErr) {
+		t.Errorf("error = %v, want load error", err)
+	}
+
+	loadErr = nil
+	if _, err := reloader.Reload(); err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	if want := []string{"log=debug", "validation"}; !slices.Equal(applied, want) {
+		t.Errorf("applied = %v, want %v", applied, want)
+	}
+	if reloader.Current().Log.Level != "debug" {
+		t.Errorf("current level = %q, want debug", reloader.Current().Log.Level)
+	}
+}
+
+func TestReloader_KeepsStaticSettings(t *testing.T) {
+	current := Default()
+	next := Default()
+	next.HTTP.Addr = ":9999"
+	next.Log.Format = "text"
+	next.Validation.RulesPath = "rules.json"
+
+	reloader := NewReloader(current, func() (Config, error) { return next, nil })
+
+	var seen Config
+	reloader.Register("capture", func(cfg Config) (func(), error) {
+		seen = cfg
+		return func() {}, nil
+	})
+
+	ignored, err := reloader.Reload()
+	if err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+
+	if want := []string{"http", "log.format"}; !slices.Equal(ignored, want) {
+		t.Errorf("ignored = %v, want %v", ignored, want)
+	}
+	if seen.HTTP.Addr != ":8080" || seen.Log.Format != "json" {
+		t.Errorf("static settings changed: addr %q, format %q", seen.HTTP.Addr, seen.Log.Format)
+	}
+	if seen.Validation.RulesPath != "rules.json" {
+		t.Errorf("RulesPath = %q, want reloaded value", seen.Validation.RulesPath)
+	}
+}
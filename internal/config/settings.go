@@ -0,0 +1,99 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// setting связывает параметр с переменной окружения и флагом, чтобы оба
+// слоя разбирались одинаково.
+type setting struct {
+	env   string
+	flag  string
+	usage string
+	apply func(c *Config, value string) error
+}
+
+// PORT стоит раньше HTTP_ADDR, чтобы более точный параметр имел приоритет.
+var settings = []setting{
+	{"PORT", "port", "listen port, shorthand for -addr :PORT", func(c *Config, value string) error {
+		c.HTTP.Addr = ":" + value
+		return nil
+	}},
+	{"HTTP_ADDR", "addr", "listen address", setString(func(c *Config) *string { return &c.HTTP.Addr })},
+	{"HTTP_READ_TIMEOUT", "read-timeout", "HTTP read timeout", setDuration(func(c *Config) *Duration { return &c.HTTP.ReadTimeout })},
+	{"HTTP_WRITE_TIMEOUT", "write-timeout", "HTTP write timeout", setDuration(func(c *Config) *Duration { return &c.HTTP.WriteTimeout })},
+	{"HTTP_IDLE_TIMEOUT", "idle-timeout", "HTTP keep-alive idle timeout", setDuration(func(c *Config) *Duration { return &c.HTTP.IdleTimeout })},
+	{"REQUEST_TIMEOUT", "request-timeout", "default per-request processing deadline, 0 disables it", setDuration(func(c *Config) *Duration { return &c.RequestTimeout.Default })},
+	{"SHUTDOWN_TIMEOUT", "shutdown-timeout", "graceful shutdown timeout", setDuration(func(c *Config) *Duration { return &c.HTTP.ShutdownTimeout })},
+	{"DRAIN_DELAY", "drain-delay", "delay between failing readiness and stopping the server", setDuration(func(c *Config) *Duration { return &c.HTTP.DrainDelay })},
+	{"COMPRESSION_MIN_SIZE", "compression-min-size", "minimum response size in bytes to compress, 0 disables compression", func(c *Config, value string) error {
+		size, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", value)
+		}
+		c.Compression.MinSize = size
+		return nil
+	}},
+	{"TLS_CERT_FILE", "tls-cert", "TLS certificate file, enables HTTPS", setString(func(c *Config) *string { return &c.TLS.CertFile })},
+	{"TLS_KEY_FILE", "tls-key", "TLS private key file", setString(func(c *Config) *string { return &c.TLS.KeyFile })},
+	{"TLS_CLIENT_CA_FILE", "tls-client-ca", "CA bundle for client certificates, enables mTLS", setString(func(c *Config) *string { return &c.TLS.ClientCAFile })},
+	{"STORAGE_BACKEND", "storage", "storage backend", setString(func(c *Config) *string { return &c.Storage.Backend })},
+	{"OUTBOX_PATH", "outbox-path", "path to the event outbox file", setString(func(c *Config) *string { return &c.Storage.OutboxPath })},
+	{"LOG_LEVEL", "log-level", "log level: debug, info, warn or error", setString(func(c *Config) *string { return &c.Log.Level })},
+	{"LOG_FORMAT", "log-format", "log format: json or text", setString(func(c *Config) *string { return &c.Log.Format })},
+	{"TRACE_EXPORTER", "trace-exporter", "span exporter: none, stdout or otlp", setString(func(c *Config) *string { return &c.Tracing.Exporter })},
+	{"OTLP_ENDPOINT", "otlp-endpoint", "OTLP/HTTP traces endpoint", setString(func(c *Config) *string { return &c.Tracing.OTLPEndpoint })},
+	{"VALIDATION_RULES_PATH", "validation-rules", "path to task validation rules", setString(func(c *Config) *string { return &c.Validation.RulesPath })},
+	{"RATE_LIMIT_RATE", "rate-limit", "default requests per second per client, 0 disables limiting", func(c *Config, value string) error {
+		rate, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("invalid number %q", value)
+		}
+		c.RateLimit.Default.Rate = rate
+		return nil
+	}},
+	{"RATE_LIMIT_BURST", "rate-limit-burst", "default burst size per client", func(c *Config, value string) error {
+		burst, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", value)
+		}
+		c.RateLimit.Default.Burst = burst
+		return nil
+	}},
+	{"CORS_ALLOWED_ORIGINS", "cors-origins", "comma-separated origins allowed for browser requests", setList(func(c *Config) *[]string { return &c.CORS.AllowedOrigins })},
+	{"TRUSTED_PROXIES", "trusted-proxies", "comma-separated proxy addresses or CIDRs trusted for X-Forwarded-For", setList(func(c *Config) *[]string { return &c.RateLimit.TrustedProxies })},
+}
+
+func setString(field func(c *Config) *string) func(c *Config, value string) error {
+	return func(c *Config, value string) error {
+		*field(c) = value
+		return nil
+	}
+}
+
+func setDuration(field func(c *Config) *Duration) func(c *Config, value string) error {
+	return func(c *Config, value string) error {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q", value)
+		}
+		*field(c) = Duration(d)
+		return nil
+	}
+}
+
+func setList(field func(c *Config) *[]string) func(c *Config, value string) error {
+	return func(c *Config, value string) error {
+		var items []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		*field(c) = items
+		return nil
+	}
+}
@@ -6,5 +6,16 @@ import (
 
 var (
 	ErrNotExists = errors.New("resource not found in storage")
+	ErrAlreadyExists = errors.New("resource already exists in storage")
+	ErrInvalidSnapshot = errors.New("invalid storage snapshot")
 	ErrEmptyTitle = errors.New("task must have non empty title")
+
+	ErrInvalidPriority     = errors.New("task priority must be one of low, normal, high")
+	ErrTitleTooLong        = errors.New("task title is too long")
+	ErrDescriptionTooLong  = errors.New("task description is too long")
+	ErrForbiddenCharacters = errors.New("task contains forbidden characters")
+	ErrDescriptionRequired = errors.New("high priority task must have a description")
+	ErrDuplicateTitle      = errors.New("an open task with the same title already exists")
+	ErrBlankTag            = errors.New("task tags must not be blank")
+	ErrInvalidParent       = errors.New("task parent must be an existing task outside its subtasks")
 )
@@ -0,0 +1,18 @@
+package domain
+
+import "time"
+
+type EventKind string
+
+const (
+	TaskCreated EventKind = "task.created"
+	TaskUpdated EventKind = "task.updated"
+	TaskDeleted EventKind = "task.deleted"
+)
+
+type Event struct {
+	Kind       EventKind
+	TaskID     uint64
+	Task       TaskSchema
+	OccurredAt time.Time
+}
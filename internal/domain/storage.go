@@ -1,6 +1,24 @@
 package domain
 
+import "time"
+
 type Elem[V any] struct {
 	ID    uint64
 	Value V
 }
+
+// Revision — версия записи или всей коллекции: Version меняется при каждом изменении.
+type Revision struct {
+	Version    uint64
+	ModifiedAt time.Time
+}
+
+// Snapshot — согласованное состояние хранилища: все записи и ID, который получит следующая новая запись.
+type Snapshot[V any] struct {
+	SerialID uint64
+	Elems    []Elem[V]
+}
+
+// Recorder фиксирует события изменения записи id. Хранилище вызывает его под своей
+// блокировкой до изменения и не применяет изменение, если Recorder вернул ошибку.
+type Recorder func(id uint64) error
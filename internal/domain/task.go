@@ -1,11 +1,46 @@
 package domain
 
+import "time"
+
+type Priority string
+
+const (
+	PriorityLow    Priority = "low"
+	PriorityNormal Priority = "normal"
+	PriorityHigh   Priority = "high"
+)
+
+func (p Priority) Valid() bool {
+	switch p {
+	case PriorityLow, PriorityNormal, PriorityHigh:
+		return true
+	default:
+		return false
+	}
+}
+
 type TaskSchema struct {
 	Title       string
 	Description string
 	IsDone      bool
+	Priority    Priority
+	// DueDate — срок выполнения; нулевое значение означает, что срока нет.
+	DueDate time.Time
+	// Tags — метки задачи в заданном порядке.
+	Tags []string
+	// ParentID — задача, в которую вложена эта; 0 у задач верхнего уровня.
+	ParentID uint64
 }
 
+// TaskField — поле задачи, которое обновление может оставить прежним.
+type TaskField int
+
+const (
+	FieldDueDate TaskField = iota + 1
+	FieldTags
+	FieldParentID
+)
+
 type Task struct {
 	ID    uint64
 	TaskSchema
@@ -0,0 +1,72 @@
+package health
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/Ant-Tab-Shift/todos-service/internal/logging"
+	"github.com/Ant-Tab-Shift/todos-service/internal/transport/http/models"
+)
+
+type Handler struct {
+	checker *Checker
+}
+
+func NewHandler(checker *Checker) *Handler {
+	return &Handler{checker: checker}
+}
+
+func (h *Handler) Live(w http.ResponseWriter, r *http.Request) {
+	writeReport(w, r, Report{Status: StatusOK}, http.StatusOK)
+}
+
+func (h *Handler) Ready(w http.ResponseWriter, r *http.Request) {
+	report := h.checker.Ready(r.Context())
+
+	status := http.StatusOK
+	if report.Status != StatusOK {
+		status = http.StatusServiceUnavailable
+	}
+	writeReport(w, r, report, status)
+}
+
+func writeReport(w http.ResponseWriter, r *http.Request, report Report, status int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(report); err != nil {
+		slog.WarnContext(r.Context(), "failed to write health report", logging.Err(err))
+	}
+}
+
+func (h *Handler) Handlers() []models.Endpoint {
+	return []models.Endpoint{
+		{
+			Pattern: "GET /healthz",
+			Func:    h.Live,
+			Doc: &models.Doc{
+				Summary:     "Liveness probe",
+				Description: "Responds while the process is able to serve HTTP.",
+				Tags:        []string{"meta"},
+				Responses: map[int]models.Response{
+					http.StatusOK: {Description: "Process is alive", Body: Report{}},
+				},
+			},
+		},
+		{
+			Pattern: "GET /readyz",
+			Func:    h.Ready,
+			Doc: &models.Doc{
+				Summary:     "Readiness probe",
+				Description: "Reports every dependency check; fails once graceful shutdown has started.",
+				Tags:        []string{"meta"},
+				Responses: map[int]models.Response{
+					http.StatusOK:                 {Description: "Ready to receive traffic", Body: Report{}},
+					http.StatusServiceUnavailable: {Description: "At least one check failed", Body: Report{}},
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,114 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestChecker_ReportsEveryCheck(t *testing.T) {
+	checker := NewChecker(time.Second)
+	checker.Register("storage", func(ctx context.Context) error { return nil })
+	checker.Register("event_bus", func(ctx context.Context) error { return errors.New("queue is full") })
+
+	report := checker.Ready(context.Background())
+
+	if report.Status != StatusFail {
+		t.Errorf("Status = %q, want %q", report.Status, StatusFail)
+	}
+	want := map[string]CheckResult{
+		"shutdown":  {Status: StatusOK},
+		"storage":   {Status: StatusOK},
+		"event_bus": {Status: StatusFail, Error: "queue is full"},
+	}
+	for name, result := range want {
+		if got := report.Checks[name]; got != result {
+			t.Errorf("check %s = %+v, want %+v", name, got, result)
+		}
+	}
+}
+
+func TestChecker_DrainFailsReadiness(t *testing.T) {
+	checker := NewChecker(time.Second)
+	if report := checker.Ready(context.Background()); report.Status != StatusOK {
+		t.Fatalf("Status = %q before drain, want ok", report.Status)
+	}
+
+	checker.Drain()
+
+	report := checker.Ready(context.Background())
+	if report.Status != StatusFail || report.Checks["shutdown"].Error != ErrShuttingDown.Error() {
+		t.Errorf("report after drain = %+v", report)
+	}
+}
+
+func TestChecker_HungCheckTimesOut(t *testing.T) {
+	checker := NewChecker(20 * time.Millisecond)
+	release := make(chan struct{})
+	defer close(release)
+	checker.Register("storage", func(ctx context.Context) error {
+		<-release
+		return nil
+	})
+
+	start := time.Now()
+	report := checker.Ready(context.Background())
+
+	if time.Since(start) > time.Second {
+		t.Fatal("hung check delayed the report")
+	}
+	if got := report.Checks["storage"]; got.Status != StatusFail || got.Error != context.DeadlineExceeded.Error() {
+		t.Errorf("storage = %+v, want deadline exceeded", got)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		path       string
+		drain      bool
+		wantStatus int
+		wantReport string
+	}{
+		{"live", "/healthz", false, http.StatusOK, StatusOK},
+		{"live while draining", "/healthz", true, http.StatusOK, StatusOK},
+		{"ready", "/readyz", false, http.StatusOK, StatusOK},
+		{"not ready while draining", "/readyz", true, http.StatusServiceUnavailable, StatusFail},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checker := NewChecker(time.Second)
+			if tt.drain {
+				checker.Drain()
+			}
+
+			mux := http.NewServeMux()
+			for _, endpoint := range NewHandler(checker).Handlers() {
+				mux.HandleFunc(endpoint.Pattern, endpoint.Func)
+			}
+
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if got := rec.Header().Get("Cache-Control"); got != "no-store" {
+				t.Errorf("Cache-Control = %q, want no-store", got)
+			}
+
+			var report Report
+			if err := json.NewDecoder(rec.Body).Decode(&report); err != nil {
+				t.Fatalf("decode failed: %v", err)
+			}
+			if report.Status != tt.wantReport {
+				t.Errorf("report status = %q, want %q", report.Status, tt.wantReport)
+			}
+		})
+	}
+}
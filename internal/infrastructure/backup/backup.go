@@ -0,0 +1,244 @@
+// Package backup пишет и читает архив хранилища задач: JSON со снимком и его
+// контрольной суммой, сжатый gzip.
+package backup
+
+import (
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/Ant-Tab-Shift/todos-service/internal/domain"
+	"github.com/Ant-Tab-Shift/todos-service/internal/usecases/rules"
+)
+
+const (
+	Format    = "todos-service/backup"
+	Version   = 1
+	MediaType = "application/gzip"
+
+	// MaxSize ограничивает распакованный архив, чтобы маленький файл не раздулся в памяти.
+	MaxSize = 256 << 20
+
+	checksumPrefix = "sha256:"
+)
+
+var (
+	ErrInvalid  = errors.New("invalid backup archive")
+	ErrTooLarge = errors.New("backup archive too large")
+)
+
+// Archive — прочитанный архив: снимок хранилища и время его создания.
+type Archive struct {
+	CreatedAt time.Time
+	Snapshot  domain.Snapshot[domain.TaskSchema]
+}
+
+// envelope — содержимое архива; Checksum считается по байтам Payload в том виде,
+// в каком они записаны в файле.
+type envelope struct {
+	Format    string          `json:"format"`
+	Version   int             `json:"version"`
+	CreatedAt time.Time       `json:"created_at"`
+	Checksum  string          `json:"checksum"`
+	Payload   json.RawMessage `json:"payload"`
+}
+
+type payload struct {
+	SerialID uint64 `json:"serial_id"`
+	Tasks    []task `json:"tasks"`
+}
+
+type task struct {
+	ID          uint64     `json:"id"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	IsDone      bool       `json:"is_done"`
+	Priority    string     `json:"priority"`
+	DueDate     *time.Time `json:"due_date,omitempty"`
+	Tags        []string   `json:"tags,omitempty"`
+	ParentID    uint64     `json:"parent_id,omitempty"`
+}
+
+// Write записывает снимок в архив.
+func Write(w io.Writer, snapshot domain.Snapshot[domain.TaskSchema], createdAt time.Time) error {
+	p := payload{SerialID: snapshot.SerialID, Tasks: make([]task, 0, len(snapshot.Elems))}
+	for _, elem := range snapshot.Elems {
+		p.Tasks = append(p.Tasks, fromSchema(elem.ID, elem.Value))
+	}
+	// в хранилище подзадачи могут пережить родителя, а в архиве ссылки должны разрешаться
+	checkParents(p.Tasks, true)
+	raw, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(envelope{
+		Format:    Format,
+		Version:   Version,
+		CreatedAt: createdAt.UTC(),
+		Checksum:  checksum(raw),
+		Payload:   raw,
+	})
+	if err != nil {
+		return err
+	}
+
+	zw := gzip.NewWriter(w)
+	if _, err = zw.Write(data); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// Read читает и проверяет архив: формат, версию, контрольную сумму, встроенные правила
+// задач и ссылки на родителей, которые должны указывать на задачи архива без циклов.
+// Согласованность ID со счётчиком проверяет хранилище при восстановлении.
+// Ошибки содержимого оборачивают ErrInvalid, ошибки чтения r возвращаются в цепочке.
+func Read(ctx context.Context, r io.Reader) (Archive, error) {
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return Archive{}, fmt.Errorf("%w: %w", ErrInvalid, err)
+	}
+	defer zr.Close()
+
+	data, err := io.ReadAll(io.LimitReader(zr, MaxSize+1))
+	if err != nil {
+		return Archive{}, fmt.Errorf("%w: %w", ErrInvalid, err)
+	}
+	if len(data) > MaxSize {
+		return Archive{}, ErrTooLarge
+	}
+
+	var env envelope
+	if err = json.Unmarshal(data, &env); err != nil {
+		return Archive{}, fmt.Errorf("%w: %w", ErrInvalid, err)
+	}
+	if env.Format != Format {
+		return Archive{}, fmt.Errorf("%w: format %q, want %q", ErrInvalid, env.Format, Format)
+	}
+	if env.Version != Version {
+		return Archive{}, fmt.Errorf("%w: unsupported version %d", ErrInvalid, env.Version)
+	}
+	if env.Checksum != checksum(env.Payload) {
+		return Archive{}, fmt.Errorf("%w: checksum mismatch", ErrInvalid)
+	}
+
+	var p payload
+	if err = json.Unmarshal(env.Payload, &p); err != nil {
+		return Archive{}, fmt.Errorf("%w: payload: %w", ErrInvalid, err)
+	}
+
+	archive := Archive{
+		CreatedAt: env.CreatedAt,
+		Snapshot: domain.Snapshot[domain.TaskSchema]{
+			SerialID: p.SerialID,
+			Elems:    make([]domain.Elem[domain.TaskSchema], 0, len(p.Tasks)),
+		},
+	}
+	engine := rules.Default()
+	for _, t := range p.Tasks {
+		schema := t.schema()
+		// %v, а не %w: ошибки полей относятся к задаче архива, а не к телу запроса
+		if err = engine.Validate(ctx, rules.Candidate{ID: t.ID, Task: schema}); err != nil {
+			return Archive{}, fmt.Errorf("%w: task %d: %v", ErrInvalid, t.ID, err)
+		}
+		archive.Snapshot.Elems = append(archive.Snapshot.Elems, domain.Elem[domain.TaskSchema]{ID: t.ID, Value: schema})
+	}
+	if err = checkParents(p.Tasks, false); err != nil {
+		return Archive{}, fmt.Errorf("%w: %w", ErrInvalid, err)
+	}
+
+	return archive, nil
+}
+
+// checkParents проверяет, что цепочка родителей каждой задачи заканчивается задачей
+// верхнего уровня. С detach ссылки на отсутствующие задачи и замыкающие цикл
+// обнуляются, без него — возвращается ошибка.
+func checkParents(tasks []task, detach bool) error {
+	index := make(map[uint64]int, len(tasks))
+	for i, t := range tasks {
+		index[t.ID] = i
+	}
+
+	const (
+		visiting = 1
+		resolved = 2
+	)
+	state := make(map[uint64]int, len(tasks))
+	for i := range tasks {
+		var path []uint64
+		for j := i; ; {
+			t := &tasks[j]
+			if state[t.ID] == resolved || t.ParentID == 0 {
+				break
+			}
+			if state[t.ID] == visiting {
+				if !detach {
+					return fmt.Errorf("task %d: parent_id %d forms a cycle", t.ID, t.ParentID)
+				}
+				t.ParentID = 0
+				break
+			}
+			state[t.ID] = visiting
+			path = append(path, t.ID)
+
+			parent, ok := index[t.ParentID]
+			if !ok {
+				if !detach {
+					return fmt.Errorf("task %d: parent_id %d is not in the archive", t.ID, t.ParentID)
+				}
+				t.ParentID = 0
+				break
+			}
+			j = parent
+		}
+		for _, id := range path {
+			state[id] = resolved
+		}
+	}
+
+	return nil
+}
+
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return checksumPrefix + hex.EncodeToString(sum[:])
+}
+
+func fromSchema(id uint64, s domain.TaskSchema) task {
+	t := task{
+		ID:          id,
+		Title:       s.Title,
+		Description: s.Description,
+		IsDone:      s.IsDone,
+		Priority:    string(s.Priority),
+		Tags:        s.Tags,
+		ParentID:    s.ParentID,
+	}
+	if !s.DueDate.IsZero() {
+		due := s.DueDate
+		t.DueDate = &due
+	}
+	return t
+}
+
+func (t task) schema() domain.TaskSchema {
+	s := domain.TaskSchema{
+		Title:       t.Title,
+		Description: t.Description,
+		IsDone:      t.IsDone,
+		Priority:    domain.Priority(t.Priority),
+		Tags:        t.Tags,
+		ParentID:    t.ParentID,
+	}
+	if t.DueDate != nil {
+		s.DueDate = *t.DueDate
+	}
+	return s
+}
@@ -0,0 +1,179 @@
+package backup
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Ant-Tab-Shift/todos-service/internal/domain"
+)
+
+func testSnapshot() domain.Snapshot[domain.TaskSchema] {
+	return domain.Snapshot[domain.TaskSchema]{
+		SerialID: 8,
+		Elems: []domain.Elem[domain.TaskSchema]{
+			{ID: 2, Value: domain.TaskSchema{Title: "Write report", Priority: domain.PriorityHigh, Tags: []string{"work"}}},
+			{ID: 5, Value: domain.TaskSchema{
+				Title:       "Proofread",
+				Description: "twice",
+				IsDone:      true,
+				Priority:    domain.PriorityLow,
+				DueDate:     time.Date(2026, 3, 1, 9, 30, 0, 0, time.UTC),
+				ParentID:    2,
+			}},
+		},
+	}
+}
+
+func TestWriteRead_RoundTrip(t *testing.T) {
+	createdAt := time.Date(2026, 2, 1, 12, 0, 0, 0, time.UTC)
+
+	var buf bytes.Buffer
+	if err := Write(&buf, testSnapshot(), createdAt); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	archive, err := Read(context.Background(), &buf)
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if !archive.CreatedAt.Equal(createdAt) {
+		t.Errorf("CreatedAt = %v, want %v", archive.CreatedAt, createdAt)
+	}
+	if !reflect.DeepEqual(archive.Snapshot, testSnapshot()) {
+		t.Errorf("snapshot = %+v, want %+v", archive.Snapshot, testSnapshot())
+	}
+}
+
+func TestRead_Invalid(t *testing.T) {
+	var valid bytes.Buffer
+	Write(&valid, testSnapshot(), time.Now())
+	contents := unzip(t, valid.Bytes())
+
+	tests := []struct {
+		name    string
+		archive []byte
+		wantErr error
+		want    string
+	}{
+		{"not gzip", []byte(contents), ErrInvalid, "gzip"},
+		{"truncated", valid.Bytes()[:valid.Len()/2], ErrInvalid, "unexpected EOF"},
+		{"not json", zip(t, "tasks"), ErrInvalid, "invalid character"},
+		{"other format", zip(t, strings.Replace(contents, Format, "other/backup", 1)), ErrInvalid, `format "other/backup"`},
+		{"newer version", zip(t, strings.Replace(contents, `"version":1`, `"version":2`, 1)), ErrInvalid, "unsupported version 2"},
+		{"tampered payload", zip(t, strings.Replace(contents, "Write report", "Write repost", 1)), ErrInvalid, "checksum mismatch"},
+		{"invalid priority", rewrite(t, contents, `"priority":"high"`, `"priority":"urgent"`), ErrInvalid, "task 2: priority: must be one of"},
+		{"blank title", rewrite(t, contents, `"title":"Write report"`, `"title":" "`), ErrInvalid, "task 2: title: must not be blank"},
+		{"blank tag", rewrite(t, contents, `"tags":["work"]`, `"tags":[""]`), ErrInvalid, "task 2: tags: must not contain blank tags"},
+		{"dangling parent", rewrite(t, contents, `"parent_id":2`, `"parent_id":3`), ErrInvalid, "task 5: parent_id 3 is not in the archive"},
+		{"parent cycle", rewrite(t, contents, `"tags":["work"]`, `"tags":["work"],"parent_id":5`), ErrInvalid, "forms a cycle"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Read(context.Background(), bytes.NewReader(tt.archive))
+			if !errors.Is(err, tt.wantErr) || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want %v containing %q", err, tt.wantErr, tt.want)
+			}
+		})
+	}
+}
+
+func TestWrite_DetachesUnresolvedParents(t *testing.T) {
+	snapshot := domain.Snapshot[domain.TaskSchema]{SerialID: 10, Elems: []domain.Elem[domain.TaskSchema]{
+		{ID: 1, Value: domain.TaskSchema{Title: "orphan", Priority: domain.PriorityNormal, ParentID: 9}},
+		{ID: 2, Value: domain.TaskSchema{Title: "cycle a", Priority: domain.PriorityNormal, ParentID: 3}},
+		{ID: 3, Value: domain.TaskSchema{Title: "cycle b", Priority: domain.PriorityNormal, ParentID: 2}},
+		{ID: 4, Value: domain.TaskSchema{Title: "child", Priority: domain.PriorityNormal, ParentID: 3}},
+	}}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, snapshot, time.Now()); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	archive, err := Read(context.Background(), &buf)
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+
+	var parents []uint64
+	for _, elem := range archive.Snapshot.Elems {
+		parents = append(parents, elem.Value.ParentID)
+	}
+	// цикл разрывается на задаче, с которой начался обход
+	if want := []uint64{0, 0, 2, 3}; !reflect.DeepEqual(parents, want) {
+		t.Errorf("parents = %v, want %v", parents, want)
+	}
+	if snapshot.Elems[0].Value.ParentID != 9 {
+		t.Error("Write must not modify the snapshot")
+	}
+}
+
+func TestRead_TooLarge(t *testing.T) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	io.CopyN(zw, zeros{}, MaxSize+1)
+	zw.Close()
+
+	if _, err := Read(context.Background(), &buf); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("error = %v, want ErrTooLarge", err)
+	}
+}
+
+type zeros struct{}
+
+func (zeros) Read(p []byte) (int, error) {
+	clear(p)
+	return len(p), nil
+}
+
+// rewrite меняет содержимое payload и пересчитывает контрольную сумму.
+func rewrite(t *testing.T, contents, old, new string) []byte {
+	t.Helper()
+
+	var env envelope
+	if err := json.Unmarshal([]byte(contents), &env); err != nil {
+		t.Fatal(err)
+	}
+	env.Payload = json.RawMessage(strings.Replace(string(env.Payload), old, new, 1))
+	env.Checksum = checksum(env.Payload)
+
+	data, err := json.Marshal(env)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return zip(t, string(data))
+}
+
+func zip(t *testing.T, contents string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	zw.Write([]byte(contents))
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func unzip(t *testing.T, data []byte) string {
+	t.Helper()
+
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	contents, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(contents)
+}
@@ -0,0 +1,212 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+
+	"github.com/Ant-Tab-Shift/todos-service/internal/domain"
+	"github.com/Ant-Tab-Shift/todos-service/internal/logging"
+)
+
+type Handler func(ctx context.Context, event domain.Event) error
+
+type Policy int
+
+const (
+	Block Policy = iota
+	DropNewest
+	DropOldest
+)
+
+var ErrClosed = errors.New("event bus is closed")
+
+type subscriber struct {
+	name    string
+	handler Handler
+	policy  Policy
+	queue   chan domain.Event
+	mu      sync.Mutex
+	dropped atomic.Uint64
+}
+
+type Bus struct {
+	mu        sync.RWMutex
+	sync      []Handler
+	async     []*subscriber
+	closed    bool
+	stopping  chan struct{}
+	closeOnce sync.Once
+	wg        sync.WaitGroup
+}
+
+func New() *Bus {
+	return &Bus{stopping: make(chan struct{})}
+}
+
+func (b *Bus) Subscribe(handler Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.sync = append(b.sync, handler)
+}
+
+func (b *Bus) SubscribeAsync(name string, handler Handler, queueSize int, policy Policy) error {
+	if queueSize <= 0 {
+		return fmt.Errorf("queue size must be positive, got %d", queueSize)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return ErrClosed
+	}
+
+	sub := &subscriber{
+		name:    name,
+		handler: handler,
+		policy:  policy,
+		queue:   make(chan domain.Event, queueSize),
+	}
+	b.async = append(b.async, sub)
+
+	b.wg.Add(1)
+	go b.run(sub)
+
+	return nil
+}
+
+func (b *Bus) Publish(ctx context.Context, event domain.Event) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if b.closed {
+		return ErrClosed
+	}
+
+	var errs []error
+	for _, handler := range b.sync {
+		if err := handler(ctx, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	for _, sub := range b.async {
+		if err := b.enqueue(ctx, sub, event); err != nil {
+			errs = append(errs, fmt.Errorf("subscriber %s: %w", sub.name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (b *Bus) Dropped() uint64 {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	var total uint64
+	for _, sub := range b.async {
+		total += sub.dropped.Load()
+	}
+
+	return total
+}
+
+// Check сообщает о неисправности, если шина закрыта или очередь
+// блокирующего подписчика заполнена и издатели ждут.
+func (b *Bus) Check(ctx context.Context) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if b.closed {
+		return ErrClosed
+	}
+
+	var errs []error
+	for _, sub := range b.async {
+		if sub.policy == Block && len(sub.queue) == cap(sub.queue) {
+			errs = append(errs, fmt.Errorf("subscriber %s: queue is full", sub.name))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (b *Bus) Close(ctx context.Context) error {
+	b.closeOnce.Do(func() {
+		// разблокируем издателей, ожидающих места в очереди
+		close(b.stopping)
+
+		b.mu.Lock()
+		b.closed = true
+		for _, sub := range b.async {
+			close(sub.queue)
+		}
+		b.mu.Unlock()
+	})
+
+	drained := make(chan struct{})
+	go func() {
+		b.wg.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("event bus drain interrupted: %w", ctx.Err())
+	}
+}
+
+func (b *Bus) enqueue(ctx context.Context, sub *subscriber, event domain.Event) error {
+	switch sub.policy {
+	case DropNewest:
+		select {
+		case sub.queue <- event:
+		default:
+			sub.dropped.Add(1)
+		}
+	case DropOldest:
+		sub.mu.Lock()
+		defer sub.mu.Unlock()
+
+		for {
+			select {
+			case sub.queue <- event:
+				return nil
+			default:
+			}
+
+			select {
+			case <-sub.queue:
+				sub.dropped.Add(1)
+			default:
+			}
+		}
+	default:
+		select {
+		case sub.queue <- event:
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-b.stopping:
+			return ErrClosed
+		}
+	}
+
+	return nil
+}
+
+func (b *Bus) run(sub *subscriber) {
+	defer b.wg.Done()
+
+	for event := range sub.queue {
+		if err := sub.handler(context.Background(), event); err != nil {
+			slog.Error("event subscriber failed", "subscriber", sub.name, "kind", event.Kind, "task_id", event.TaskID, logging.Err(err))
+		}
+	}
+}
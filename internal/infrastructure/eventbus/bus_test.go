@@ -0,0 +1,220 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Ant-Tab-Shift/todos-service/internal/domain"
+)
+
+func TestBus_SyncSubscriberReceivesEvent(t *testing.T) {
+	bus := New()
+	ctx := context.Background()
+
+	var received []domain.Event
+	bus.Subscribe(func(ctx context.Context, event domain.Event) error {
+		received = append(received, event)
+		return nil
+	})
+
+	event := domain.Event{Kind: domain.TaskCreated, TaskID: 1}
+	if err := bus.Publish(ctx, event); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+
+	if len(received) != 1 || received[0].TaskID != 1 {
+		t.Errorf("received = %+v, want single event for task 1", received)
+	}
+}
+
+func TestBus_SyncSubscriberErrorIsReturned(t *testing.T) {
+	bus := New()
+	expectedErr := errors.New("handler failed")
+	bus.Subscribe(func(ctx context.Context, event domain.Event) error {
+		return expectedErr
+	})
+
+	err := bus.Publish(context.Background(), domain.Event{Kind: domain.TaskDeleted})
+	if !errors.Is(err, expectedErr) {
+		t.Errorf("error = %v, want %v", err, expectedErr)
+	}
+}
+
+func TestBus_AsyncSubscriberDrainedOnClose(t *testing.T) {
+	bus := New()
+	ctx := context.Background()
+
+	var (
+		mu       sync.Mutex
+		received []uint64
+	)
+	err := bus.SubscribeAsync("test", func(ctx context.Context, event domain.Event) error {
+		time.Sleep(time.Millisecond)
+		mu.Lock()
+		received = append(received, event.TaskID)
+		mu.Unlock()
+		return nil
+	}, 16, Block)
+	if err != nil {
+		t.Fatalf("SubscribeAsync failed: %v", err)
+	}
+
+	for i := uint64(1); i <= 10; i++ {
+		if err = bus.Publish(ctx, domain.Event{Kind: domain.TaskCreated, TaskID: i}); err != nil {
+			t.Fatalf("Publish failed: %v", err)
+		}
+	}
+
+	if err = bus.Close(ctx); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(received) != 10 {
+		t.Fatalf("len(received) = %d, want 10", len(received))
+	}
+	for i, id := range received {
+		if id != uint64(i+1) {
+			t.Errorf("received[%d] = %d, want %d", i, id, i+1)
+		}
+	}
+}
+
+func TestBus_PublishAfterClose(t *testing.T) {
+	bus := New()
+	ctx := context.Background()
+
+	if err := bus.Close(ctx); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	if err := bus.Publish(ctx, domain.Event{}); !errors.Is(err, ErrClosed) {
+		t.Errorf("error = %v, want ErrClosed", err)
+	}
+	if err := bus.SubscribeAsync("late", func(context.Context, domain.Event) error { return nil }, 1, Block); !errors.Is(err, ErrClosed) {
+		t.Errorf("error = %v, want ErrClosed", err)
+	}
+}
+
+func TestBus_BackpressurePolicies(t *testing.T) {
+	tests := []struct {
+		name        string
+		policy      Policy
+		wantIDs     []uint64
+		wantDropped uint64
+		wantErr     error
+	}{
+		{name: "drop newest", policy: DropNewest, wantIDs: []uint64{1, 2}, wantDropped: 1},
+		{name: "drop oldest", policy: DropOldest, wantIDs: []uint64{2, 3}, wantDropped: 1},
+		{name: "block", policy: Block, wantIDs: []uint64{1, 2}, wantErr: context.DeadlineExceeded},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bus := New()
+			release := make(chan struct{})
+			started := make(chan struct{}, 1)
+
+			var received []uint64
+			err := bus.SubscribeAsync("slow", func(ctx context.Context, event domain.Event) error {
+				received = append(received, event.TaskID)
+				if event.TaskID == 0 {
+					started <- struct{}{}
+					<-release
+				}
+				return nil
+			}, 2, tt.policy)
+			if err != nil {
+				t.Fatalf("SubscribeAsync failed: %v", err)
+			}
+
+			// событие 0 занимает обработчик, очередь на два места заполняют 1 и 2
+			_ = bus.Publish(context.Background(), domain.Event{TaskID: 0})
+			<-started
+			_ = bus.Publish(context.Background(), domain.Event{TaskID: 1})
+			_ = bus.Publish(context.Background(), domain.Event{TaskID: 2})
+
+			ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+			defer cancel()
+			err = bus.Publish(ctx, domain.Event{TaskID: 3})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Publish error = %v, want %v", err, tt.wantErr)
+			}
+
+			close(release)
+			if err = bus.Close(context.Background()); err != nil {
+				t.Fatalf("Close failed: %v", err)
+			}
+
+			if got := received[1:]; !slices.Equal(got, tt.wantIDs) {
+				t.Errorf("received = %v, want %v", got, tt.wantIDs)
+			}
+			if dropped := bus.Dropped(); dropped != tt.wantDropped {
+				t.Errorf("Dropped() = %d, want %d", dropped, tt.wantDropped)
+			}
+		})
+	}
+}
+
+func TestBus_CloseRespectsContext(t *testing.T) {
+	bus := New()
+	release := make(chan struct{})
+	defer close(release)
+
+	err := bus.SubscribeAsync("stuck", func(ctx context.Context, event domain.Event) error {
+		<-release
+		return nil
+	}, 1, Block)
+	if err != nil {
+		t.Fatalf("SubscribeAsync failed: %v", err)
+	}
+	_ = bus.Publish(context.Background(), domain.Event{})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if err = bus.Close(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Close error = %v, want context.DeadlineExceeded", err)
+	}
+}
+
+func TestBus_Check(t *testing.T) {
+	bus := New()
+	release := make(chan struct{})
+	started := make(chan struct{}, 1)
+
+	err := bus.SubscribeAsync("slow", func(ctx context.Context, event domain.Event) error {
+		started <- struct{}{}
+		<-release
+		return nil
+	}, 1, Block)
+	if err != nil {
+		t.Fatalf("SubscribeAsync failed: %v", err)
+	}
+
+	if err := bus.Check(context.Background()); err != nil {
+		t.Errorf("Check on idle bus = %v, want nil", err)
+	}
+
+	// первое событие занимает обработчик, второе заполняет очередь
+	_ = bus.Publish(context.Background(), domain.Event{TaskID: 1})
+	<-started
+	_ = bus.Publish(context.Background(), domain.Event{TaskID: 2})
+
+	if err := bus.Check(context.Background()); err == nil {
+		t.Error("Check with full blocking queue = nil, want error")
+	}
+
+	close(release)
+	if err := bus.Close(context.Background()); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if err := bus.Check(context.Background()); !errors.Is(err, ErrClosed) {
+		t.Errorf("Check after close = %v, want ErrClosed", err)
+	}
+}
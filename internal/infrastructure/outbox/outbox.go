@@ -0,0 +1,376 @@
+package outbox
+
+import (
+	"bufio"
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+
+	"github.com/Ant-Tab-Shift/todos-service/internal/domain"
+)
+
+const compactThreshold = 1024
+
+const (
+	opAppend = "append"
+	opAck    = "ack"
+)
+
+type Record struct {
+	Seq   uint64
+	Event domain.Event
+}
+
+type entry struct {
+	Op    string        `json:"op"`
+	Seq   uint64        `json:"seq"`
+	Event *domain.Event `json:"event,omitempty"`
+}
+
+// journal — файл журнала; интерфейс позволяет проверить обработку сбоев записи.
+type journal interface {
+	io.Writer
+	Sync() error
+	Truncate(size int64) error
+	Close() error
+}
+
+type Outbox struct {
+	mu   sync.Mutex
+	path string
+	file journal
+	// size — длина журнала после последней успешной записи
+	size int64
+	// failed — ошибка отката: пока журнал не переписан, дописывать его нельзя
+	failed  error
+	seq     uint64
+	pending map[uint64]domain.Event
+	acked   int
+	notify  chan struct{}
+}
+
+func Open(path string) (*Outbox, error) {
+	o := &Outbox{
+		path:    path,
+		pending: make(map[uint64]domain.Event),
+		notify:  make(chan struct{}, 1),
+	}
+	if path == "" {
+		return o, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create outbox directory: %w", err)
+	}
+	if err := o.load(); err != nil {
+		return nil, err
+	}
+	if err := o.compact(); err != nil {
+		return nil, err
+	}
+	if len(o.pending) > 0 {
+		o.signal()
+	}
+
+	return o, nil
+}
+
+func (o *Outbox) Append(events ...domain.Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	seq := o.seq
+	lines := make([]byte, 0, 256*len(events))
+	for i := range events {
+		seq++
+		line, err := json.Marshal(entry{Op: opAppend, Seq: seq, Event: &events[i]})
+		if err != nil {
+			return fmt.Errorf("failed to encode outbox entry: %w", err)
+		}
+		lines = append(append(lines, line...), '\n')
+	}
+
+	if err := o.write(lines); err != nil {
+		return err
+	}
+
+	for _, event := range events {
+		o.seq++
+		o.pending[o.seq] = event
+	}
+	o.signal()
+
+	return nil
+}
+
+// Publish записывает событие в журнал; доставит его Relay.
+func (o *Outbox) Publish(_ context.Context, event domain.Event) error {
+	return o.Append(event)
+}
+
+func (o *Outbox) Pending() []Record {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	records := make([]Record, 0, len(o.pending))
+	for seq, event := range o.pending {
+		records = append(records, Record{Seq: seq, Event: event})
+	}
+	slices.SortFunc(records, func(a, b Record) int {
+		return cmp.Compare(a.Seq, b.Seq)
+	})
+
+	return records
+}
+
+func (o *Outbox) MarkDelivered(seq uint64) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if _, ok := o.pending[seq]; !ok {
+		return nil
+	}
+
+	line, err := json.Marshal(entry{Op: opAck, Seq: seq})
+	if err != nil {
+		return fmt.Errorf("failed to encode outbox entry: %w", err)
+	}
+	if err = o.write(append(line, '\n')); err != nil {
+		return err
+	}
+
+	delete(o.pending, seq)
+	o.acked++
+
+	if o.acked >= compactThreshold {
+		return o.compact()
+	}
+
+	return nil
+}
+
+func (o *Outbox) Notify() <-chan struct{} {
+	return o.notify
+}
+
+func (o *Outbox) Close() error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.file == nil {
+		return nil
+	}
+	err := o.file.Close()
+	o.file = nil
+
+	return err
+}
+
+func (o *Outbox) signal() {
+	select {
+	case o.notify <- struct{}{}:
+	default:
+	}
+}
+
+func (o *Outbox) write(data []byte) error {
+	if o.path == "" {
+		return nil
+	}
+	if o.file == nil {
+		return errors.New("outbox is closed")
+	}
+	if o.failed != nil {
+		// хвост файла неизвестен: переписываем журнал из памяти, прежде чем дописывать
+		if err := o.compact(); err != nil {
+			return fmt.Errorf("outbox is unusable after a failed write: %w", errors.Join(o.failed, err))
+		}
+	}
+
+	_, err := o.file.Write(data)
+	if err == nil {
+		if err = o.file.Sync(); err != nil {
+			err = fmt.Errorf("failed to sync outbox: %w", err)
+		}
+	} else {
+		err = fmt.Errorf("failed to write outbox: %w", err)
+	}
+	if err != nil {
+		// недописанная строка склеилась бы со следующей, поэтому откатываемся к последней целой
+		if truncErr := o.file.Truncate(o.size); truncErr != nil {
+			o.failed = truncErr
+			return errors.Join(err, fmt.Errorf("failed to roll back outbox: %w", truncErr))
+		}
+		return err
+	}
+	o.size += int64(len(data))
+
+	return nil
+}
+
+func (o *Outbox) load() error {
+	file, err := os.Open(o.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open outbox: %w", err)
+	}
+	defer file.Close()
+
+	var corrupt [][]byte
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64<<10), 16<<20)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+
+		e, ok := parseEntry(line)
+		if !ok {
+			// недописанная строка: пропускаем её, но не всё, что записано после
+			corrupt = append(corrupt, bytes.Clone(line))
+			continue
+		}
+
+		switch e.Op {
+		case opAppend:
+			if e.Event != nil {
+				o.pending[e.Seq] = *e.Event
+			}
+		case opAck:
+			delete(o.pending, e.Seq)
+		}
+		o.seq = max(o.seq, e.Seq)
+	}
+
+	if err = scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read outbox: %w", err)
+	}
+
+	return o.quarantine(corrupt)
+}
+
+// parseEntry разбирает строку журнала. Если к недописанной строке приклеилась
+// целая запись, она восстанавливается.
+func parseEntry(line []byte) (entry, bool) {
+	var e entry
+	if json.Unmarshal(line, &e) == nil {
+		return e, true
+	}
+	if i := bytes.LastIndex(line, []byte(`{"op":`)); i > 0 && json.Unmarshal(line[i:], &e) == nil {
+		return e, true
+	}
+	return entry{}, false
+}
+
+// quarantine сохраняет нечитаемые строки рядом с журналом, прежде чем сжатие их удалит.
+func (o *Outbox) quarantine(lines [][]byte) error {
+	if len(lines) == 0 {
+		return nil
+	}
+
+	path := o.path + ".corrupt"
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to quarantine outbox lines: %w", err)
+	}
+	data := append(bytes.Join(lines, []byte("\n")), '\n')
+	if _, err = file.Write(data); err == nil {
+		err = file.Sync()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to quarantine outbox lines: %w", err)
+	}
+
+	slog.Warn("outbox: skipped unreadable lines", "lines", len(lines), "quarantine", path)
+
+	return nil
+}
+
+func (o *Outbox) compact() error {
+	if o.path == "" {
+		o.acked = 0
+		return nil
+	}
+
+	tmpPath := o.path + ".tmp"
+	tmp, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to create outbox: %w", err)
+	}
+
+	writer := bufio.NewWriter(tmp)
+	encoder := json.NewEncoder(writer)
+	seqs := make([]uint64, 0, len(o.pending))
+	for seq := range o.pending {
+		seqs = append(seqs, seq)
+	}
+	slices.Sort(seqs)
+
+	for _, seq := range seqs {
+		event := o.pending[seq]
+		if err = encoder.Encode(entry{Op: opAppend, Seq: seq, Event: &event}); err != nil {
+			tmp.Close()
+			return fmt.Errorf("failed to compact outbox: %w", err)
+		}
+	}
+	// сохраняем счётчик, чтобы после перезапуска номера не повторялись
+	if len(seqs) == 0 && o.seq > 0 {
+		if err = encoder.Encode(entry{Op: opAck, Seq: o.seq}); err != nil {
+			tmp.Close()
+			return fmt.Errorf("failed to compact outbox: %w", err)
+		}
+	}
+
+	if err = writer.Flush(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to compact outbox: %w", err)
+	}
+	if err = tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to compact outbox: %w", err)
+	}
+	if err = tmp.Close(); err != nil {
+		return fmt.Errorf("failed to compact outbox: %w", err)
+	}
+	if err = os.Rename(tmpPath, o.path); err != nil {
+		return fmt.Errorf("failed to compact outbox: %w", err)
+	}
+
+	if o.file != nil {
+		o.file.Close()
+	}
+	file, err := os.OpenFile(o.path, os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		o.file = nil
+		return fmt.Errorf("failed to open outbox: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		o.file = nil
+		return fmt.Errorf("failed to open outbox: %w", err)
+	}
+	o.file, o.size, o.failed = file, info.Size(), nil
+	o.acked = 0
+
+	return nil
+}
@@ -0,0 +1,250 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/Ant-Tab-Shift/todos-service/internal/domain"
+)
+
+type mockPublisher struct {
+	published []domain.Event
+	failAt    int
+}
+
+func (m *mockPublisher) Publish(ctx context.Context, event domain.Event) error {
+	if m.failAt > 0 && len(m.published)+1 == m.failAt {
+		m.failAt = 0
+		return errors.New("publish failed")
+	}
+	m.published = append(m.published, event)
+	return nil
+}
+
+func TestOutbox_AppendAndDeliver(t *testing.T) {
+	ob, err := Open("")
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+
+	err = ob.Append(
+		domain.Event{Kind: domain.TaskCreated, TaskID: 1},
+		domain.Event{Kind: domain.TaskUpdated, TaskID: 1},
+	)
+	if err != nil {
+		t.Fatalf("Append failed: %v", err)
+	}
+
+	pending := ob.Pending()
+	if len(pending) != 2 {
+		t.Fatalf("len(pending) = %d, want 2", len(pending))
+	}
+	if pending[0].Seq != 1 || pending[1].Seq != 2 {
+		t.Errorf("seqs = %d, %d, want 1, 2", pending[0].Seq, pending[1].Seq)
+	}
+
+	if err = ob.MarkDelivered(1); err != nil {
+		t.Fatalf("MarkDelivered failed: %v", err)
+	}
+	if pending = ob.Pending(); len(pending) != 1 || pending[0].Seq != 2 {
+		t.Errorf("pending = %+v, want only seq 2", pending)
+	}
+}
+
+func TestOutbox_Publish(t *testing.T) {
+	ob, _ := Open("")
+
+	if err := ob.Publish(context.Background(), domain.Event{Kind: domain.TaskDeleted, TaskID: 3}); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+	if pending := ob.Pending(); len(pending) != 1 || pending[0].Event.TaskID != 3 {
+		t.Errorf("pending = %+v, want one event for task 3", pending)
+	}
+}
+
+func TestOutbox_PendingSurvivesReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data", "outbox.jsonl")
+
+	ob, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	_ = ob.Append(domain.Event{Kind: domain.TaskCreated, TaskID: 1, Task: domain.TaskSchema{Title: "first"}})
+	_ = ob.Append(domain.Event{Kind: domain.TaskCreated, TaskID: 2, Task: domain.TaskSchema{Title: "second"}})
+	if err = ob.MarkDelivered(1); err != nil {
+		t.Fatalf("MarkDelivered failed: %v", err)
+	}
+	if err = ob.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	reopened, err := Open(path)
+	if err != nil {
+		t.Fatalf("reopen failed: %v", err)
+	}
+	defer reopened.Close()
+
+	pending := reopened.Pending()
+	if len(pending) != 1 {
+		t.Fatalf("len(pending) = %d, want 1", len(pending))
+	}
+	if pending[0].Seq != 2 || pending[0].Event.Task.Title != "second" {
+		t.Errorf("pending[0] = %+v, want seq 2 'second'", pending[0])
+	}
+
+	if err = reopened.Append(domain.Event{Kind: domain.TaskDeleted, TaskID: 2}); err != nil {
+		t.Fatalf("Append failed: %v", err)
+	}
+	if pending = reopened.Pending(); pending[len(pending)-1].Seq != 3 {
+		t.Errorf("new seq = %d, want 3", pending[len(pending)-1].Seq)
+	}
+}
+
+func TestOutbox_IgnoresTruncatedTail(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "outbox.jsonl")
+	content := `{"op":"append","seq":1,"event":{"Kind":"task.created","TaskID":1}}` + "\n" + `{"op":"app`
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+
+	ob, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer ob.Close()
+
+	if pending := ob.Pending(); len(pending) != 1 || pending[0].Event.TaskID != 1 {
+		t.Errorf("pending = %+v, want single event for task 1", pending)
+	}
+}
+
+func TestOutbox_SkipsCorruptLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "outbox.jsonl")
+	content := `{"op":"append","seq":1,"event":{"Kind":"task.created","TaskID":1}}` + "\n" +
+		`{"op":"append","seq":2,"ev` + "\n" +
+		`{"op":"append","seq":3,"ev{"op":"append","seq":4,"event":{"Kind":"task.created","TaskID":4}}` + "\n" +
+		`{"op":"append","seq":5,"event":{"Kind":"task.created","TaskID":5}}` + "\n" +
+		`{"op":"ack","seq":1}` + "\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+
+	ob, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer ob.Close()
+
+	var seqs []uint64
+	for _, record := range ob.Pending() {
+		seqs = append(seqs, record.Seq)
+	}
+	if !slices.Equal(seqs, []uint64{4, 5}) {
+		t.Errorf("pending seqs = %v, want [4 5]", seqs)
+	}
+
+	quarantined, err := os.ReadFile(path + ".corrupt")
+	if err != nil {
+		t.Fatalf("quarantine not written: %v", err)
+	}
+	if want := `{"op":"append","seq":2,"ev` + "\n"; string(quarantined) != want {
+		t.Errorf("quarantine = %q, want %q", quarantined, want)
+	}
+}
+
+// shortWriter дописывает только часть данных и возвращает ошибку, как при нехватке места.
+type shortWriter struct {
+	journal
+}
+
+func (w shortWriter) Write(p []byte) (int, error) {
+	n, _ := w.journal.Write(p[:len(p)/2])
+	return n, errors.New("no space left on device")
+}
+
+func TestOutbox_RollsBackFailedWrite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "outbox.jsonl")
+	ob, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+
+	_ = ob.Append(domain.Event{TaskID: 1})
+	file := ob.file
+	ob.file = shortWriter{file}
+	if err = ob.Append(domain.Event{TaskID: 2}); err == nil {
+		t.Fatal("Append should fail on a short write")
+	}
+	ob.file = file
+	if err = ob.Append(domain.Event{TaskID: 3}); err != nil {
+		t.Fatalf("Append failed: %v", err)
+	}
+	ob.Close()
+
+	data, _ := os.ReadFile(path)
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		if !json.Valid([]byte(line)) {
+			t.Errorf("journal line %q is not valid JSON", line)
+		}
+	}
+
+	reopened, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer reopened.Close()
+
+	var ids []uint64
+	for _, record := range reopened.Pending() {
+		ids = append(ids, record.Event.TaskID)
+	}
+	if !slices.Equal(ids, []uint64{1, 3}) {
+		t.Errorf("pending tasks = %v, want [1 3]", ids)
+	}
+	if _, err = os.Stat(path + ".corrupt"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("no lines should be quarantined, stat error = %v", err)
+	}
+}
+
+func TestRelay_FlushRetriesFromFailedEvent(t *testing.T) {
+	ob, _ := Open("")
+	_ = ob.Append(
+		domain.Event{TaskID: 1},
+		domain.Event{TaskID: 2},
+		domain.Event{TaskID: 3},
+	)
+
+	publisher := &mockPublisher{failAt: 2}
+	relay := NewRelay(ob, publisher, 0)
+	ctx := context.Background()
+
+	if err := relay.Flush(ctx); err == nil {
+		t.Fatal("Flush should fail when publisher fails")
+	}
+	if len(ob.Pending()) != 2 {
+		t.Fatalf("len(pending) = %d, want 2", len(ob.Pending()))
+	}
+
+	if err := relay.Flush(ctx); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+	if len(ob.Pending()) != 0 {
+		t.Errorf("len(pending) = %d, want 0", len(ob.Pending()))
+	}
+
+	want := []uint64{1, 2, 3}
+	if len(publisher.published) != len(want) {
+		t.Fatalf("published %d events, want %d", len(publisher.published), len(want))
+	}
+	for i, event := range publisher.published {
+		if event.TaskID != want[i] {
+			t.Errorf("published[%d].TaskID = %d, want %d", i, event.TaskID, want[i])
+		}
+	}
+}
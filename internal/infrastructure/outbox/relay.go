@@ -0,0 +1,88 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/Ant-Tab-Shift/todos-service/internal/domain"
+	"github.com/Ant-Tab-Shift/todos-service/internal/logging"
+)
+
+type Publisher interface {
+	Publish(ctx context.Context, event domain.Event) error
+}
+
+type Relay struct {
+	outbox    *Outbox
+	publisher Publisher
+	interval  time.Duration
+
+	mu      sync.Mutex
+	lastErr error
+}
+
+func NewRelay(outbox *Outbox, publisher Publisher, interval time.Duration) *Relay {
+	return &Relay{
+		outbox:    outbox,
+		publisher: publisher,
+		interval:  interval,
+	}
+}
+
+func (r *Relay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-r.outbox.Notify():
+		case <-ticker.C:
+		}
+
+		err := r.Flush(ctx)
+		if ctx.Err() != nil {
+			continue
+		}
+		if err != nil {
+			slog.ErrorContext(ctx, "outbox relay failed", logging.Err(err))
+		}
+
+		r.mu.Lock()
+		r.lastErr = err
+		r.mu.Unlock()
+	}
+}
+
+// Check возвращает ошибку последней фоновой доставки событий.
+func (r *Relay) Check(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.lastErr != nil {
+		return fmt.Errorf("outbox relay is failing: %w", r.lastErr)
+	}
+	return nil
+}
+
+func (r *Relay) Flush(ctx context.Context) error {
+	for _, record := range r.outbox.Pending() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		// порядок событий важен, поэтому при ошибке прерываемся и повторяем позже
+		if err := r.publisher.Publish(ctx, record.Event); err != nil {
+			return fmt.Errorf("failed to publish event %d: %w", record.Seq, err)
+		}
+		if err := r.outbox.MarkDelivered(record.Seq); err != nil {
+			return fmt.Errorf("failed to mark event %d delivered: %w", record.Seq, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,112 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Ant-Tab-Shift/todos-service/internal/domain"
+	"github.com/Ant-Tab-Shift/todos-service/internal/metrics"
+	"github.com/Ant-Tab-Shift/todos-service/internal/tracing"
+)
+
+type Store[V any] interface {
+	Save(ctx context.Context, value V, id uint64, record domain.Recorder) (uint64, error)
+	GetByID(ctx context.Context, id uint64) (V, error)
+	GetByIDWithRevision(ctx context.Context, id uint64) (V, domain.Revision, error)
+	Revision(ctx context.Context) (domain.Revision, error)
+	GetAll(ctx context.Context) ([]domain.Elem[V], error)
+	GetPage(ctx context.Context, after uint64, limit int) ([]domain.Elem[V], error)
+	Insert(ctx context.Context, value V, id uint64, record domain.Recorder) error
+	Delete(ctx context.Context, id uint64, record domain.Recorder) error
+}
+
+// Instrumented замеряет длительность операций обёрнутого хранилища и пишет их спаны.
+type Instrumented[V any] struct {
+	next     Store[V]
+	duration *metrics.HistogramVec
+}
+
+func NewInstrumented[V any](next Store[V], registry *metrics.Registry) *Instrumented[V] {
+	return &Instrumented[V]{
+		next: next,
+		duration: registry.NewHistogramVec("storage_operation_duration_seconds",
+			"Storage operation latency by operation and outcome.", metrics.DefBuckets, "operation", "outcome"),
+	}
+}
+
+func (s *Instrumented[V]) Save(ctx context.Context, value V, id uint64, record domain.Recorder) (uint64, error) {
+	ctx, span, start := s.start(ctx, "storage.Save")
+	id, err := s.next.Save(ctx, value, id, record)
+	s.observe(span, "save", start, err)
+	return id, err
+}
+
+func (s *Instrumented[V]) GetByID(ctx context.Context, id uint64) (V, error) {
+	ctx, span, start := s.start(ctx, "storage.GetByID")
+	value, err := s.next.GetByID(ctx, id)
+	s.observe(span, "get_by_id", start, err)
+	return value, err
+}
+
+func (s *Instrumented[V]) GetByIDWithRevision(ctx context.Context, id uint64) (V, domain.Revision, error) {
+	ctx, span, start := s.start(ctx, "storage.GetByIDWithRevision")
+	value, revision, err := s.next.GetByIDWithRevision(ctx, id)
+	s.observe(span, "get_by_id", start, err)
+	return value, revision, err
+}
+
+func (s *Instrumented[V]) Revision(ctx context.Context) (domain.Revision, error) {
+	ctx, span, start := s.start(ctx, "storage.Revision")
+	revision, err := s.next.Revision(ctx)
+	s.observe(span, "revision", start, err)
+	return revision, err
+}
+
+func (s *Instrumented[V]) GetAll(ctx context.Context) ([]domain.Elem[V], error) {
+	ctx, span, start := s.start(ctx, "storage.GetAll")
+	elems, err := s.next.GetAll(ctx)
+	s.observe(span, "get_all", start, err)
+	return elems, err
+}
+
+func (s *Instrumented[V]) GetPage(ctx context.Context, after uint64, limit int) ([]domain.Elem[V], error) {
+	ctx, span, start := s.start(ctx, "storage.GetPage")
+	elems, err := s.next.GetPage(ctx, after, limit)
+	s.observe(span, "get_page", start, err)
+	return elems, err
+}
+
+func (s *Instrumented[V]) Insert(ctx context.Context, value V, id uint64, record domain.Recorder) error {
+	ctx, span, start := s.start(ctx, "storage.Insert")
+	err := s.next.Insert(ctx, value, id, record)
+	s.observe(span, "insert", start, err)
+	return err
+}
+
+func (s *Instrumented[V]) Delete(ctx context.Context, id uint64, record domain.Recorder) error {
+	ctx, span, start := s.start(ctx, "storage.Delete")
+	err := s.next.Delete(ctx, id, record)
+	s.observe(span, "delete", start, err)
+	return err
+}
+
+func (s *Instrumented[V]) start(ctx context.Context, name string) (context.Context, *tracing.Span, time.Time) {
+	ctx, span := tracing.Start(ctx, name)
+	return ctx, span, time.Now()
+}
+
+func (s *Instrumented[V]) observe(span *tracing.Span, operation string, start time.Time, err error) {
+	// ErrNotExists и ErrAlreadyExists - штатные ответы хранилища, а не сбой
+	if errors.Is(err, domain.ErrNotExists) || errors.Is(err, domain.ErrAlreadyExists) {
+		span.End()
+	} else {
+		span.Finish(err)
+	}
+
+	outcome := "ok"
+	if err != nil {
+		outcome = "error"
+	}
+	s.duration.Observe(time.Since(start).Seconds(), operation, outcome)
+}
@@ -2,7 +2,11 @@ package storage
 
 import (
 	"context"
+	"fmt"
+	"log/slog"
+	"slices"
 	"sync"
+	"time"
 
 	"github.com/Ant-Tab-Shift/todos-service/internal/domain"
 )
@@ -11,16 +15,39 @@ type InMemory[V any] struct {
 	rwm      sync.RWMutex
 	serialID uint64
 	data     map[uint64]V
+	// ids — ключи data по возрастанию, чтобы GetPage не сортировал всё хранилище
+	ids []uint64
+
+	// revision — счётчик изменений всей коллекции, revisions — версии отдельных записей
+	revision  domain.Revision
+	revisions map[uint64]domain.Revision
+
+	// matched — сколько записей удовлетворяет match; считается при каждом изменении,
+	// чтобы Count не обходил всё хранилище
+	match   func(V) bool
+	matched int
 }
 
 func NewInMemory[V any]() *InMemory[V] {
+	return NewCountingInMemory[V](nil)
+}
+
+// NewCountingInMemory создаёт хранилище, которое ведёт счётчик записей,
+// удовлетворяющих match. Без match счётчик всегда равен нулю.
+func NewCountingInMemory[V any](match func(V) bool) *InMemory[V] {
+	now := time.Now()
+
 	return &InMemory[V]{
 		serialID: 1,
 		data:     make(map[uint64]V),
+		// счётчик начинается с момента запуска, чтобы версии не повторялись после рестарта
+		revision:  domain.Revision{Version: uint64(now.UnixNano()), ModifiedAt: now},
+		revisions: make(map[uint64]domain.Revision),
+		match:     match,
 	}
 }
 
-func (m *InMemory[V]) Save(ctx context.Context, value V, id uint64) (uint64, error) {
+func (m *InMemory[V]) Save(ctx context.Context, value V, id uint64, record domain.Recorder) (uint64, error) {
 	err := ctx.Err()
 	if err != nil {
 		return 0, err
@@ -33,15 +60,67 @@ func (m *InMemory[V]) Save(ctx context.Context, value V, id uint64) (uint64, err
 		return 0, err
 	}
 
-	if id == 0 {
+	generated := id == 0
+	if generated {
 		id = m.serialID
+	}
+
+	if err = m.record(id, record); err != nil {
+		return 0, err
+	}
+
+	if generated {
 		m.serialID++
 	}
+	if prev, ok := m.data[id]; ok {
+		m.matched -= m.matches(prev)
+	}
+	m.matched += m.matches(value)
 	m.data[id] = value
+	m.revisions[id] = m.touch()
+	m.addID(id)
+
+	slog.DebugContext(ctx, "storage: value saved", "id", id, "created", generated)
 
 	return id, nil
 }
 
+// Insert сохраняет значение под заданным ID, только если он свободен. Счётчик
+// сдвигается за этот ID, чтобы новые записи не получили его повторно.
+func (m *InMemory[V]) Insert(ctx context.Context, value V, id uint64, record domain.Recorder) error {
+	if id == 0 {
+		return fmt.Errorf("insert requires a non-zero id")
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.rwm.Lock()
+	defer m.rwm.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if _, ok := m.data[id]; ok {
+		return domain.ErrAlreadyExists
+	}
+
+	if err := m.record(id, record); err != nil {
+		return err
+	}
+
+	m.serialID = max(m.serialID, id+1)
+	m.matched += m.matches(value)
+	m.data[id] = value
+	m.revisions[id] = m.touch()
+	m.addID(id)
+
+	slog.DebugContext(ctx, "storage: value inserted", "id", id)
+
+	return nil
+}
+
 func (m *InMemory[V]) GetByID(ctx context.Context, id uint64) (V, error) {
 	var (
 		err  error
@@ -91,7 +170,7 @@ func (m *InMemory[V]) GetAll(ctx context.Context) ([]domain.Elem[V], error) {
 	return elems, nil
 }
 
-func (m *InMemory[V]) Delete(ctx context.Context, id uint64) error {
+func (m *InMemory[V]) Delete(ctx context.Context, id uint64, record domain.Recorder) error {
 	err := ctx.Err()
 	if err != nil {
 		return err
@@ -104,10 +183,223 @@ func (m *InMemory[V]) Delete(ctx context.Context, id uint64) error {
 		return err
 	}
 
-	if _, ok := m.data[id]; !ok {
+	prev, ok := m.data[id]
+	if !ok {
 		return domain.ErrNotExists
 	}
+
+	if err = m.record(id, record); err != nil {
+		return err
+	}
+	m.matched -= m.matches(prev)
 	delete(m.data, id)
+	delete(m.revisions, id)
+	m.removeID(id)
+	m.touch()
+
+	slog.DebugContext(ctx, "storage: value deleted", "id", id)
+
+	return nil
+}
+
+// GetPage возвращает до limit записей с ID больше after по возрастанию ID.
+// Блокировка держится только на время чтения страницы, поэтому обход всего
+// хранилища страницами не мешает записи.
+func (m *InMemory[V]) GetPage(ctx context.Context, after uint64, limit int) ([]domain.Elem[V], error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	m.rwm.RLock()
+	defer m.rwm.RUnlock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	start, found := slices.BinarySearch(m.ids, after)
+	if found {
+		start++
+	}
+	ids := m.ids[start:min(len(m.ids), start+limit)]
+
+	elems := make([]domain.Elem[V], len(ids))
+	for i, id := range ids {
+		elems[i] = domain.Elem[V]{ID: id, Value: m.data[id]}
+	}
+
+	return elems, nil
+}
+
+// GetByIDWithRevision возвращает запись вместе с её версией, прочитанные атомарно.
+func (m *InMemory[V]) GetByIDWithRevision(ctx context.Context, id uint64) (V, domain.Revision, error) {
+	var zero V
+	if err := ctx.Err(); err != nil {
+		return zero, domain.Revision{}, err
+	}
+
+	m.rwm.RLock()
+	defer m.rwm.RUnlock()
+
+	if err := ctx.Err(); err != nil {
+		return zero, domain.Revision{}, err
+	}
+
+	value, ok := m.data[id]
+	if !ok {
+		return zero, domain.Revision{}, domain.ErrNotExists
+	}
+
+	return value, m.revisions[id], nil
+}
+
+// Revision возвращает версию всей коллекции; она меняется при любом сохранении или удалении.
+func (m *InMemory[V]) Revision(ctx context.Context) (domain.Revision, error) {
+	if err := ctx.Err(); err != nil {
+		return domain.Revision{}, err
+	}
+
+	m.rwm.RLock()
+	defer m.rwm.RUnlock()
+
+	return m.revision, nil
+}
+
+// Count возвращает число всех записей и тех, что удовлетворяют условию NewCountingInMemory.
+func (m *InMemory[V]) Count() (total, matched int) {
+	m.rwm.RLock()
+	defer m.rwm.RUnlock()
+
+	return len(m.data), m.matched
+}
+
+// Snapshot копирует всё хранилище под блокировкой на чтение, поэтому снимок
+// согласован: в нём нет записей, сохранённых после выданного SerialID.
+func (m *InMemory[V]) Snapshot(ctx context.Context) (domain.Snapshot[V], error) {
+	if err := ctx.Err(); err != nil {
+		return domain.Snapshot[V]{}, err
+	}
+
+	m.rwm.RLock()
+	defer m.rwm.RUnlock()
+
+	if err := ctx.Err(); err != nil {
+		return domain.Snapshot[V]{}, err
+	}
+
+	elems := make([]domain.Elem[V], 0, len(m.ids))
+	for _, id := range m.ids {
+		elems = append(elems, domain.Elem[V]{ID: id, Value: m.data[id]})
+	}
+
+	return domain.Snapshot[V]{SerialID: m.serialID, Elems: elems}, nil
+}
+
+// Restore заменяет всё содержимое хранилища снимком. Снимок проверяется и
+// раскладывается заранее, а под блокировкой на запись только подменяются данные,
+// поэтому читатели видят либо прежнее состояние, либо восстановленное целиком.
+// События о восстановленных записях не пишутся; версии всех записей меняются.
+func (m *InMemory[V]) Restore(ctx context.Context, snapshot domain.Snapshot[V]) error {
+	// ID 0 означает «выдать новый», поэтому счётчик, как и в NewInMemory, начинается с 1
+	if snapshot.SerialID < 1 {
+		return fmt.Errorf("%w: serial id must be at least 1", domain.ErrInvalidSnapshot)
+	}
+
+	data := make(map[uint64]V, len(snapshot.Elems))
+	ids := make([]uint64, 0, len(snapshot.Elems))
+	matched := 0
+	for _, elem := range snapshot.Elems {
+		if elem.ID == 0 {
+			return fmt.Errorf("%w: zero id", domain.ErrInvalidSnapshot)
+		}
+		if _, ok := data[elem.ID]; ok {
+			return fmt.Errorf("%w: duplicate id %d", domain.ErrInvalidSnapshot, elem.ID)
+		}
+		// иначе новые записи получили бы ID восстановленных
+		if elem.ID >= snapshot.SerialID {
+			return fmt.Errorf("%w: id %d is not below serial id %d", domain.ErrInvalidSnapshot, elem.ID, snapshot.SerialID)
+		}
+		data[elem.ID] = elem.Value
+		ids = append(ids, elem.ID)
+		matched += m.matches(elem.Value)
+	}
+	slices.Sort(ids)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.rwm.Lock()
+	defer m.rwm.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	revision := m.touch()
+	revisions := make(map[uint64]domain.Revision, len(data))
+	for id := range data {
+		revisions[id] = revision
+	}
+	m.data, m.revisions, m.ids, m.serialID = data, revisions, ids, snapshot.SerialID
+	m.matched = matched
+
+	slog.InfoContext(ctx, "storage: snapshot restored", "values", len(data), "serial_id", snapshot.SerialID)
+
+	return nil
+}
+
+// Ping проверяет, что хранилище доступно и блокировка не удерживается дольше контекста.
+func (m *InMemory[V]) Ping(ctx context.Context) error {
+	acquired := make(chan struct{})
+	go func() {
+		m.rwm.RLock()
+		m.rwm.RUnlock()
+		close(acquired)
+	}()
+
+	select {
+	case <-acquired:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("storage is unavailable: %w", ctx.Err())
+	}
+}
+
+// addID добавляет ID в упорядоченный индекс, если его там нет; вызывается под блокировкой на запись.
+// Новые ID почти всегда больше прежних, поэтому вставка обычно приходится на конец.
+func (m *InMemory[V]) addID(id uint64) {
+	if i, found := slices.BinarySearch(m.ids, id); !found {
+		m.ids = slices.Insert(m.ids, i, id)
+	}
+}
+
+func (m *InMemory[V]) removeID(id uint64) {
+	if i, found := slices.BinarySearch(m.ids, id); found {
+		m.ids = slices.Delete(m.ids, i, i+1)
+	}
+}
+
+func (m *InMemory[V]) matches(value V) int {
+	if m.match != nil && m.match(value) {
+		return 1
+	}
+	return 0
+}
+
+// touch фиксирует изменение коллекции; вызывается под блокировкой на запись.
+func (m *InMemory[V]) touch() domain.Revision {
+	m.revision = domain.Revision{Version: m.revision.Version + 1, ModifiedAt: time.Now()}
+	return m.revision
+}
+
+func (m *InMemory[V]) record(id uint64, record domain.Recorder) error {
+	if record == nil {
+		return nil
+	}
+
+	if err := record(id); err != nil {
+		return fmt.Errorf("failed to record events: %w", err)
+	}
 
 	return nil
 }
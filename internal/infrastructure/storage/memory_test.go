@@ -3,6 +3,7 @@ package storage
 import (
 	"context"
 	"errors"
+	"fmt"
 	"testing"
 	"time"
 
@@ -33,7 +34,7 @@ func TestInMemory_Save_WithAutoID(t *testing.T) {
 	ctx := context.Background()
 
 	data1 := testData{Name: "first", Value: 1}
-	id1, err := storage.Save(ctx, data1, 0)
+	id1, err := storage.Save(ctx, data1, 0, nil)
 	if err != nil {
 		t.Fatalf("Save failed: %v", err)
 	}
@@ -42,7 +43,7 @@ func TestInMemory_Save_WithAutoID(t *testing.T) {
 	}
 
 	data2 := testData{Name: "second", Value: 2}
-	id2, err := storage.Save(ctx, data2, 0)
+	id2, err := storage.Save(ctx, data2, 0, nil)
 	if err != nil {
 		t.Fatalf("Save failed: %v", err)
 	}
@@ -60,7 +61,7 @@ func TestInMemory_Save_WithSpecificID(t *testing.T) {
 	ctx := context.Background()
 
 	data := testData{Name: "test", Value: 42}
-	id, err := storage.Save(ctx, data, 100)
+	id, err := storage.Save(ctx, data, 100, nil)
 	if err != nil {
 		t.Fatalf("Save failed: %v", err)
 	}
@@ -82,13 +83,13 @@ func TestInMemory_Save_Update(t *testing.T) {
 	ctx := context.Background()
 
 	original := testData{Name: "original", Value: 1}
-	id, err := storage.Save(ctx, original, 0)
+	id, err := storage.Save(ctx, original, 0, nil)
 	if err != nil {
 		t.Fatalf("Save failed: %v", err)
 	}
 
 	updated := testData{Name: "updated", Value: 2}
-	_, err = storage.Save(ctx, updated, id)
+	_, err = storage.Save(ctx, updated, id, nil)
 	if err != nil {
 		t.Fatalf("Update failed: %v", err)
 	}
@@ -108,7 +109,7 @@ func TestInMemory_Save_CancelledContext(t *testing.T) {
 	cancel()
 
 	data := testData{Name: "test", Value: 1}
-	_, err := storage.Save(ctx, data, 0)
+	_, err := storage.Save(ctx, data, 0, nil)
 	if err == nil {
 		t.Error("Save should fail with cancelled context")
 	}
@@ -122,7 +123,7 @@ func TestInMemory_GetByID_Success(t *testing.T) {
 	ctx := context.Background()
 
 	expected := testData{Name: "test", Value: 42}
-	id, _ := storage.Save(ctx, expected, 0)
+	id, _ := storage.Save(ctx, expected, 0, nil)
 
 	result, err := storage.GetByID(ctx, id)
 	if err != nil {
@@ -181,9 +182,9 @@ func TestInMemory_GetAll_MultipleItems(t *testing.T) {
 	data2 := testData{Name: "second", Value: 2}
 	data3 := testData{Name: "third", Value: 3}
 
-	id1, _ := storage.Save(ctx, data1, 0)
-	id2, _ := storage.Save(ctx, data2, 0)
-	id3, _ := storage.Save(ctx, data3, 0)
+	id1, _ := storage.Save(ctx, data1, 0, nil)
+	id2, _ := storage.Save(ctx, data2, 0, nil)
+	id3, _ := storage.Save(ctx, data3, 0, nil)
 
 	elems, err := storage.GetAll(ctx)
 	if err != nil {
@@ -238,9 +239,9 @@ func TestInMemory_Delete_Success(t *testing.T) {
 	ctx := context.Background()
 
 	data := testData{Name: "test", Value: 1}
-	id, _ := storage.Save(ctx, data, 0)
+	id, _ := storage.Save(ctx, data, 0, nil)
 
-	err := storage.Delete(ctx, id)
+	err := storage.Delete(ctx, id, nil)
 	if err != nil {
 		t.Fatalf("Delete failed: %v", err)
 	}
@@ -259,7 +260,7 @@ func TestInMemory_Delete_NotExists(t *testing.T) {
 	storage := NewInMemory[testData]()
 	ctx := context.Background()
 
-	err := storage.Delete(ctx, 999)
+	err := storage.Delete(ctx, 999, nil)
 	if err == nil {
 		t.Error("Delete should fail for non-existent id")
 	}
@@ -273,7 +274,7 @@ func TestInMemory_Delete_CancelledContext(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	cancel()
 
-	err := storage.Delete(ctx, 1)
+	err := storage.Delete(ctx, 1, nil)
 	if err == nil {
 		t.Error("Delete should fail with cancelled context")
 	}
@@ -292,7 +293,7 @@ func TestInMemory_ConcurrentAccess(t *testing.T) {
 	for i := 0; i < operations; i++ {
 		go func(val int) {
 			data := testData{Name: "concurrent", Value: val}
-			_, err := storage.Save(ctx, data, 0)
+			_, err := storage.Save(ctx, data, 0, nil)
 			if err != nil {
 				t.Errorf("concurrent Save failed: %v", err)
 			}
@@ -324,3 +325,322 @@ func TestInMemory_ConcurrentAccess(t *testing.T) {
 		t.Errorf("final count = %d, want %d", len(elems), operations)
 	}
 }
+
+type mockOutbox struct {
+	events    []domain.Event
+	appendErr error
+}
+
+func (m *mockOutbox) record(kind domain.EventKind) domain.Recorder {
+	return func(id uint64) error {
+		if m.appendErr != nil {
+			return m.appendErr
+		}
+		m.events = append(m.events, domain.Event{Kind: kind, TaskID: id})
+		return nil
+	}
+}
+
+func TestInMemory_Save_RecordsEventsWithAssignedID(t *testing.T) {
+	outbox := &mockOutbox{}
+	storage := NewInMemory[testData]()
+	ctx := context.Background()
+
+	id, err := storage.Save(ctx, testData{Name: "test"}, 0, outbox.record(domain.TaskCreated))
+	if err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	if len(outbox.events) != 1 {
+		t.Fatalf("len(events) = %d, want 1", len(outbox.events))
+	}
+	if outbox.events[0].TaskID != id {
+		t.Errorf("event TaskID = %d, want %d", outbox.events[0].TaskID, id)
+	}
+}
+
+func TestInMemory_OutboxFailureAbortsMutation(t *testing.T) {
+	outbox := &mockOutbox{}
+	storage := NewInMemory[testData]()
+	ctx := context.Background()
+
+	id, _ := storage.Save(ctx, testData{Name: "original"}, 0, nil)
+
+	expectedErr := errors.New("disk full")
+	outbox.appendErr = expectedErr
+
+	_, err := storage.Save(ctx, testData{Name: "new"}, 0, outbox.record(domain.TaskCreated))
+	if !errors.Is(err, expectedErr) {
+		t.Errorf("Save error = %v, want %v", err, expectedErr)
+	}
+	if len(storage.data) != 1 || storage.serialID != 2 {
+		t.Errorf("storage changed after failed Save: len = %d, serialID = %d", len(storage.data), storage.serialID)
+	}
+
+	_, err = storage.Save(ctx, testData{Name: "updated"}, id, outbox.record(domain.TaskUpdated))
+	if !errors.Is(err, expectedErr) {
+		t.Errorf("Save error = %v, want %v", err, expectedErr)
+	}
+	if value, _ := storage.GetByID(ctx, id); value.Name != "original" {
+		t.Errorf("value = %+v, want original", value)
+	}
+
+	err = storage.Delete(ctx, id, outbox.record(domain.TaskDeleted))
+	if !errors.Is(err, expectedErr) {
+		t.Errorf("Delete error = %v, want %v", err, expectedErr)
+	}
+	if _, err = storage.GetByID(ctx, id); err != nil {
+		t.Errorf("task should survive failed Delete, got %v", err)
+	}
+}
+
+func TestInMemory_Revision(t *testing.T) {
+	outbox := &mockOutbox{}
+	storage := NewInMemory[testData]()
+	ctx := context.Background()
+
+	initial, err := storage.Revision(ctx)
+	if err != nil {
+		t.Fatalf("Revision failed: %v", err)
+	}
+
+	id, _ := storage.Save(ctx, testData{Name: "first"}, 0, nil)
+	otherID, _ := storage.Save(ctx, testData{Name: "second"}, 0, nil)
+
+	_, created, err := storage.GetByIDWithRevision(ctx, id)
+	if err != nil {
+		t.Fatalf("GetByIDWithRevision failed: %v", err)
+	}
+	if created.Version <= initial.Version {
+		t.Errorf("task version = %d, want greater than %d", created.Version, initial.Version)
+	}
+
+	storage.Save(ctx, testData{Name: "updated"}, id, nil)
+	value, updated, _ := storage.GetByIDWithRevision(ctx, id)
+	if value.Name != "updated" || updated.Version <= created.Version || updated.ModifiedAt.Before(created.ModifiedAt) {
+		t.Errorf("after update: value = %+v, revision = %+v, previous = %+v", value, updated, created)
+	}
+
+	// изменение другой записи не меняет версию этой, но меняет версию коллекции
+	beforeDelete, _ := storage.Revision(ctx)
+	if err = storage.Delete(ctx, otherID, nil); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, unchanged, _ := storage.GetByIDWithRevision(ctx, id); unchanged != updated {
+		t.Errorf("task revision = %+v, want %+v", unchanged, updated)
+	}
+	afterDelete, _ := storage.Revision(ctx)
+	if afterDelete.Version <= beforeDelete.Version {
+		t.Errorf("collection version = %d after delete, want greater than %d", afterDelete.Version, beforeDelete.Version)
+	}
+
+	outbox.appendErr = errors.New("disk full")
+	storage.Save(ctx, testData{Name: "lost"}, id, outbox.record(domain.TaskUpdated))
+	if failed, _ := storage.Revision(ctx); failed != afterDelete {
+		t.Errorf("collection revision changed after failed Save: %+v, want %+v", failed, afterDelete)
+	}
+
+	if _, _, err = storage.GetByIDWithRevision(ctx, otherID); !errors.Is(err, domain.ErrNotExists) {
+		t.Errorf("error = %v, want ErrNotExists", err)
+	}
+}
+
+func TestInMemory_Insert(t *testing.T) {
+	outbox := &mockOutbox{}
+	storage := NewInMemory[testData]()
+	ctx := context.Background()
+
+	if err := storage.Insert(ctx, testData{Name: "imported"}, 10, outbox.record(domain.TaskCreated)); err != nil {
+		t.Fatalf("Insert failed: %v", err)
+	}
+	if len(outbox.events) != 1 || outbox.events[0].TaskID != 10 {
+		t.Errorf("events = %+v, want one event for task 10", outbox.events)
+	}
+
+	err := storage.Insert(ctx, testData{Name: "duplicate"}, 10, nil)
+	if !errors.Is(err, domain.ErrAlreadyExists) {
+		t.Errorf("Insert error = %v, want ErrAlreadyExists", err)
+	}
+	if value, _ := storage.GetByID(ctx, 10); value.Name != "imported" {
+		t.Errorf("value = %+v, want imported", value)
+	}
+
+	// ID ниже счётчика не сдвигает его назад
+	storage.Insert(ctx, testData{Name: "low"}, 3, nil)
+	id, _ := storage.Save(ctx, testData{Name: "next"}, 0, nil)
+	if id != 11 {
+		t.Errorf("next generated id = %d, want 11", id)
+	}
+
+	if err = storage.Insert(ctx, testData{}, 0, nil); err == nil {
+		t.Error("Insert with zero id should fail")
+	}
+}
+
+func TestInMemory_GetPage(t *testing.T) {
+	storage := NewInMemory[testData]()
+	ctx := context.Background()
+	for i := range 7 {
+		storage.Save(ctx, testData{Value: i + 1}, 0, nil)
+	}
+	storage.Delete(ctx, 3, nil)
+	// ID, заданные явно и не по порядку, попадают в индекс на свои места
+	storage.Save(ctx, testData{Value: 9}, 9, nil)
+	storage.Insert(ctx, testData{Value: 8}, 8, nil)
+
+	var pages [][]uint64
+	var after uint64
+	for {
+		page, err := storage.GetPage(ctx, after, 3)
+		if err != nil {
+			t.Fatalf("GetPage failed: %v", err)
+		}
+		if len(page) == 0 {
+			break
+		}
+
+		ids := make([]uint64, len(page))
+		for i, elem := range page {
+			ids[i] = elem.ID
+			if elem.Value.Value != int(elem.ID) {
+				t.Errorf("elem %d has value %d", elem.ID, elem.Value.Value)
+			}
+		}
+		pages = append(pages, ids)
+		after = page[len(page)-1].ID
+	}
+
+	want := [][]uint64{{1, 2, 4}, {5, 6, 7}, {8, 9}}
+	if fmt.Sprint(pages) != fmt.Sprint(want) {
+		t.Errorf("pages = %v, want %v", pages, want)
+	}
+
+	// страница может начинаться после удалённого ID
+	if page, _ := storage.GetPage(ctx, 3, 2); len(page) != 2 || page[0].ID != 4 || page[1].ID != 5 {
+		t.Errorf("page after deleted id = %+v, want ids 4, 5", page)
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := storage.GetPage(cancelled, 0, 3); !errors.Is(err, context.Canceled) {
+		t.Errorf("error = %v, want context.Canceled", err)
+	}
+}
+
+func TestInMemory_SnapshotRestore(t *testing.T) {
+	outbox := &mockOutbox{}
+	source := NewInMemory[testData]()
+	ctx := context.Background()
+
+	source.Save(ctx, testData{Name: "first"}, 0, nil)
+	second, _ := source.Save(ctx, testData{Name: "second"}, 0, nil)
+	source.Save(ctx, testData{Name: "third"}, 0, nil)
+	source.Delete(ctx, second, nil)
+
+	snapshot, err := source.Snapshot(ctx)
+	if err != nil {
+		t.Fatalf("Snapshot failed: %v", err)
+	}
+	want := domain.Snapshot[testData]{SerialID: 4, Elems: []domain.Elem[testData]{
+		{ID: 1, Value: testData{Name: "first"}},
+		{ID: 3, Value: testData{Name: "third"}},
+	}}
+	if fmt.Sprint(snapshot) != fmt.Sprint(want) {
+		t.Fatalf("snapshot = %+v, want %+v", snapshot, want)
+	}
+
+	target := NewInMemory[testData]()
+	target.Save(ctx, testData{Name: "replaced"}, 0, nil)
+	before, _ := target.Revision(ctx)
+	events := len(outbox.events)
+
+	if err = target.Restore(ctx, snapshot); err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+	// GetPage, в отличие от GetAll, отдаёт записи по возрастанию ID
+	all, _ := target.GetPage(ctx, 0, 10)
+	if fmt.Sprint(all) != fmt.Sprint(want.Elems) {
+		t.Errorf("restored = %+v, want %+v", all, want.Elems)
+	}
+	if id, _ := target.Save(ctx, testData{Name: "next"}, 0, outbox.record(domain.TaskCreated)); id != 4 {
+		t.Errorf("next id = %d, want 4", id)
+	}
+	if len(outbox.events) != events+1 {
+		t.Errorf("outbox has %d new events, want only the one of Save", len(outbox.events)-events)
+	}
+
+	// восстановление меняет версии, чтобы клиенты не сочли прежние ответы актуальными
+	if after, _ := target.Revision(ctx); after.Version <= before.Version {
+		t.Errorf("collection version = %d, want greater than %d", after.Version, before.Version)
+	}
+	if _, revision, _ := target.GetByIDWithRevision(ctx, 1); revision.Version <= before.Version {
+		t.Errorf("task version = %d, want greater than %d", revision.Version, before.Version)
+	}
+}
+
+func TestInMemory_Restore_InvalidSnapshot(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name     string
+		snapshot domain.Snapshot[testData]
+	}{
+		{"zero serial id", domain.Snapshot[testData]{SerialID: 0}},
+		{"zero id", domain.Snapshot[testData]{SerialID: 2, Elems: []domain.Elem[testData]{{ID: 0}}}},
+		{"duplicate id", domain.Snapshot[testData]{SerialID: 3, Elems: []domain.Elem[testData]{{ID: 1}, {ID: 1}}}},
+		{"id beyond serial", domain.Snapshot[testData]{SerialID: 2, Elems: []domain.Elem[testData]{{ID: 2}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storage := NewInMemory[testData]()
+			storage.Save(ctx, testData{Name: "kept"}, 0, nil)
+
+			if err := storage.Restore(ctx, tt.snapshot); !errors.Is(err, domain.ErrInvalidSnapshot) {
+				t.Fatalf("error = %v, want ErrInvalidSnapshot", err)
+			}
+			if value, err := storage.GetByID(ctx, 1); err != nil || value.Name != "kept" {
+				t.Errorf("storage changed after failed Restore: %+v, %v", value, err)
+			}
+		})
+	}
+}
+
+func TestInMemory_Count(t *testing.T) {
+	storage := NewCountingInMemory(func(value testData) bool { return value.Value > 0 })
+	ctx := context.Background()
+
+	check := func(step string, wantTotal, wantMatched int) {
+		t.Helper()
+		if total, matched := storage.Count(); total != wantTotal || matched != wantMatched {
+			t.Errorf("%s: Count() = %d, %d, want %d, %d", step, total, matched, wantTotal, wantMatched)
+		}
+	}
+
+	first, _ := storage.Save(ctx, testData{Name: "first", Value: 1}, 0, nil)
+	second, _ := storage.Save(ctx, testData{Name: "second"}, 0, nil)
+	storage.Insert(ctx, testData{Name: "third", Value: 1}, 10, nil)
+	check("save", 3, 2)
+
+	storage.Save(ctx, testData{Name: "first"}, first, nil)
+	storage.Save(ctx, testData{Name: "second", Value: 1}, second, nil)
+	check("update", 3, 2)
+
+	// неудачная запись счётчик не меняет
+	storage.Save(ctx, testData{Name: "first", Value: 1}, first, func(uint64) error { return errors.New("outbox is down") })
+	storage.Delete(ctx, 42, nil)
+	check("failed mutation", 3, 2)
+
+	storage.Delete(ctx, 10, nil)
+	check("delete", 2, 1)
+
+	storage.Restore(ctx, domain.Snapshot[testData]{SerialID: 4, Elems: []domain.Elem[testData]{
+		{ID: 1, Value: testData{Value: 1}},
+		{ID: 2, Value: testData{Value: 1}},
+		{ID: 3},
+	}})
+	check("restore", 3, 2)
+
+	if total, matched := NewInMemory[testData]().Count(); total != 0 || matched != 0 {
+		t.Errorf("Count() without match = %d, %d, want 0, 0", total, matched)
+	}
+}
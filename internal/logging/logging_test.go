@@ -0,0 +1,62 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestNew_JSONWithContextAttrs(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := New(&buf, slog.LevelInfo, "JSON")
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	ctx := WithAttrs(context.Background(), slog.String("request_id", "req-1"))
+	ctx = WithAttrs(ctx, slog.String("trace_id", "abc"))
+
+	logger.DebugContext(ctx, "hidden")
+	logger.With("component", "test").ErrorContext(ctx, "failed", Err(errors.New("boom")))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("got %d lines, want 1 (debug must be filtered): %q", len(lines), buf.String())
+	}
+
+	var record map[string]any
+	if err = json.Unmarshal([]byte(lines[0]), &record); err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+	want := map[string]any{
+		"level":      "ERROR",
+		"msg":        "failed",
+		"component":  "test",
+		"error":      "boom",
+		"request_id": "req-1",
+		"trace_id":   "abc",
+	}
+	for key, value := range want {
+		if record[key] != value {
+			t.Errorf("%s = %v, want %v", key, record[key], value)
+		}
+	}
+}
+
+func TestNew_Options(t *testing.T) {
+	if _, err := New(&bytes.Buffer{}, slog.LevelInfo, "xml"); err == nil {
+		t.Error("New with unknown format succeeded")
+	}
+
+	level, err := ParseLevel("warn")
+	if err != nil || level != slog.LevelWarn {
+		t.Errorf("ParseLevel(warn) = %v, %v", level, err)
+	}
+	if _, err = ParseLevel("loud"); err == nil {
+		t.Error("ParseLevel(loud) succeeded")
+	}
+}
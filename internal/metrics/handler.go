@@ -0,0 +1,51 @@
+package metrics
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/Ant-Tab-Shift/todos-service/internal/logging"
+	"github.com/Ant-Tab-Shift/todos-service/internal/transport/http/models"
+)
+
+const contentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// exposition описывает тело ответа для OpenAPI.
+type exposition string
+
+func (exposition) ContentType() string {
+	return contentType
+}
+
+type Handler struct {
+	registry *Registry
+}
+
+func NewHandler(registry *Registry) *Handler {
+	return &Handler{registry: registry}
+}
+
+func (h *Handler) Serve(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(http.StatusOK)
+
+	if _, err := h.registry.WriteTo(w); err != nil {
+		slog.WarnContext(r.Context(), "failed to write metrics", logging.Err(err))
+	}
+}
+
+func (h *Handler) Handlers() []models.Endpoint {
+	return []models.Endpoint{
+		{
+			Pattern: "GET /metrics",
+			Func:    h.Serve,
+			Doc: &models.Doc{
+				Summary: "Get metrics in Prometheus text format",
+				Tags:    []string{"meta"},
+				Responses: map[int]models.Response{
+					http.StatusOK: {Description: "Prometheus exposition format 0.0.4", Body: exposition("")},
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,86 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRegistry_WriteTo(t *testing.T) {
+	registry := NewRegistry()
+
+	requests := registry.NewCounterVec("http_requests_total", "Total requests.", "route", "status")
+	requests.Inc("GET /todos/{id}", "200")
+	requests.Inc("GET /todos/{id}", "200")
+	requests.Add(3, "POST /todos", "201")
+
+	latency := registry.NewHistogramVec("latency_seconds", "Latency.\nSecond line.", []float64{0.1, 1}, "route")
+	latency.Observe(0.05, "a")
+	latency.Observe(0.5, "a")
+	latency.Observe(5, "a")
+
+	registry.NewGaugeFunc("tasks", "Tasks by state.", []string{"state"}, func() []Sample {
+		return []Sample{{LabelValues: []string{`quote"back\slash`}, Value: 2}}
+	})
+
+	var sb strings.Builder
+	if _, err := registry.WriteTo(&sb); err != nil {
+		t.Fatalf("WriteTo failed: %v", err)
+	}
+
+	want := `# HELP http_requests_total Total requests.
+# TYPE http_requests_total counter
+http_requests_total{route="GET /todos/{id}",status="200"} 2
+http_requests_total{route="POST /todos",status="201"} 3
+# HELP latency_seconds Latency.\nSecond line.
+# TYPE latency_seconds histogram
+latency_seconds_bucket{route="a",le="0.1"} 1
+latency_seconds_bucket{route="a",le="1"} 2
+latency_seconds_bucket{route="a",le="+Inf"} 3
+latency_seconds_sum{route="a"} 5.55
+latency_seconds_count{route="a"} 3
+# HELP tasks Tasks by state.
+# TYPE tasks gauge
+tasks{state="quote\"back\\slash"} 2
+`
+	if got := sb.String(); got != want {
+		t.Errorf("exposition mismatch\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestRegistry_Misuse(t *testing.T) {
+	assertPanics := func(name string, fn func()) {
+		t.Helper()
+		defer func() {
+			if recover() == nil {
+				t.Errorf("%s did not panic", name)
+			}
+		}()
+		fn()
+	}
+
+	registry := NewRegistry()
+	counter := registry.NewCounterVec("c", "c", "label")
+
+	assertPanics("duplicate name", func() { registry.NewCounterVec("c", "c") })
+	assertPanics("wrong label count", func() { counter.Inc() })
+	assertPanics("negative add", func() { counter.Add(-1, "x") })
+}
+
+func TestHandler(t *testing.T) {
+	registry := NewRegistry()
+	RegisterRuntime(registry)
+
+	rec := httptest.NewRecorder()
+	NewHandler(registry).Serve(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if ct := rec.Header().Get("Content-Type"); ct != contentType {
+		t.Errorf("Content-Type = %q, want %q", ct, contentType)
+	}
+	for _, name := range []string{"go_goroutines ", "go_memstats_heap_alloc_bytes ", "go_gc_cycles_total "} {
+		if !strings.Contains(rec.Body.String(), "\n"+name) {
+			t.Errorf("metric %s missing from output", name)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package metrics
+
+import (
+	"runtime"
+	"time"
+)
+
+func RegisterRuntime(r *Registry) {
+	start := float64(time.Now().Unix())
+
+	r.NewGaugeFunc("go_goroutines", "Number of goroutines that currently exist.", nil, func() []Sample {
+		return []Sample{{Value: float64(runtime.NumGoroutine())}}
+	})
+	r.NewGaugeFunc("process_start_time_seconds", "Start time of the process since unix epoch in seconds.", nil, func() []Sample {
+		return []Sample{{Value: start}}
+	})
+
+	memStats := func(value func(*runtime.MemStats) float64) func() []Sample {
+		return func() []Sample {
+			var stats runtime.MemStats
+			runtime.ReadMemStats(&stats)
+			return []Sample{{Value: value(&stats)}}
+		}
+	}
+	r.NewGaugeFunc("go_memstats_heap_alloc_bytes", "Number of heap bytes allocated and still in use.", nil,
+		memStats(func(s *runtime.MemStats) float64 { return float64(s.HeapAlloc) }))
+	r.NewGaugeFunc("go_memstats_sys_bytes", "Number of bytes obtained from system.", nil,
+		memStats(func(s *runtime.MemStats) float64 { return float64(s.Sys) }))
+	r.NewCounterFunc("go_gc_cycles_total", "Number of completed GC cycles.", nil,
+		memStats(func(s *runtime.MemStats) float64 { return float64(s.NumGC) }))
+	r.NewCounterFunc("go_gc_pause_seconds_total", "Total time spent in GC stop-the-world pauses.", nil,
+		memStats(func(s *runtime.MemStats) float64 { return time.Duration(s.PauseTotalNs).Seconds() }))
+}
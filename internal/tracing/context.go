@@ -0,0 +1,118 @@
+package tracing
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+)
+
+type TraceID [16]byte
+
+func (t TraceID) String() string {
+	return hex.EncodeToString(t[:])
+}
+
+func (t TraceID) IsValid() bool {
+	return t != TraceID{}
+}
+
+type SpanID [8]byte
+
+func (s SpanID) String() string {
+	return hex.EncodeToString(s[:])
+}
+
+func (s SpanID) IsValid() bool {
+	return s != SpanID{}
+}
+
+type SpanContext struct {
+	TraceID    TraceID
+	SpanID     SpanID
+	Sampled    bool
+	TraceState string
+}
+
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID.IsValid() && sc.SpanID.IsValid()
+}
+
+// Traceparent форматирует заголовок по W3C Trace Context, версия 00.
+func (sc SpanContext) Traceparent() string {
+	flags := "00"
+	if sc.Sampled {
+		flags = "01"
+	}
+	return "00-" + sc.TraceID.String() + "-" + sc.SpanID.String() + "-" + flags
+}
+
+// ParseTraceparent принимает будущие версии формата, читая только известные
+// поля, но отвергает версию ff и нулевые идентификаторы, как требует спецификация.
+func ParseTraceparent(header string) (SpanContext, bool) {
+	header = strings.TrimSpace(header)
+	if len(header) < 55 {
+		return SpanContext{}, false
+	}
+
+	version, ok := decodeHex(header[0:2], 1)
+	if !ok || version[0] == 0xff {
+		return SpanContext{}, false
+	}
+	if version[0] == 0 && len(header) != 55 {
+		return SpanContext{}, false
+	}
+	if len(header) > 55 && header[55] != '-' {
+		return SpanContext{}, false
+	}
+	if header[2] != '-' || header[35] != '-' || header[52] != '-' {
+		return SpanContext{}, false
+	}
+
+	var sc SpanContext
+	traceID, ok := decodeHex(header[3:35], 16)
+	if !ok {
+		return SpanContext{}, false
+	}
+	spanID, ok := decodeHex(header[36:52], 8)
+	if !ok {
+		return SpanContext{}, false
+	}
+	flags, ok := decodeHex(header[53:55], 1)
+	if !ok {
+		return SpanContext{}, false
+	}
+
+	copy(sc.TraceID[:], traceID)
+	copy(sc.SpanID[:], spanID)
+	sc.Sampled = flags[0]&0x01 == 1
+
+	if !sc.IsValid() {
+		return SpanContext{}, false
+	}
+
+	return sc, true
+}
+
+func decodeHex(s string, size int) ([]byte, bool) {
+	if len(s) != size*2 || strings.ToLower(s) != s {
+		return nil, false
+	}
+	b, err := hex.DecodeString(s)
+	return b, err == nil
+}
+
+func newTraceID() TraceID {
+	var id TraceID
+	for !id.IsValid() {
+		_, _ = rand.Read(id[:])
+	}
+	return id
+}
+
+func newSpanID() SpanID {
+	var id SpanID
+	for !id.IsValid() {
+		_, _ = rand.Read(id[:])
+	}
+	return id
+}
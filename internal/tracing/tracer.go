@@ -0,0 +1,287 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Ant-Tab-Shift/todos-service/internal/logging"
+)
+
+const (
+	queueSize     = 2048
+	batchSize     = 256
+	flushInterval = 2 * time.Second
+)
+
+type Kind int
+
+// значения совпадают с SpanKind из OTLP
+const (
+	KindInternal Kind = 1
+	KindServer   Kind = 2
+	KindClient   Kind = 3
+)
+
+type StatusCode int
+
+const (
+	StatusUnset StatusCode = 0
+	StatusOK    StatusCode = 1
+	StatusError StatusCode = 2
+)
+
+type Attribute struct {
+	Key   string
+	Value any
+}
+
+type SpanData struct {
+	Name          string
+	Kind          Kind
+	TraceID       TraceID
+	SpanID        SpanID
+	ParentSpanID  SpanID
+	Start         time.Time
+	End           time.Time
+	Attributes    []Attribute
+	Status        StatusCode
+	StatusMessage string
+}
+
+type Exporter interface {
+	Export(ctx context.Context, spans []SpanData) error
+}
+
+type Tracer struct {
+	exporter Exporter
+	done     chan struct{}
+	dropped  atomic.Uint64
+
+	mu     sync.RWMutex
+	queue  chan SpanData
+	closed bool
+}
+
+// NewTracer без экспортёра только генерирует и распространяет идентификаторы.
+func NewTracer(exporter Exporter) *Tracer {
+	t := &Tracer{exporter: exporter, done: make(chan struct{})}
+	if exporter == nil {
+		close(t.done)
+		return t
+	}
+
+	t.queue = make(chan SpanData, queueSize)
+	go t.run()
+
+	return t
+}
+
+var defaultTracer atomic.Pointer[Tracer]
+
+func init() {
+	defaultTracer.Store(NewTracer(nil))
+}
+
+func SetDefault(t *Tracer) {
+	defaultTracer.Store(t)
+}
+
+func Default() *Tracer {
+	return defaultTracer.Load()
+}
+
+func Start(ctx context.Context, name string) (context.Context, *Span) {
+	return Default().Start(ctx, name, KindInternal)
+}
+
+func (t *Tracer) Start(ctx context.Context, name string, kind Kind) (context.Context, *Span) {
+	span := &Span{
+		tracer: t,
+		data: SpanData{
+			Name:   name,
+			Kind:   kind,
+			SpanID: newSpanID(),
+			Start:  time.Now(),
+		},
+	}
+
+	if parent, ok := spanContextFromContext(ctx); ok {
+		span.data.TraceID = parent.TraceID
+		span.data.ParentSpanID = parent.SpanID
+		span.sampled = parent.Sampled
+		span.traceState = parent.TraceState
+	} else {
+		span.data.TraceID = newTraceID()
+		span.sampled = true
+	}
+
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+func (t *Tracer) Dropped() uint64 {
+	return t.dropped.Load()
+}
+
+func (t *Tracer) export(data SpanData) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	if t.queue == nil {
+		return
+	}
+	if t.closed {
+		t.dropped.Add(1)
+		return
+	}
+
+	select {
+	case t.queue <- data:
+	default:
+		t.dropped.Add(1)
+	}
+}
+
+func (t *Tracer) run() {
+	defer close(t.done)
+
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]SpanData, 0, batchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		if err := t.exporter.Export(ctx, batch); err != nil {
+			slog.Warn("failed to export spans", "count", len(batch), logging.Err(err))
+		}
+		cancel()
+		batch = make([]SpanData, 0, batchSize)
+	}
+
+	for {
+		select {
+		case data, ok := <-t.queue:
+			if !ok {
+				flush()
+				return
+			}
+			batch = append(batch, data)
+			if len(batch) >= batchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+// Shutdown отправляет накопленные спаны и останавливает фоновую выгрузку.
+func (t *Tracer) Shutdown(ctx context.Context) error {
+	t.mu.Lock()
+	if t.queue != nil && !t.closed {
+		t.closed = true
+		close(t.queue)
+	}
+	t.mu.Unlock()
+
+	select {
+	case <-t.done:
+		return nil
+	case <-ctx.Done():
+		return errors.Join(errors.New("tracer shutdown interrupted"), ctx.Err())
+	}
+}
+
+type Span struct {
+	tracer     *Tracer
+	sampled    bool
+	traceState string
+
+	mu    sync.Mutex
+	data  SpanData
+	ended bool
+}
+
+func (s *Span) Context() SpanContext {
+	return SpanContext{
+		TraceID:    s.data.TraceID,
+		SpanID:     s.data.SpanID,
+		Sampled:    s.sampled,
+		TraceState: s.traceState,
+	}
+}
+
+func (s *Span) SetName(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.Name = name
+}
+
+func (s *Span) SetAttributes(attrs ...Attribute) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.Attributes = append(s.data.Attributes, attrs...)
+}
+
+func (s *Span) SetStatus(code StatusCode, message string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.Status = code
+	s.data.StatusMessage = message
+}
+
+func (s *Span) RecordError(err error) {
+	if err != nil {
+		s.SetStatus(StatusError, err.Error())
+	}
+}
+
+func (s *Span) End() {
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.data.End = time.Now()
+	data := s.data
+	s.mu.Unlock()
+
+	if s.sampled {
+		s.tracer.export(data)
+	}
+}
+
+// Finish - сокращение для defer с именованной ошибкой: func() { span.Finish(err) }.
+func (s *Span) Finish(err error) {
+	s.RecordError(err)
+	s.End()
+}
+
+type spanKey struct{}
+
+type remoteKey struct{}
+
+// ContextWithRemote делает контекст вызывающего сервиса родителем следующего спана.
+func ContextWithRemote(ctx context.Context, sc SpanContext) context.Context {
+	return context.WithValue(ctx, remoteKey{}, sc)
+}
+
+func SpanFromContext(ctx context.Context) *Span {
+	span, _ := ctx.Value(spanKey{}).(*Span)
+	return span
+}
+
+func spanContextFromContext(ctx context.Context) (SpanContext, bool) {
+	if span := SpanFromContext(ctx); span != nil {
+		return span.Context(), true
+	}
+	sc, ok := ctx.Value(remoteKey{}).(SpanContext)
+	return sc, ok && sc.IsValid()
+}
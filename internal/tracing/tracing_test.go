@@ -0,0 +1,242 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestParseTraceparent(t *testing.T) {
+	tests := []struct {
+		name        string
+		header      string
+		wantOK      bool
+		wantSampled bool
+	}{
+		{"valid sampled", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", true, true},
+		{"valid not sampled", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00", true, false},
+		{"future version with extra fields", "01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra", true, true},
+		{"version 00 with extra fields", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra", false, false},
+		{"forbidden version", "ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", false, false},
+		{"zero trace id", "00-00000000000000000000000000000000-00f067aa0ba902b7-01", false, false},
+		{"zero span id", "00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01", false, false},
+		{"uppercase hex", "00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01", false, false},
+		{"bad separator", "00_4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", false, false},
+		{"empty", "", false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sc, ok := ParseTraceparent(tt.header)
+			if ok != tt.wantOK {
+				t.Fatalf("ok = %v, want %v", ok, tt.wantOK)
+			}
+			if !ok {
+				return
+			}
+			if sc.Sampled != tt.wantSampled {
+				t.Errorf("Sampled = %v, want %v", sc.Sampled, tt.wantSampled)
+			}
+			// исходящий заголовок всегда использует поддерживаемую версию 00
+			if got, want := sc.Traceparent(), "00"+tt.header[2:55]; got != want {
+				t.Errorf("Traceparent() = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+type recordingExporter struct {
+	mu    sync.Mutex
+	spans []SpanData
+}
+
+func (e *recordingExporter) Export(ctx context.Context, spans []SpanData) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.spans = append(e.spans, spans...)
+	return nil
+}
+
+func TestTracer_ParentChild(t *testing.T) {
+	exporter := &recordingExporter{}
+	tracer := NewTracer(exporter)
+
+	remote, _ := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	ctx := ContextWithRemote(context.Background(), remote)
+
+	ctx, server := tracer.Start(ctx, "GET /todos", KindServer)
+	_, child := tracer.Start(ctx, "TaskService.GetAll", KindInternal)
+	child.Finish(errors.New("boom"))
+	server.End()
+	server.End()
+
+	if err := tracer.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+
+	if len(exporter.spans) != 2 {
+		t.Fatalf("exported %d spans, want 2", len(exporter.spans))
+	}
+	gotChild, gotServer := exporter.spans[0], exporter.spans[1]
+
+	if gotServer.TraceID != remote.TraceID || gotServer.ParentSpanID != remote.SpanID {
+		t.Errorf("server span not linked to remote parent: %+v", gotServer)
+	}
+	if gotChild.TraceID != remote.TraceID || gotChild.ParentSpanID != gotServer.SpanID {
+		t.Errorf("child span not linked to server span: %+v", gotChild)
+	}
+	if gotChild.Status != StatusError || gotChild.StatusMessage != "boom" {
+		t.Errorf("child status = %v %q, want error boom", gotChild.Status, gotChild.StatusMessage)
+	}
+}
+
+func TestTracer_RespectsSamplingAndShutdown(t *testing.T) {
+	exporter := &recordingExporter{}
+	tracer := NewTracer(exporter)
+
+	remote, _ := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00")
+	_, span := tracer.Start(ContextWithRemote(context.Background(), remote), "unsampled", KindServer)
+	span.End()
+
+	if err := tracer.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+	_, late := tracer.Start(context.Background(), "late", KindInternal)
+	late.End()
+
+	if len(exporter.spans) != 0 {
+		t.Errorf("exported %d spans, want none", len(exporter.spans))
+	}
+	if tracer.Dropped() != 1 {
+		t.Errorf("Dropped() = %d, want 1", tracer.Dropped())
+	}
+}
+
+func TestStdoutExporter(t *testing.T) {
+	var buf bytes.Buffer
+	start := time.Now()
+	span := SpanData{
+		Name:       "storage.Save",
+		Kind:       KindInternal,
+		TraceID:    TraceID{1},
+		SpanID:     SpanID{2},
+		Start:      start,
+		End:        start.Add(1500 * time.Microsecond),
+		Attributes: []Attribute{{Key: "task.id", Value: 7}},
+	}
+
+	if err := NewStdoutExporter(&buf).Export(context.Background(), []SpanData{span}); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+
+	var record map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+	if record["name"] != "storage.Save" || record["trace_id"] != span.TraceID.String() || record["duration_ms"] != 1.5 {
+		t.Errorf("record = %v", record)
+	}
+	if _, ok := record["parent_span_id"]; ok {
+		t.Error("root span must not have parent_span_id")
+	}
+}
+
+func TestOTLPExporter(t *testing.T) {
+	var (
+		gotPath        string
+		gotContentType string
+		gotBody        []byte
+	)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotContentType = r.Header.Get("Content-Type")
+		gotBody, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer receiver.Close()
+
+	start := time.Unix(1700000000, 0)
+	span := SpanData{
+		Name:         "GET /todos/{id}",
+		Kind:         KindServer,
+		TraceID:      TraceID{0xab},
+		SpanID:       SpanID{0xcd},
+		ParentSpanID: SpanID{0xef},
+		Start:        start,
+		End:          start.Add(time.Millisecond),
+		Attributes: []Attribute{
+			{Key: "http.response.status_code", Value: 404},
+			{Key: "http.route", Value: "GET /todos/{id}"},
+		},
+		Status: StatusError,
+	}
+
+	exporter := NewOTLPExporter(receiver.URL+"/v1/traces", "todos-test", receiver.Client())
+	if err := exporter.Export(context.Background(), []SpanData{span}); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+
+	if gotPath != "/v1/traces" || gotContentType != "application/json" {
+		t.Errorf("request = %s %s, want /v1/traces application/json", gotPath, gotContentType)
+	}
+
+	var payload struct {
+		ResourceSpans []struct {
+			Resource struct {
+				Attributes []otlpKeyValue `json:"attributes"`
+			} `json:"resource"`
+			ScopeSpans []struct {
+				Spans []struct {
+					TraceID           string         `json:"traceId"`
+					SpanID            string         `json:"spanId"`
+					ParentSpanID      string         `json:"parentSpanId"`
+					Kind              int            `json:"kind"`
+					StartTimeUnixNano string         `json:"startTimeUnixNano"`
+					Attributes        []otlpKeyValue `json:"attributes"`
+					Status            struct {
+						Code int `json:"code"`
+					} `json:"status"`
+				} `json:"spans"`
+			} `json:"scopeSpans"`
+		} `json:"resourceSpans"`
+	}
+	if err := json.Unmarshal(gotBody, &payload); err != nil {
+		t.Fatalf("decode payload failed: %v\n%s", err, gotBody)
+	}
+
+	resource := payload.ResourceSpans[0]
+	if resource.Resource.Attributes[0].Key != "service.name" || resource.Resource.Attributes[0].Value["stringValue"] != "todos-test" {
+		t.Errorf("resource attributes = %+v", resource.Resource.Attributes)
+	}
+
+	got := resource.ScopeSpans[0].Spans[0]
+	if got.TraceID != span.TraceID.String() || got.SpanID != span.SpanID.String() || got.ParentSpanID != span.ParentSpanID.String() {
+		t.Errorf("ids = %s/%s/%s", got.TraceID, got.SpanID, got.ParentSpanID)
+	}
+	if got.Kind != int(KindServer) || got.Status.Code != int(StatusError) || got.StartTimeUnixNano != "1700000000000000000" {
+		t.Errorf("span = %+v", got)
+	}
+	if got.Attributes[0].Value["intValue"] != "404" {
+		t.Errorf("int attribute = %+v, want string-encoded intValue", got.Attributes[0])
+	}
+}
+
+func TestOTLPExporter_ReportsCollectorErrors(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer receiver.Close()
+
+	err := NewOTLPExporter(receiver.URL, "todos-test", receiver.Client()).Export(context.Background(), []SpanData{{Name: "x"}})
+	if err == nil || !strings.Contains(err.Error(), "503") {
+		t.Errorf("error = %v, want 503", err)
+	}
+}
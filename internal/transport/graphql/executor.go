@@ -0,0 +1,646 @@
+package graphql
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Ant-Tab-Shift/todos-service/internal/domain"
+	"github.com/Ant-Tab-Shift/todos-service/internal/transport/http/handlers"
+	"github.com/Ant-Tab-Shift/todos-service/internal/validation"
+)
+
+type Request struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName,omitempty"`
+	Variables     map[string]any `json:"variables,omitempty"`
+}
+
+type Response struct {
+	Data   *Object  `json:"data,omitempty"`
+	Errors []*Error `json:"errors,omitempty"`
+}
+
+type Error struct {
+	Message string `json:"message"`
+	Path    []any  `json:"path,omitempty"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func errorf(format string, args ...any) error {
+	return &Error{Message: fmt.Sprintf(format, args...)}
+}
+
+type Object struct {
+	keys   []string
+	values []any
+}
+
+func (o *Object) Set(key string, value any) {
+	o.keys = append(o.keys, key)
+	o.values = append(o.values, value)
+}
+
+func (o *Object) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, key := range o.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		encodedKey, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		encodedValue, err := json.Marshal(o.values[i])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(encodedKey)
+		buf.WriteByte(':')
+		buf.Write(encodedValue)
+	}
+	buf.WriteByte('}')
+
+	return buf.Bytes(), nil
+}
+
+type Executor struct {
+	service handlers.TaskService
+}
+
+func NewExecutor(service handlers.TaskService) *Executor {
+	return &Executor{service: service}
+}
+
+func (e *Executor) Execute(ctx context.Context, req Request) Response {
+	doc, err := Parse(req.Query)
+	if err != nil {
+		return Response{Errors: []*Error{{Message: err.Error()}}}
+	}
+
+	op, err := selectOperation(doc, req.OperationName)
+	if err != nil {
+		return Response{Errors: []*Error{{Message: err.Error()}}}
+	}
+
+	vars, err := coerceVariables(op, req.Variables)
+	if err != nil {
+		return Response{Errors: []*Error{{Message: err.Error()}}}
+	}
+
+	ex := &execution{executor: e, vars: vars}
+	data := ex.executeRoot(ctx, op)
+
+	return Response{Data: data, Errors: ex.errors}
+}
+
+func selectOperation(doc *Document, name string) (*Operation, error) {
+	if name == "" {
+		if len(doc.Operations) > 1 {
+			return nil, errorf("operationName is required for documents with multiple operations")
+		}
+		return doc.Operations[0], nil
+	}
+
+	for _, op := range doc.Operations {
+		if op.Name == name {
+			return op, nil
+		}
+	}
+
+	return nil, errorf("unknown operation %q", name)
+}
+
+func coerceVariables(op *Operation, provided map[string]any) (map[string]any, error) {
+	vars := make(map[string]any, len(op.Variables))
+	for _, def := range op.Variables {
+		value, ok := provided[def.Name]
+		if !ok && def.DefaultValue.Kind != ValueNull {
+			value, _ = resolveValue(def.DefaultValue, nil)
+			ok = true
+		}
+		if def.NonNull && value == nil {
+			return nil, errorf("variable $%s of required type %s was not provided", def.Name, def.Type)
+		}
+		if ok {
+			vars[def.Name] = value
+		}
+	}
+
+	return vars, nil
+}
+
+type execution struct {
+	executor *Executor
+	vars     map[string]any
+	errors   []*Error
+}
+
+func (ex *execution) executeRoot(ctx context.Context, op *Operation) *Object {
+	typeName := "Query"
+	if op.Type == OperationMutation {
+		typeName = "Mutation"
+	}
+
+	data := &Object{}
+	for _, field := range op.SelectionSet {
+		path := []any{field.ResponseKey()}
+		if field.Name == "__typename" {
+			data.Set(field.ResponseKey(), typeName)
+			continue
+		}
+
+		args, err := ex.arguments(field)
+		if err != nil {
+			ex.fail(path, err)
+			data.Set(field.ResponseKey(), nil)
+			continue
+		}
+
+		var result any
+		if op.Type == OperationMutation {
+			result, err = ex.resolveMutation(ctx, field, args)
+		} else {
+			result, err = ex.resolveQuery(ctx, field, args)
+		}
+		if err != nil {
+			ex.fail(path, err)
+			data.Set(field.ResponseKey(), nil)
+			continue
+		}
+
+		data.Set(field.ResponseKey(), ex.complete(field, result, path))
+	}
+
+	return data
+}
+
+func (ex *execution) resolveQuery(ctx context.Context, field *Field, args map[string]any) (any, error) {
+	switch field.Name {
+	case "task":
+		if err := allowArguments(args, "id"); err != nil {
+			return nil, err
+		}
+		id, err := requiredID(args, "id")
+		if err != nil {
+			return nil, err
+		}
+
+		task, err := ex.executor.service.GetByID(ctx, id)
+		if errors.Is(err, domain.ErrNotExists) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		return &task, nil
+	case "tasks":
+		if err := allowArguments(args, "filter", "first", "after"); err != nil {
+			return nil, err
+		}
+		return ex.tasks(ctx, args)
+	default:
+		return nil, errorf("cannot query field %q on type Query", field.Name)
+	}
+}
+
+func (ex *execution) resolveMutation(ctx context.Context, field *Field, args map[string]any) (any, error) {
+	service := ex.executor.service
+
+	switch field.Name {
+	case "createTask":
+		if err := allowArguments(args, "title", "description", "priority"); err != nil {
+			return nil, err
+		}
+		title, ok, err := stringArg(args, "title")
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, errorf("argument \"title\" of type String! is required")
+		}
+		description, _, err := stringArg(args, "description")
+		if err != nil {
+			return nil, err
+		}
+
+		priority, _, err := priorityArg(args, "priority")
+		if err != nil {
+			return nil, err
+		}
+
+		task, err := service.Create(ctx, domain.TaskSchema{
+			Title:       title,
+			Description: description,
+			Priority:    priority,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		return &task, nil
+	case "updateTask":
+		if err := allowArguments(args, "id", "title", "description", "isDone", "priority"); err != nil {
+			return nil, err
+		}
+		id, err := requiredID(args, "id")
+		if err != nil {
+			return nil, err
+		}
+
+		task, err := service.GetByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if title, ok, err := stringArg(args, "title"); err != nil {
+			return nil, err
+		} else if ok {
+			task.Title = title
+		}
+		if description, ok, err := stringArg(args, "description"); err != nil {
+			return nil, err
+		} else if ok {
+			task.Description = description
+		}
+		if isDone, ok, err := boolArg(args, "isDone"); err != nil {
+			return nil, err
+		} else if ok {
+			task.IsDone = isDone
+		}
+		if priority, ok, err := priorityArg(args, "priority"); err != nil {
+			return nil, err
+		} else if ok {
+			task.Priority = priority
+		}
+
+		if err = service.Update(ctx, id, task.TaskSchema); err != nil {
+			return nil, err
+		}
+
+		return &task, nil
+	case "deleteTask":
+		if err := allowArguments(args, "id"); err != nil {
+			return nil, err
+		}
+		id, err := requiredID(args, "id")
+		if err != nil {
+			return nil, err
+		}
+
+		if err = service.Delete(ctx, id); err != nil {
+			return nil, err
+		}
+
+		return true, nil
+	default:
+		return nil, errorf("cannot query field %q on type Mutation", field.Name)
+	}
+}
+
+func (ex *execution) tasks(ctx context.Context, args map[string]any) (any, error) {
+	tasks, err := ex.executor.service.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	slices.SortFunc(tasks, func(a, b domain.Task) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	if raw, ok := args["filter"]; ok && raw != nil {
+		filter, ok := raw.(map[string]any)
+		if !ok {
+			return nil, errorf("argument \"filter\" must be a TaskFilter object")
+		}
+		if err = allowArguments(filter, "isDone", "titleContains"); err != nil {
+			return nil, err
+		}
+
+		isDone, filterDone, err := boolArg(filter, "isDone")
+		if err != nil {
+			return nil, err
+		}
+		contains, filterTitle, err := stringArg(filter, "titleContains")
+		if err != nil {
+			return nil, err
+		}
+
+		tasks = slices.DeleteFunc(tasks, func(task domain.Task) bool {
+			if filterDone && task.IsDone != isDone {
+				return true
+			}
+			return filterTitle && !strings.Contains(strings.ToLower(task.Title), strings.ToLower(contains))
+		})
+	}
+
+	after, hasAfter, err := idArg(args, "after")
+	if err != nil {
+		return nil, err
+	}
+	if hasAfter {
+		tasks = slices.DeleteFunc(tasks, func(task domain.Task) bool {
+			return task.ID <= after
+		})
+	}
+
+	first, hasFirst, err := intArg(args, "first")
+	if err != nil {
+		return nil, err
+	}
+	if hasFirst {
+		if first < 0 {
+			return nil, errorf("argument \"first\" must not be negative")
+		}
+		if int64(len(tasks)) > first {
+			tasks = tasks[:first]
+		}
+	}
+
+	return tasks, nil
+}
+
+func (ex *execution) complete(field *Field, value any, path []any) any {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case *domain.Task:
+		if v == nil {
+			return nil
+		}
+		return ex.completeTask(field, v, path)
+	case []domain.Task:
+		if len(field.SelectionSet) == 0 {
+			ex.fail(path, errorf("field %q of type [Task!]! must have a selection of subfields", field.Name))
+			return nil
+		}
+		list := make([]any, 0, len(v))
+		for i := range v {
+			list = append(list, ex.completeTask(field, &v[i], append(slices.Clone(path), i)))
+		}
+		return list
+	default:
+		if len(field.SelectionSet) > 0 {
+			ex.fail(path, errorf("field %q must not have a selection since it is a scalar", field.Name))
+			return nil
+		}
+		return v
+	}
+}
+
+func (ex *execution) completeTask(field *Field, task *domain.Task, path []any) any {
+	if len(field.SelectionSet) == 0 {
+		ex.fail(path, errorf("field %q of type Task must have a selection of subfields", field.Name))
+		return nil
+	}
+
+	object := &Object{}
+	for _, sub := range field.SelectionSet {
+		if len(sub.SelectionSet) > 0 {
+			ex.fail(append(slices.Clone(path), sub.ResponseKey()), errorf("field %q must not have a selection since it is a scalar", sub.Name))
+			object.Set(sub.ResponseKey(), nil)
+			continue
+		}
+
+		switch sub.Name {
+		case "__typename":
+			object.Set(sub.ResponseKey(), "Task")
+		case "id":
+			object.Set(sub.ResponseKey(), strconv.FormatUint(task.ID, 10))
+		case "title":
+			object.Set(sub.ResponseKey(), task.Title)
+		case "description":
+			object.Set(sub.ResponseKey(), task.Description)
+		case "isDone":
+			object.Set(sub.ResponseKey(), task.IsDone)
+		case "priority":
+			object.Set(sub.ResponseKey(), strings.ToUpper(string(task.Priority)))
+		default:
+			ex.fail(append(slices.Clone(path), sub.ResponseKey()), errorf("cannot query field %q on type Task", sub.Name))
+			object.Set(sub.ResponseKey(), nil)
+		}
+	}
+
+	return object
+}
+
+func (ex *execution) arguments(field *Field) (map[string]any, error) {
+	args := make(map[string]any, len(field.Arguments))
+	for _, arg := range field.Arguments {
+		if _, ok := args[arg.Name]; ok {
+			return nil, errorf("argument %q is specified more than once", arg.Name)
+		}
+
+		value, err := resolveValue(arg.Value, ex.vars)
+		if err != nil {
+			return nil, err
+		}
+		// неопределённая переменная равносильна отсутствующему аргументу
+		if arg.Value.Kind == ValueVariable {
+			if _, ok := ex.vars[arg.Value.Raw]; !ok {
+				continue
+			}
+		}
+		args[arg.Name] = value
+	}
+
+	return args, nil
+}
+
+func (ex *execution) fail(path []any, err error) {
+	var (
+		gqlErr  *Error
+		invalid validation.Errors
+		message string
+	)
+	switch {
+	case errors.As(err, &gqlErr):
+		message = gqlErr.Message
+	case errors.As(err, &invalid):
+		message = invalid.Error()
+	case errors.Is(err, domain.ErrNotExists):
+		message = "task not found"
+	case errors.Is(err, domain.ErrEmptyTitle):
+		message = domain.ErrEmptyTitle.Error()
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		message = "request cancelled"
+	default:
+		message = "internal server error"
+	}
+
+	ex.errors = append(ex.errors, &Error{Message: message, Path: path})
+}
+
+func resolveValue(value Value, vars map[string]any) (any, error) {
+	switch value.Kind {
+	case ValueNull:
+		return nil, nil
+	case ValueVariable:
+		if vars == nil {
+			return nil, errorf("variable $%s is not allowed here", value.Raw)
+		}
+		return vars[value.Raw], nil
+	case ValueInt:
+		n, err := strconv.ParseInt(value.Raw, 10, 64)
+		if err != nil {
+			return nil, errorf("invalid Int value %s", value.Raw)
+		}
+		return n, nil
+	case ValueFloat:
+		f, err := strconv.ParseFloat(value.Raw, 64)
+		if err != nil {
+			return nil, errorf("invalid Float value %s", value.Raw)
+		}
+		return f, nil
+	case ValueString, ValueEnum:
+		return value.Raw, nil
+	case ValueBoolean:
+		return value.Raw == "true", nil
+	case ValueList:
+		list := make([]any, 0, len(value.List))
+		for _, item := range value.List {
+			resolved, err := resolveValue(item, vars)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, resolved)
+		}
+		return list, nil
+	case ValueObject:
+		object := make(map[string]any, len(value.Fields))
+		for _, field := range value.Fields {
+			resolved, err := resolveValue(field.Value, vars)
+			if err != nil {
+				return nil, err
+			}
+			object[field.Name] = resolved
+		}
+		return object, nil
+	default:
+		return nil, errorf("unknown value kind")
+	}
+}
+
+func allowArguments(args map[string]any, allowed ...string) error {
+	for name := range args {
+		if !slices.Contains(allowed, name) {
+			return errorf("unknown argument %q", name)
+		}
+	}
+
+	return nil
+}
+
+func requiredID(args map[string]any, name string) (uint64, error) {
+	id, ok, err := idArg(args, name)
+	if err != nil {
+		return 0, err
+	}
+	if !ok {
+		return 0, errorf("argument %q of type ID! is required", name)
+	}
+
+	return id, nil
+}
+
+func idArg(args map[string]any, name string) (uint64, bool, error) {
+	raw, ok := args[name]
+	if !ok || raw == nil {
+		return 0, false, nil
+	}
+
+	switch v := raw.(type) {
+	case string:
+		id, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			return 0, false, errorf("argument %q is not a valid ID", name)
+		}
+		return id, true, nil
+	default:
+		n, ok := toInt(raw)
+		if !ok || n < 0 {
+			return 0, false, errorf("argument %q is not a valid ID", name)
+		}
+		return uint64(n), true, nil
+	}
+}
+
+func intArg(args map[string]any, name string) (int64, bool, error) {
+	raw, ok := args[name]
+	if !ok || raw == nil {
+		return 0, false, nil
+	}
+
+	n, ok := toInt(raw)
+	if !ok {
+		return 0, false, errorf("argument %q must be Int", name)
+	}
+
+	return n, true, nil
+}
+
+func stringArg(args map[string]any, name string) (string, bool, error) {
+	raw, ok := args[name]
+	if !ok || raw == nil {
+		return "", false, nil
+	}
+
+	s, ok := raw.(string)
+	if !ok {
+		return "", false, errorf("argument %q must be String", name)
+	}
+
+	return s, true, nil
+}
+
+func priorityArg(args map[string]any, name string) (domain.Priority, bool, error) {
+	raw, ok, err := stringArg(args, name)
+	if err != nil || !ok {
+		return "", false, err
+	}
+
+	priority := domain.Priority(strings.ToLower(raw))
+	if raw != strings.ToUpper(raw) || !priority.Valid() {
+		return "", false, errorf("argument %q must be one of LOW, NORMAL, HIGH", name)
+	}
+
+	return priority, true, nil
+}
+
+func boolArg(args map[string]any, name string) (bool, bool, error) {
+	raw, ok := args[name]
+	if !ok || raw == nil {
+		return false, false, nil
+	}
+
+	b, ok := raw.(bool)
+	if !ok {
+		return false, false, errorf("argument %q must be Boolean", name)
+	}
+
+	return b, true, nil
+}
+
+func toInt(raw any) (int64, bool) {
+	switch v := raw.(type) {
+	case int64:
+		return v, true
+	case float64:
+		if v != math.Trunc(v) || v > math.MaxInt64 || v < math.MinInt64 {
+			return 0, false
+		}
+		return int64(v), true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,329 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Ant-Tab-Shift/todos-service/internal/domain"
+)
+
+type mockTaskService struct {
+	tasks  map[uint64]domain.Task
+	nextID uint64
+	err    error
+}
+
+func newMockTaskService(tasks ...domain.Task) *mockTaskService {
+	m := &mockTaskService{tasks: make(map[uint64]domain.Task), nextID: 1}
+	for _, task := range tasks {
+		m.tasks[task.ID] = task
+		m.nextID = max(m.nextID, task.ID+1)
+	}
+	return m
+}
+
+func (m *mockTaskService) Create(ctx context.Context, schema domain.TaskSchema) (domain.Task, error) {
+	if schema.Title == "" {
+		return domain.Task{}, domain.ErrEmptyTitle
+	}
+	task := domain.Task{ID: m.nextID, TaskSchema: schema}
+	m.tasks[task.ID] = task
+	m.nextID++
+	return task, nil
+}
+
+func (m *mockTaskService) GetByID(ctx context.Context, id uint64) (domain.Task, error) {
+	task, ok := m.tasks[id]
+	if !ok {
+		return domain.Task{}, domain.ErrNotExists
+	}
+	return task, nil
+}
+
+func (m *mockTaskService) GetByIDWithRevision(ctx context.Context, id uint64) (domain.Task, domain.Revision, error) {
+	task, err := m.GetByID(ctx, id)
+	return task, domain.Revision{}, err
+}
+
+func (m *mockTaskService) Revision(ctx context.Context) (domain.Revision, error) {
+	return domain.Revision{}, nil
+}
+
+func (m *mockTaskService) Export(ctx context.Context, fn func(domain.Task) error) error {
+	return errors.New("not implemented")
+}
+
+func (m *mockTaskService) Import(ctx context.Context, task domain.Task, preserveID bool) (domain.Task, error) {
+	return domain.Task{}, errors.New("not implemented")
+}
+
+func (m *mockTaskService) DetachUnresolvedParents(ctx context.Context, ids []uint64) (map[uint64]error, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (m *mockTaskService) GetAll(ctx context.Context) ([]domain.Task, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	tasks := make([]domain.Task, 0, len(m.tasks))
+	for _, task := range m.tasks {
+		tasks = append(tasks, task)
+	}
+	return tasks, nil
+}
+
+func (m *mockTaskService) Update(ctx context.Context, id uint64, schema domain.TaskSchema, keep ...domain.TaskField) error {
+	if _, ok := m.tasks[id]; !ok {
+		return domain.ErrNotExists
+	}
+	if schema.Title == "" {
+		return domain.ErrEmptyTitle
+	}
+	m.tasks[id] = domain.Task{ID: id, TaskSchema: schema}
+	return nil
+}
+
+func (m *mockTaskService) Delete(ctx context.Context, id uint64) error {
+	if _, ok := m.tasks[id]; !ok {
+		return domain.ErrNotExists
+	}
+	delete(m.tasks, id)
+	return nil
+}
+
+func sampleTasks() []domain.Task {
+	return []domain.Task{
+		{ID: 1, TaskSchema: domain.TaskSchema{Title: "Buy milk", Description: "2L", IsDone: true}},
+		{ID: 2, TaskSchema: domain.TaskSchema{Title: "Write report"}},
+		{ID: 3, TaskSchema: domain.TaskSchema{Title: "Buy bread"}},
+		{ID: 4, TaskSchema: domain.TaskSchema{Title: "Call mom"}},
+	}
+}
+
+func execute(t *testing.T, service *mockTaskService, query string, vars map[string]any) (string, []*Error) {
+	t.Helper()
+
+	resp := NewExecutor(service).Execute(context.Background(), Request{Query: query, Variables: vars})
+	if resp.Data == nil {
+		return "", resp.Errors
+	}
+
+	data, err := json.Marshal(resp.Data)
+	if err != nil {
+		t.Fatalf("marshal data failed: %v", err)
+	}
+
+	return string(data), resp.Errors
+}
+
+func TestExecutor_TaskFieldSelection(t *testing.T) {
+	data, errs := execute(t, newMockTaskService(sampleTasks()...), `{ task(id: 1) { isDone title __typename } }`, nil)
+	if len(errs) != 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+
+	want := `{"task":{"isDone":true,"title":"Buy milk","__typename":"Task"}}`
+	if data != want {
+		t.Errorf("data = %s, want %s", data, want)
+	}
+}
+
+func TestExecutor_TaskNotFoundIsNull(t *testing.T) {
+	data, errs := execute(t, newMockTaskService(), `{ task(id: "42") { id } }`, nil)
+	if len(errs) != 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if data != `{"task":null}` {
+		t.Errorf("data = %s, want null task", data)
+	}
+}
+
+func TestExecutor_TasksFilterAndPagination(t *testing.T) {
+	service := newMockTaskService(sampleTasks()...)
+
+	tests := []struct {
+		name  string
+		query string
+		vars  map[string]any
+		want  string
+	}{
+		{
+			name:  "all sorted by id",
+			query: `{ tasks { id } }`,
+			want:  `{"tasks":[{"id":"1"},{"id":"2"},{"id":"3"},{"id":"4"}]}`,
+		},
+		{
+			name:  "filter by done and title",
+			query: `{ tasks(filter: {isDone: false, titleContains: "buy"}) { title } }`,
+			want:  `{"tasks":[{"title":"Buy bread"}]}`,
+		},
+		{
+			name:  "first and after",
+			query: `{ tasks(first: 2, after: "1") { id } }`,
+			want:  `{"tasks":[{"id":"2"},{"id":"3"}]}`,
+		},
+		{
+			name:  "variables with default",
+			query: `query Open($done: Boolean = false, $first: Int) { open: tasks(filter: {isDone: $done}, first: $first) { id } }`,
+			vars:  map[string]any{"first": float64(1)},
+			want:  `{"open":[{"id":"2"}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, errs := execute(t, service, tt.query, tt.vars)
+			if len(errs) != 0 {
+				t.Fatalf("unexpected errors: %v", errs)
+			}
+			if data != tt.want {
+				t.Errorf("data = %s, want %s", data, tt.want)
+			}
+		})
+	}
+}
+
+func TestExecutor_Mutations(t *testing.T) {
+	service := newMockTaskService(sampleTasks()...)
+
+	data, errs := execute(t, service, `
+		mutation Create($title: String!) {
+			created: createTask(title: $title, description: "d") { id title description isDone }
+		}`, map[string]any{"title": "New"})
+	if len(errs) != 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if want := `{"created":{"id":"5","title":"New","description":"d","isDone":false}}`; data != want {
+		t.Errorf("data = %s, want %s", data, want)
+	}
+
+	data, errs = execute(t, service, `mutation { updateTask(id: 2, isDone: true) { title isDone } }`, nil)
+	if len(errs) != 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if want := `{"updateTask":{"title":"Write report","isDone":true}}`; data != want {
+		t.Errorf("data = %s, want %s", data, want)
+	}
+
+	data, errs = execute(t, service, `mutation { deleteTask(id: 4) }`, nil)
+	if len(errs) != 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if data != `{"deleteTask":true}` {
+		t.Errorf("data = %s, want deleteTask true", data)
+	}
+	if _, ok := service.tasks[4]; ok {
+		t.Error("task 4 should be deleted")
+	}
+}
+
+func TestExecutor_FieldErrors(t *testing.T) {
+	service := newMockTaskService(sampleTasks()...)
+
+	tests := []struct {
+		name     string
+		query    string
+		wantData string
+		wantMsg  string
+		wantPath []any
+	}{
+		{
+			name:     "unknown task field",
+			query:    `{ task(id: 1) { id secret } }`,
+			wantData: `{"task":{"id":"1","secret":null}}`,
+			wantMsg:  `cannot query field "secret" on type Task`,
+			wantPath: []any{"task", "secret"},
+		},
+		{
+			name:     "missing selection",
+			query:    `{ task(id: 1) }`,
+			wantData: `{"task":null}`,
+			wantMsg:  `field "task" of type Task must have a selection of subfields`,
+			wantPath: []any{"task"},
+		},
+		{
+			name:     "domain validation error",
+			query:    `mutation { createTask(title: "") { id } }`,
+			wantData: `{"createTask":null}`,
+			wantMsg:  domain.ErrEmptyTitle.Error(),
+			wantPath: []any{"createTask"},
+		},
+		{
+			name:     "not found on update",
+			query:    `mutation { updateTask(id: 99, title: "x") { id } }`,
+			wantData: `{"updateTask":null}`,
+			wantMsg:  "task not found",
+			wantPath: []any{"updateTask"},
+		},
+		{
+			name:     "unknown argument",
+			query:    `{ task(id: 1, extra: 2) { id } }`,
+			wantData: `{"task":null}`,
+			wantMsg:  `unknown argument "extra"`,
+			wantPath: []any{"task"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, errs := execute(t, service, tt.query, nil)
+			if data != tt.wantData {
+				t.Errorf("data = %s, want %s", data, tt.wantData)
+			}
+			if len(errs) != 1 {
+				t.Fatalf("len(errors) = %d, want 1", len(errs))
+			}
+			if errs[0].Message != tt.wantMsg {
+				t.Errorf("message = %q, want %q", errs[0].Message, tt.wantMsg)
+			}
+			gotPath, _ := json.Marshal(errs[0].Path)
+			wantPath, _ := json.Marshal(tt.wantPath)
+			if string(gotPath) != string(wantPath) {
+				t.Errorf("path = %s, want %s", gotPath, wantPath)
+			}
+		})
+	}
+}
+
+func TestExecutor_RequestErrors(t *testing.T) {
+	service := newMockTaskService()
+
+	tests := []struct {
+		name  string
+		req   Request
+		wants string
+	}{
+		{"syntax", Request{Query: `{ tasks {`}, "syntax error"},
+		{"missing variable", Request{Query: `query ($id: ID!) { task(id: $id) { id } }`}, "variable $id of required type ID! was not provided"},
+		{"ambiguous operation", Request{Query: `query A { tasks { id } } query B { tasks { id } }`}, "operationName is required"},
+		{"unknown operation", Request{Query: `query A { tasks { id } }`, OperationName: "B"}, `unknown operation "B"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := NewExecutor(service).Execute(context.Background(), tt.req)
+			if resp.Data != nil {
+				t.Errorf("data should be absent, got %+v", resp.Data)
+			}
+			if len(resp.Errors) != 1 || !strings.Contains(resp.Errors[0].Message, tt.wants) {
+				t.Errorf("errors = %+v, want message containing %q", resp.Errors, tt.wants)
+			}
+		})
+	}
+}
+
+func TestExecutor_ServiceErrorDoesNotLeak(t *testing.T) {
+	service := newMockTaskService()
+	service.err = errors.New("connection refused to 10.0.0.1")
+
+	_, errs := execute(t, service, `{ tasks { id } }`, nil)
+	if len(errs) != 1 {
+		t.Fatalf("len(errors) = %d, want 1", len(errs))
+	}
+	if errs[0].Message != "internal server error" {
+		t.Errorf("message = %q, want internal server error", errs[0].Message)
+	}
+}
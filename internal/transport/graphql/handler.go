@@ -0,0 +1,62 @@
+package graphql
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/Ant-Tab-Shift/todos-service/internal/transport/http/handlers"
+	"github.com/Ant-Tab-Shift/todos-service/internal/transport/http/models"
+)
+
+const maxBodySize = 1 << 20
+
+type Handler struct {
+	executor *Executor
+}
+
+func NewHandler(service handlers.TaskService) *Handler {
+	return &Handler{executor: NewExecutor(service)}
+}
+
+func (h *Handler) Serve(w http.ResponseWriter, r *http.Request) {
+	var req Request
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize)).Decode(&req); err != nil {
+		writeJSON(w, Response{Errors: []*Error{{Message: "invalid request body"}}}, http.StatusBadRequest)
+		return
+	}
+
+	response := h.executor.Execute(r.Context(), req)
+
+	// без data запрос не дошёл до выполнения: синтаксическая ошибка или неверные переменные
+	status := http.StatusOK
+	if response.Data == nil {
+		status = http.StatusBadRequest
+	}
+
+	writeJSON(w, response, status)
+}
+
+func (h *Handler) Handlers() []models.Endpoint {
+	return []models.Endpoint{
+		{
+			Pattern: "POST /graphql",
+			Func:    h.Serve,
+			Doc: &models.Doc{
+				Summary: "Execute a GraphQL query or mutation",
+				Tags:    []string{"graphql"},
+				Request: Request{},
+				Responses: map[int]models.Response{
+					http.StatusOK:         {Description: "Execution result, possibly with field errors", Body: Response{}},
+					http.StatusBadRequest: {Description: "Invalid body, syntax or variables", Body: Response{}},
+				},
+			},
+		},
+	}
+}
+
+func writeJSON(w http.ResponseWriter, data any, status int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	_ = json.NewEncoder(w).Encode(data)
+}
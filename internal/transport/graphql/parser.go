@@ -0,0 +1,605 @@
+package graphql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+type OperationType string
+
+const (
+	OperationQuery    OperationType = "query"
+	OperationMutation OperationType = "mutation"
+)
+
+type Document struct {
+	Operations []*Operation
+}
+
+type Operation struct {
+	Type         OperationType
+	Name         string
+	Variables    []VariableDefinition
+	SelectionSet []*Field
+}
+
+type VariableDefinition struct {
+	Name         string
+	Type         string
+	NonNull      bool
+	DefaultValue Value
+}
+
+type Field struct {
+	Alias        string
+	Name         string
+	Arguments    []Argument
+	SelectionSet []*Field
+}
+
+func (f *Field) ResponseKey() string {
+	if f.Alias != "" {
+		return f.Alias
+	}
+
+	return f.Name
+}
+
+type Argument struct {
+	Name  string
+	Value Value
+}
+
+type ValueKind int
+
+const (
+	ValueNull ValueKind = iota
+	ValueVariable
+	ValueInt
+	ValueFloat
+	ValueString
+	ValueBoolean
+	ValueEnum
+	ValueList
+	ValueObject
+)
+
+type Value struct {
+	Kind   ValueKind
+	Raw    string
+	List   []Value
+	Fields []ObjectField
+}
+
+type ObjectField struct {
+	Name  string
+	Value Value
+}
+
+type SyntaxError struct {
+	Pos     int
+	Message string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("syntax error at position %d: %s", e.Pos, e.Message)
+}
+
+func Parse(source string) (*Document, error) {
+	p := &parser{lexer: lexer{src: source}}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	doc := &Document{}
+	for p.tok.kind != tokenEOF {
+		op, err := p.parseOperation()
+		if err != nil {
+			return nil, err
+		}
+		doc.Operations = append(doc.Operations, op)
+	}
+
+	if len(doc.Operations) == 0 {
+		return nil, &SyntaxError{Pos: 0, Message: "document contains no operations"}
+	}
+
+	return doc, nil
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenPunct
+	tokenName
+	tokenInt
+	tokenFloat
+	tokenString
+)
+
+type token struct {
+	kind  tokenKind
+	value string
+	pos   int
+}
+
+type lexer struct {
+	src string
+	pos int
+}
+
+func (l *lexer) next() (token, error) {
+	l.skipIgnored()
+	if l.pos >= len(l.src) {
+		return token{kind: tokenEOF, pos: l.pos}, nil
+	}
+
+	start := l.pos
+	c := l.src[l.pos]
+	switch {
+	case strings.IndexByte("!$():=@[]{}|", c) >= 0:
+		l.pos++
+		return token{kind: tokenPunct, value: string(c), pos: start}, nil
+	case c == '.':
+		if strings.HasPrefix(l.src[l.pos:], "...") {
+			l.pos += 3
+			return token{kind: tokenPunct, value: "...", pos: start}, nil
+		}
+		return token{}, &SyntaxError{Pos: start, Message: "unexpected '.'"}
+	case c == '_' || isLetter(c):
+		for l.pos < len(l.src) && (l.src[l.pos] == '_' || isLetter(l.src[l.pos]) || isDigit(l.src[l.pos])) {
+			l.pos++
+		}
+		return token{kind: tokenName, value: l.src[start:l.pos], pos: start}, nil
+	case c == '-' || isDigit(c):
+		return l.number()
+	case c == '"':
+		return l.string()
+	default:
+		return token{}, &SyntaxError{Pos: start, Message: fmt.Sprintf("unexpected character %q", c)}
+	}
+}
+
+func (l *lexer) skipIgnored() {
+	for l.pos < len(l.src) {
+		switch c := l.src[l.pos]; {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == ',':
+			l.pos++
+		case c == '#':
+			for l.pos < len(l.src) && l.src[l.pos] != '\n' {
+				l.pos++
+			}
+		case strings.HasPrefix(l.src[l.pos:], "\uFEFF"):
+			l.pos += len("\uFEFF")
+		default:
+			return
+		}
+	}
+}
+
+func (l *lexer) number() (token, error) {
+	start := l.pos
+	if l.src[l.pos] == '-' {
+		l.pos++
+	}
+	digits := l.pos
+	for l.pos < len(l.src) && isDigit(l.src[l.pos]) {
+		l.pos++
+	}
+	if l.pos == digits {
+		return token{}, &SyntaxError{Pos: start, Message: "invalid number"}
+	}
+
+	kind := tokenInt
+	if l.pos < len(l.src) && l.src[l.pos] == '.' {
+		kind = tokenFloat
+		l.pos++
+		for l.pos < len(l.src) && isDigit(l.src[l.pos]) {
+			l.pos++
+		}
+	}
+	if l.pos < len(l.src) && (l.src[l.pos] == 'e' || l.src[l.pos] == 'E') {
+		kind = tokenFloat
+		l.pos++
+		if l.pos < len(l.src) && (l.src[l.pos] == '+' || l.src[l.pos] == '-') {
+			l.pos++
+		}
+		for l.pos < len(l.src) && isDigit(l.src[l.pos]) {
+			l.pos++
+		}
+	}
+
+	return token{kind: kind, value: l.src[start:l.pos], pos: start}, nil
+}
+
+func (l *lexer) string() (token, error) {
+	start := l.pos
+	l.pos++
+
+	var sb strings.Builder
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		switch {
+		case c == '"':
+			l.pos++
+			return token{kind: tokenString, value: sb.String(), pos: start}, nil
+		case c == '\n' || c == '\r':
+			return token{}, &SyntaxError{Pos: l.pos, Message: "unterminated string"}
+		case c == '\\':
+			if l.pos+1 >= len(l.src) {
+				return token{}, &SyntaxError{Pos: l.pos, Message: "unterminated string"}
+			}
+			escaped := l.src[l.pos+1]
+			l.pos += 2
+			switch escaped {
+			case '"', '\\', '/':
+				sb.WriteByte(escaped)
+			case 'b':
+				sb.WriteByte('\b')
+			case 'f':
+				sb.WriteByte('\f')
+			case 'n':
+				sb.WriteByte('\n')
+			case 'r':
+				sb.WriteByte('\r')
+			case 't':
+				sb.WriteByte('\t')
+			case 'u':
+				if l.pos+4 > len(l.src) {
+					return token{}, &SyntaxError{Pos: l.pos, Message: "invalid unicode escape"}
+				}
+				code, err := strconv.ParseUint(l.src[l.pos:l.pos+4], 16, 32)
+				if err != nil {
+					return token{}, &SyntaxError{Pos: l.pos, Message: "invalid unicode escape"}
+				}
+				sb.WriteRune(rune(code))
+				l.pos += 4
+			default:
+				return token{}, &SyntaxError{Pos: l.pos - 1, Message: fmt.Sprintf("invalid escape '\\%c'", escaped)}
+			}
+		default:
+			r, size := utf8.DecodeRuneInString(l.src[l.pos:])
+			sb.WriteRune(r)
+			l.pos += size
+		}
+	}
+
+	return token{}, &SyntaxError{Pos: start, Message: "unterminated string"}
+}
+
+type parser struct {
+	lexer lexer
+	tok   token
+}
+
+func (p *parser) advance() error {
+	tok, err := p.lexer.next()
+	if err != nil {
+		return err
+	}
+	p.tok = tok
+
+	return nil
+}
+
+func (p *parser) peek(value string) bool {
+	return p.tok.kind == tokenPunct && p.tok.value == value
+}
+
+func (p *parser) expect(value string) error {
+	if !p.peek(value) {
+		return p.unexpected(fmt.Sprintf("expected %q", value))
+	}
+
+	return p.advance()
+}
+
+func (p *parser) expectName() (string, error) {
+	if p.tok.kind != tokenName {
+		return "", p.unexpected("expected name")
+	}
+	name := p.tok.value
+
+	return name, p.advance()
+}
+
+func (p *parser) unexpected(message string) error {
+	found := p.tok.value
+	if p.tok.kind == tokenEOF {
+		found = "<EOF>"
+	}
+
+	return &SyntaxError{Pos: p.tok.pos, Message: fmt.Sprintf("%s, found %q", message, found)}
+}
+
+func (p *parser) parseOperation() (*Operation, error) {
+	op := &Operation{Type: OperationQuery}
+
+	if p.peek("{") {
+		selection, err := p.parseSelectionSet()
+		if err != nil {
+			return nil, err
+		}
+		op.SelectionSet = selection
+
+		return op, nil
+	}
+
+	if p.tok.kind != tokenName {
+		return nil, p.unexpected("expected operation")
+	}
+	switch p.tok.value {
+	case "query":
+		op.Type = OperationQuery
+	case "mutation":
+		op.Type = OperationMutation
+	default:
+		return nil, p.unexpected("unsupported operation type")
+	}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	if p.tok.kind == tokenName {
+		op.Name = p.tok.value
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.peek("(") {
+		vars, err := p.parseVariableDefinitions()
+		if err != nil {
+			return nil, err
+		}
+		op.Variables = vars
+	}
+
+	selection, err := p.parseSelectionSet()
+	if err != nil {
+		return nil, err
+	}
+	op.SelectionSet = selection
+
+	return op, nil
+}
+
+func (p *parser) parseVariableDefinitions() ([]VariableDefinition, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+
+	var defs []VariableDefinition
+	for !p.peek(")") {
+		if err := p.expect("$"); err != nil {
+			return nil, err
+		}
+		name, err := p.expectName()
+		if err != nil {
+			return nil, err
+		}
+		if err = p.expect(":"); err != nil {
+			return nil, err
+		}
+
+		typ, err := p.parseType()
+		if err != nil {
+			return nil, err
+		}
+		def := VariableDefinition{
+			Name:    name,
+			Type:    typ,
+			NonNull: strings.HasSuffix(typ, "!"),
+		}
+
+		if p.peek("=") {
+			if err = p.advance(); err != nil {
+				return nil, err
+			}
+			if def.DefaultValue, err = p.parseValue(true); err != nil {
+				return nil, err
+			}
+		}
+
+		defs = append(defs, def)
+	}
+
+	return defs, p.advance()
+}
+
+func (p *parser) parseType() (string, error) {
+	var typ string
+	if p.peek("[") {
+		if err := p.advance(); err != nil {
+			return "", err
+		}
+		inner, err := p.parseType()
+		if err != nil {
+			return "", err
+		}
+		if err = p.expect("]"); err != nil {
+			return "", err
+		}
+		typ = "[" + inner + "]"
+	} else {
+		name, err := p.expectName()
+		if err != nil {
+			return "", err
+		}
+		typ = name
+	}
+
+	if p.peek("!") {
+		typ += "!"
+		if err := p.advance(); err != nil {
+			return "", err
+		}
+	}
+
+	return typ, nil
+}
+
+func (p *parser) parseSelectionSet() ([]*Field, error) {
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+
+	var fields []*Field
+	for !p.peek("}") {
+		if p.peek("...") {
+			return nil, p.unexpected("fragments are not supported")
+		}
+
+		field, err := p.parseField()
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, field)
+	}
+
+	if len(fields) == 0 {
+		return nil, p.unexpected("selection set must not be empty")
+	}
+
+	return fields, p.advance()
+}
+
+func (p *parser) parseField() (*Field, error) {
+	name, err := p.expectName()
+	if err != nil {
+		return nil, err
+	}
+
+	field := &Field{Name: name}
+	if p.peek(":") {
+		if err = p.advance(); err != nil {
+			return nil, err
+		}
+		field.Alias = name
+		if field.Name, err = p.expectName(); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.peek("(") {
+		if field.Arguments, err = p.parseArguments(); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.peek("@") {
+		return nil, p.unexpected("directives are not supported")
+	}
+
+	if p.peek("{") {
+		if field.SelectionSet, err = p.parseSelectionSet(); err != nil {
+			return nil, err
+		}
+	}
+
+	return field, nil
+}
+
+func (p *parser) parseArguments() ([]Argument, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+
+	var args []Argument
+	for !p.peek(")") {
+		name, err := p.expectName()
+		if err != nil {
+			return nil, err
+		}
+		if err = p.expect(":"); err != nil {
+			return nil, err
+		}
+		value, err := p.parseValue(false)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, Argument{Name: name, Value: value})
+	}
+
+	return args, p.advance()
+}
+
+func (p *parser) parseValue(constant bool) (Value, error) {
+	tok := p.tok
+
+	switch {
+	case p.peek("$"):
+		if constant {
+			return Value{}, p.unexpected("variables are not allowed here")
+		}
+		if err := p.advance(); err != nil {
+			return Value{}, err
+		}
+		name, err := p.expectName()
+		if err != nil {
+			return Value{}, err
+		}
+		return Value{Kind: ValueVariable, Raw: name}, nil
+	case p.peek("["):
+		if err := p.advance(); err != nil {
+			return Value{}, err
+		}
+		list := Value{Kind: ValueList}
+		for !p.peek("]") {
+			item, err := p.parseValue(constant)
+			if err != nil {
+				return Value{}, err
+			}
+			list.List = append(list.List, item)
+		}
+		return list, p.advance()
+	case p.peek("{"):
+		if err := p.advance(); err != nil {
+			return Value{}, err
+		}
+		object := Value{Kind: ValueObject}
+		for !p.peek("}") {
+			name, err := p.expectName()
+			if err != nil {
+				return Value{}, err
+			}
+			if err = p.expect(":"); err != nil {
+				return Value{}, err
+			}
+			value, err := p.parseValue(constant)
+			if err != nil {
+				return Value{}, err
+			}
+			object.Fields = append(object.Fields, ObjectField{Name: name, Value: value})
+		}
+		return object, p.advance()
+	case tok.kind == tokenInt:
+		return Value{Kind: ValueInt, Raw: tok.value}, p.advance()
+	case tok.kind == tokenFloat:
+		return Value{Kind: ValueFloat, Raw: tok.value}, p.advance()
+	case tok.kind == tokenString:
+		return Value{Kind: ValueString, Raw: tok.value}, p.advance()
+	case tok.kind == tokenName:
+		switch tok.value {
+		case "true", "false":
+			return Value{Kind: ValueBoolean, Raw: tok.value}, p.advance()
+		case "null":
+			return Value{Kind: ValueNull}, p.advance()
+		default:
+			return Value{Kind: ValueEnum, Raw: tok.value}, p.advance()
+		}
+	default:
+		return Value{}, p.unexpected("expected value")
+	}
+}
+
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
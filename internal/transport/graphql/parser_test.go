@@ -0,0 +1,138 @@
+package graphql
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParse_ShorthandQuery(t *testing.T) {
+	doc, err := Parse(`{ tasks { id title } }`)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	if len(doc.Operations) != 1 {
+		t.Fatalf("len(operations) = %d, want 1", len(doc.Operations))
+	}
+	op := doc.Operations[0]
+	if op.Type != OperationQuery {
+		t.Errorf("op.Type = %s, want query", op.Type)
+	}
+	if len(op.SelectionSet) != 1 || op.SelectionSet[0].Name != "tasks" {
+		t.Fatalf("selection = %+v, want single tasks field", op.SelectionSet)
+	}
+	sub := op.SelectionSet[0].SelectionSet
+	if len(sub) != 2 || sub[0].Name != "id" || sub[1].Name != "title" {
+		t.Errorf("sub selection = %+v, want id and title", sub)
+	}
+}
+
+func TestParse_NamedOperationWithVariables(t *testing.T) {
+	source := `
+		# комментарии и запятые игнорируются
+		query GetTasks($done: Boolean = false, $first: Int!, $ids: [ID!]) {
+			open: tasks(filter: {isDone: $done, titleContains: "a\"b"}, first: $first) {
+				id,
+			}
+		}
+	`
+	doc, err := Parse(source)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	op := doc.Operations[0]
+	if op.Name != "GetTasks" {
+		t.Errorf("op.Name = %q, want GetTasks", op.Name)
+	}
+
+	wantVars := []VariableDefinition{
+		{Name: "done", Type: "Boolean", DefaultValue: Value{Kind: ValueBoolean, Raw: "false"}},
+		{Name: "first", Type: "Int!", NonNull: true},
+		{Name: "ids", Type: "[ID!]"},
+	}
+	if len(op.Variables) != len(wantVars) {
+		t.Fatalf("len(variables) = %d, want %d", len(op.Variables), len(wantVars))
+	}
+	for i, want := range wantVars {
+		got := op.Variables[i]
+		if got.Name != want.Name || got.Type != want.Type || got.NonNull != want.NonNull || got.DefaultValue.Kind != want.DefaultValue.Kind {
+			t.Errorf("variables[%d] = %+v, want %+v", i, got, want)
+		}
+	}
+
+	field := op.SelectionSet[0]
+	if field.Alias != "open" || field.Name != "tasks" || field.ResponseKey() != "open" {
+		t.Errorf("field = %+v, want alias open for tasks", field)
+	}
+	if len(field.Arguments) != 2 {
+		t.Fatalf("len(arguments) = %d, want 2", len(field.Arguments))
+	}
+
+	filter := field.Arguments[0].Value
+	if filter.Kind != ValueObject || len(filter.Fields) != 2 {
+		t.Fatalf("filter = %+v, want object with 2 fields", filter)
+	}
+	if filter.Fields[0].Value.Kind != ValueVariable || filter.Fields[0].Value.Raw != "done" {
+		t.Errorf("filter.isDone = %+v, want $done", filter.Fields[0].Value)
+	}
+	if filter.Fields[1].Value.Raw != `a"b` {
+		t.Errorf("filter.titleContains = %q, want a\"b", filter.Fields[1].Value.Raw)
+	}
+}
+
+func TestParse_Mutation(t *testing.T) {
+	doc, err := Parse(`mutation { createTask(title: "New", description: "Line\nBreak A") { id } }`)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	op := doc.Operations[0]
+	if op.Type != OperationMutation {
+		t.Errorf("op.Type = %s, want mutation", op.Type)
+	}
+	args := op.SelectionSet[0].Arguments
+	if args[1].Value.Raw != "Line\nBreak A" {
+		t.Errorf("description = %q, want escaped string", args[1].Value.Raw)
+	}
+}
+
+func TestParse_Values(t *testing.T) {
+	doc, err := Parse(`{ f(a: -12, b: 1.5e3, c: null, d: ENUM, e: [1, "two", true]) }`)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	args := doc.Operations[0].SelectionSet[0].Arguments
+	want := []ValueKind{ValueInt, ValueFloat, ValueNull, ValueEnum, ValueList}
+	for i, kind := range want {
+		if args[i].Value.Kind != kind {
+			t.Errorf("args[%d].Kind = %d, want %d", i, args[i].Value.Kind, kind)
+		}
+	}
+	if len(args[4].Value.List) != 3 {
+		t.Errorf("list length = %d, want 3", len(args[4].Value.List))
+	}
+}
+
+func TestParse_SyntaxErrors(t *testing.T) {
+	tests := []string{
+		``,
+		`{ tasks { id }`,
+		`{ }`,
+		`subscription { tasks { id } }`,
+		`{ task(id: ) { id } }`,
+		`{ tasks { ...TaskFields } }`,
+		`{ task(id: "unterminated) { id } }`,
+		`query ($id: ID! = $other) { task(id: $id) { id } }`,
+		`{ tasks ? }`,
+	}
+
+	for _, source := range tests {
+		_, err := Parse(source)
+		var syntaxErr *SyntaxError
+		if !errors.As(err, &syntaxErr) {
+			t.Errorf("Parse(%q) error = %v, want SyntaxError", source, err)
+		}
+	}
+}
@@ -1,21 +1,41 @@
 package dto
 
+import (
+	"time"
+
+	"github.com/Ant-Tab-Shift/todos-service/internal/transport/http/problem"
+)
+
 type CreateTaskRequest struct {
-	Title       string `json:"title"`
-	Description string `json:"description"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	Priority    string     `json:"priority,omitempty"`
+	DueDate     *time.Time `json:"due_date,omitempty"`
+	Tags        []string   `json:"tags,omitempty"`
+	ParentID    uint64     `json:"parent_id,omitempty"`
 }
 
+// UpdateTaskRequest — новое состояние задачи. Отсутствующие priority, due_date, tags
+// и parent_id остаются прежними; null, пустой список и 0 их снимают.
 type UpdateTaskRequest struct {
-	Title       string `json:"title"`
-	Description string `json:"description"`
-	IsDone      bool   `json:"is_done"`
+	Title       string               `json:"title"`
+	Description string               `json:"description"`
+	IsDone      bool                 `json:"is_done"`
+	Priority    string               `json:"priority,omitempty"`
+	DueDate     Optional[*time.Time] `json:"due_date,omitempty"`
+	Tags        Optional[[]string]   `json:"tags,omitempty"`
+	ParentID    Optional[uint64]     `json:"parent_id,omitempty"`
 }
 
 type TaskResponse struct {
-	ID          uint64 `json:"id"`
-	Title       string `json:"title"`
-	Description string `json:"description"`
-	IsDone      bool   `json:"is_done"`
+	ID          uint64     `json:"id"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	IsDone      bool       `json:"is_done"`
+	Priority    string     `json:"priority"`
+	DueDate     *time.Time `json:"due_date,omitempty"`
+	Tags        []string   `json:"tags,omitempty"`
+	ParentID    uint64     `json:"parent_id,omitempty"`
 }
 
 type TaskListResponse struct {
@@ -23,6 +43,55 @@ type TaskListResponse struct {
 	Total int            `json:"total"`
 }
 
-type ErrorResponse struct {
-	Error string `json:"error"`
+// TaskLine — строка выгрузки задач в формате NDJSON.
+type TaskLine TaskResponse
+
+func (TaskLine) ContentType() string {
+	return "application/x-ndjson"
+}
+
+// ImportTaskRequest — строка импорта; формат совпадает со строкой выгрузки.
+type ImportTaskRequest struct {
+	ID          uint64     `json:"id"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	IsDone      bool       `json:"is_done"`
+	Priority    string     `json:"priority,omitempty"`
+	DueDate     *time.Time `json:"due_date,omitempty"`
+	Tags        []string   `json:"tags,omitempty"`
+	ParentID    uint64     `json:"parent_id,omitempty"`
+}
+
+func (ImportTaskRequest) ContentType() string {
+	return "application/x-ndjson"
+}
+
+type ImportLineError struct {
+	Line   int                  `json:"line"`
+	Type   string               `json:"type"`
+	Title  string               `json:"title"`
+	Status int                  `json:"status"`
+	Detail string               `json:"detail,omitempty"`
+	Errors []problem.FieldError `json:"errors,omitempty"`
+}
+
+type ImportResponse struct {
+	Imported int `json:"imported"`
+	Failed   int `json:"failed"`
+	// Detached — импортированные задачи, чей родитель не нашёлся; они тоже есть в Errors
+	Detached int               `json:"detached,omitempty"`
+	Errors   []ImportLineError `json:"errors"`
+}
+
+// BackupArchive — архив хранилища, который отдаёт POST /admin/backup и принимает POST /admin/restore.
+type BackupArchive string
+
+func (BackupArchive) ContentType() string {
+	return "application/gzip"
+}
+
+type RestoreResponse struct {
+	Tasks     int       `json:"tasks"`
+	SerialID  uint64    `json:"serial_id"`
+	CreatedAt time.Time `json:"created_at"`
 }
@@ -1,6 +1,10 @@
 package dto
 
-import "github.com/Ant-Tab-Shift/todos-service/internal/domain"
+import (
+	"time"
+
+	"github.com/Ant-Tab-Shift/todos-service/internal/domain"
+)
 
 func ToTaskResponse(task *domain.Task) TaskResponse {
 	if task == nil {
@@ -12,9 +16,50 @@ func ToTaskResponse(task *domain.Task) TaskResponse {
 		Title:       task.Title,
 		Description: task.Description,
 		IsDone:      task.IsDone,
+		Priority:    string(task.Priority),
+		DueDate:     toDueDate(task.DueDate),
+		Tags:        task.Tags,
+		ParentID:    task.ParentID,
+	}
+}
+
+func FromCreateTaskRequest(req CreateTaskRequest) domain.TaskSchema {
+	return domain.TaskSchema{
+		Title:       req.Title,
+		Description: req.Description,
+		Priority:    domain.Priority(req.Priority),
+		DueDate:     fromDueDate(req.DueDate),
+		Tags:        req.Tags,
+		ParentID:    req.ParentID,
 	}
 }
 
+// FromUpdateTaskRequest возвращает новое состояние задачи и поля, которые в запросе не переданы.
+func FromUpdateTaskRequest(req UpdateTaskRequest) (domain.TaskSchema, []domain.TaskField) {
+	task := domain.TaskSchema{
+		Title:       req.Title,
+		Description: req.Description,
+		IsDone:      req.IsDone,
+		Priority:    domain.Priority(req.Priority),
+		DueDate:     fromDueDate(req.DueDate.Value),
+		Tags:        req.Tags.Value,
+		ParentID:    req.ParentID.Value,
+	}
+
+	var keep []domain.TaskField
+	if !req.DueDate.Set {
+		keep = append(keep, domain.FieldDueDate)
+	}
+	if !req.Tags.Set {
+		keep = append(keep, domain.FieldTags)
+	}
+	if !req.ParentID.Set {
+		keep = append(keep, domain.FieldParentID)
+	}
+
+	return task, keep
+}
+
 func ToTaskListResponse(tasks []domain.Task) TaskListResponse {
 	if tasks == nil {
 		return TaskListResponse{
@@ -33,3 +78,32 @@ func ToTaskListResponse(tasks []domain.Task) TaskListResponse {
 		Total: len(responses),
 	}
 }
+
+func FromImportTaskRequest(req ImportTaskRequest) domain.Task {
+	return domain.Task{
+		ID: req.ID,
+		TaskSchema: domain.TaskSchema{
+			Title:       req.Title,
+			Description: req.Description,
+			IsDone:      req.IsDone,
+			Priority:    domain.Priority(req.Priority),
+			DueDate:     fromDueDate(req.DueDate),
+			Tags:        req.Tags,
+			ParentID:    req.ParentID,
+		},
+	}
+}
+
+func toDueDate(due time.Time) *time.Time {
+	if due.IsZero() {
+		return nil
+	}
+	return &due
+}
+
+func fromDueDate(due *time.Time) time.Time {
+	if due == nil {
+		return time.Time{}
+	}
+	return *due
+}
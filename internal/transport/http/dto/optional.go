@@ -0,0 +1,30 @@
+package dto
+
+import (
+	"encoding/json"
+	"reflect"
+)
+
+// Optional — поле запроса, которое можно не передавать: Set отличает
+// отсутствующее поле от переданного, в том числе от null.
+type Optional[T any] struct {
+	Value T
+	Set   bool
+}
+
+func (o *Optional[T]) UnmarshalJSON(data []byte) error {
+	var value T
+	if string(data) != "null" {
+		if err := json.Unmarshal(data, &value); err != nil {
+			return err
+		}
+	}
+	o.Value, o.Set = value, true
+
+	return nil
+}
+
+// SchemaType — тип, которым поле описывается в OpenAPI: значение или null.
+func (Optional[T]) SchemaType() reflect.Type {
+	return reflect.TypeFor[*T]()
+}
@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/Ant-Tab-Shift/todos-service/internal/domain"
+	"github.com/Ant-Tab-Shift/todos-service/internal/infrastructure/backup"
+	"github.com/Ant-Tab-Shift/todos-service/internal/logging"
+	"github.com/Ant-Tab-Shift/todos-service/internal/transport/http/dto"
+	"github.com/Ant-Tab-Shift/todos-service/internal/transport/http/models"
+	"github.com/Ant-Tab-Shift/todos-service/internal/transport/http/problem"
+)
+
+// maxRestoreSize ограничивает сжатый архив; распакованный ограничен backup.MaxSize.
+const maxRestoreSize = 64 << 20
+
+// BackupStore — хранилище, которое умеет отдать согласованный снимок и целиком заменить содержимое.
+type BackupStore interface {
+	Snapshot(ctx context.Context) (domain.Snapshot[domain.TaskSchema], error)
+	Restore(ctx context.Context, snapshot domain.Snapshot[domain.TaskSchema]) error
+}
+
+// AdminHandler обслуживает /admin/*; доступ к этим путям проверяет сервер.
+type AdminHandler struct {
+	store    BackupStore
+	problems *problem.Registry
+	now      func() time.Time
+}
+
+func NewAdminHandler(store BackupStore) *AdminHandler {
+	return &AdminHandler{
+		store:    store,
+		problems: newProblemRegistry(),
+		now:      time.Now,
+	}
+}
+
+func (h *AdminHandler) Backup(w http.ResponseWriter, r *http.Request) {
+	snapshot, err := h.store.Snapshot(r.Context())
+	if err != nil {
+		writeProblem(w, r, h.problems, err)
+		return
+	}
+
+	createdAt := h.now().UTC()
+	w.Header().Set("Content-Type", backup.MediaType)
+	w.Header().Set("Content-Disposition", `attachment; filename="todos-backup-`+createdAt.Format("20060102T150405Z")+`.json.gz"`)
+	w.Header().Set("Cache-Control", "no-store")
+
+	if err = backup.Write(newStreamWriter(w), snapshot, createdAt); err != nil {
+		// обрезанный архив не пройдёт проверку, но клиент должен узнать об ошибке сразу
+		slog.ErrorContext(r.Context(), "backup aborted", logging.Err(err))
+		panic(http.ErrAbortHandler)
+	}
+
+	slog.InfoContext(r.Context(), "backup created", "tasks", len(snapshot.Elems), "serial_id", snapshot.SerialID)
+}
+
+func (h *AdminHandler) Restore(w http.ResponseWriter, r *http.Request) {
+	archive, err := backup.Read(r.Context(), http.MaxBytesReader(w, newStreamReader(w, r.Body), maxRestoreSize))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			err = errBodyTooLarge
+		}
+		// в ответе только тип ошибки, подробности нужны оператору
+		slog.WarnContext(r.Context(), "restore rejected", logging.Err(err))
+		writeProblem(w, r, h.problems, err)
+		return
+	}
+
+	if err = h.store.Restore(r.Context(), archive.Snapshot); err != nil {
+		if errors.Is(err, domain.ErrInvalidSnapshot) {
+			slog.WarnContext(r.Context(), "restore rejected", logging.Err(err))
+		}
+		writeProblem(w, r, h.problems, err)
+		return
+	}
+
+	slog.InfoContext(r.Context(), "storage restored from backup",
+		"tasks", len(archive.Snapshot.Elems), "serial_id", archive.Snapshot.SerialID, "created_at", archive.CreatedAt)
+
+	writeJSON(w, r, dto.RestoreResponse{
+		Tasks:     len(archive.Snapshot.Elems),
+		SerialID:  archive.Snapshot.SerialID,
+		CreatedAt: archive.CreatedAt,
+	}, http.StatusOK)
+}
+
+func (h *AdminHandler) Handlers() []models.Endpoint {
+	errorBody := problem.Problem{}
+
+	return []models.Endpoint{
+		{
+			Pattern: "POST /admin/backup",
+			Func:    h.Backup,
+			Doc: &models.Doc{
+				Summary: "Back up the storage",
+				Description: "Returns a gzip-compressed JSON archive with every task and the next id, taken as one consistent snapshot " +
+					"and protected by a SHA-256 checksum of its payload. Requires a client certificate listed in admin.identities.",
+				Tags: []string{"admin"},
+				Responses: map[int]models.Response{
+					http.StatusOK:                  {Description: "Backup archive as an attachment", Body: dto.BackupArchive("")},
+					http.StatusUnauthorized:        {Description: "No verified client certificate", Body: errorBody},
+					http.StatusForbidden:           {Description: "Client certificate is not an admin identity", Body: errorBody},
+					http.StatusInternalServerError: {Body: errorBody},
+					http.StatusGatewayTimeout:      {Description: "Request deadline exceeded", Body: errorBody},
+				},
+			},
+		},
+		{
+			Pattern: "POST /admin/restore",
+			Func:    h.Restore,
+			Doc: &models.Doc{
+				Summary: "Restore the storage from a backup",
+				Description: "Validates an archive produced by POST /admin/backup, including the built-in task rules and parent references, " +
+					"and replaces all stored tasks with its contents at once; " +
+					"on any error the storage is left unchanged. Restored tasks emit no events. " +
+					"Requires a client certificate listed in admin.identities.",
+				Tags:    []string{"admin"},
+				Request: dto.BackupArchive(""),
+				Responses: map[int]models.Response{
+					http.StatusOK:                    {Description: "Storage restored", Body: dto.RestoreResponse{}},
+					http.StatusBadRequest:            {Description: "Corrupted, tampered or inconsistent archive", Body: errorBody},
+					http.StatusUnauthorized:          {Description: "No verified client certificate", Body: errorBody},
+					http.StatusForbidden:             {Description: "Client certificate is not an admin identity", Body: errorBody},
+					http.StatusRequestEntityTooLarge: {Description: "Archive exceeds 64 MB compressed or 256 MB uncompressed", Body: errorBody},
+					http.StatusInternalServerError:   {Body: errorBody},
+					http.StatusGatewayTimeout:        {Description: "Request deadline exceeded", Body: errorBody},
+				},
+			},
+		},
+	}
+}
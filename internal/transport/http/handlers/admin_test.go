@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Ant-Tab-Shift/todos-service/internal/domain"
+	"github.com/Ant-Tab-Shift/todos-service/internal/infrastructure/storage"
+	"github.com/Ant-Tab-Shift/todos-service/internal/transport/http/dto"
+	"github.com/Ant-Tab-Shift/todos-service/internal/transport/http/problem"
+)
+
+func newAdminHandler(store BackupStore) http.Handler {
+	h := NewAdminHandler(store)
+	h.now = func() time.Time { return time.Date(2026, 2, 1, 12, 0, 0, 0, time.UTC) }
+
+	mux := http.NewServeMux()
+	for _, endpoint := range h.Handlers() {
+		mux.HandleFunc(endpoint.Pattern, endpoint.Func)
+	}
+	return mux
+}
+
+func TestAdminHandler_BackupRestore(t *testing.T) {
+	ctx := context.Background()
+	source := storage.NewInMemory[domain.TaskSchema]()
+	source.Save(ctx, domain.TaskSchema{Title: "Write report", Priority: domain.PriorityHigh}, 0, nil)
+	source.Save(ctx, domain.TaskSchema{Title: "Proofread", Priority: domain.PriorityNormal, ParentID: 1}, 0, nil)
+
+	rec := httptest.NewRecorder()
+	newAdminHandler(source).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/admin/backup", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("backup status = %d, body = %s", rec.Code, rec.Body)
+	}
+	if got := rec.Header().Get("Content-Type"); got != "application/gzip" {
+		t.Errorf("Content-Type = %q", got)
+	}
+	if got := rec.Header().Get("Content-Disposition"); got != `attachment; filename="todos-backup-20260201T120000Z.json.gz"` {
+		t.Errorf("Content-Disposition = %q", got)
+	}
+	archive := rec.Body.Bytes()
+
+	target := storage.NewInMemory[domain.TaskSchema]()
+	target.Save(ctx, domain.TaskSchema{Title: "Replaced"}, 0, nil)
+
+	rec = httptest.NewRecorder()
+	newAdminHandler(target).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/admin/restore", bytes.NewReader(archive)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("restore status = %d, body = %s", rec.Code, rec.Body)
+	}
+	var resp dto.RestoreResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	want := dto.RestoreResponse{Tasks: 2, SerialID: 3, CreatedAt: time.Date(2026, 2, 1, 12, 0, 0, 0, time.UTC)}
+	if resp != want {
+		t.Errorf("response = %+v, want %+v", resp, want)
+	}
+
+	restored, _ := target.GetPage(ctx, 0, 10)
+	if len(restored) != 2 || restored[0].Value.Title != "Write report" || restored[1].Value.ParentID != 1 {
+		t.Errorf("restored = %+v", restored)
+	}
+}
+
+func TestAdminHandler_RestoreRejected(t *testing.T) {
+	ctx := context.Background()
+
+	source := storage.NewInMemory[domain.TaskSchema]()
+	source.Save(ctx, domain.TaskSchema{Title: "Backed up", Priority: domain.PriorityNormal}, 0, nil)
+	rec := httptest.NewRecorder()
+	newAdminHandler(source).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/admin/backup", nil))
+	archive := rec.Body.Bytes()
+
+	tests := []struct {
+		name       string
+		body       []byte
+		wantStatus int
+		wantType   string
+	}{
+		{"not an archive", []byte("tasks"), http.StatusBadRequest, "/problems/invalid-backup"},
+		{"truncated", archive[:len(archive)-8], http.StatusBadRequest, "/problems/invalid-backup"},
+		{"too large", storedGzip(t, maxRestoreSize+1), http.StatusRequestEntityTooLarge, "/problems/body-too-large"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target := storage.NewInMemory[domain.TaskSchema]()
+			target.Save(ctx, domain.TaskSchema{Title: "Kept"}, 0, nil)
+
+			rec := httptest.NewRecorder()
+			newAdminHandler(target).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/admin/restore", bytes.NewReader(tt.body)))
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d, body = %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+			var p problem.Problem
+			json.Unmarshal(rec.Body.Bytes(), &p)
+			if !strings.HasSuffix(p.Type, tt.wantType) {
+				t.Errorf("type = %q, want %q", p.Type, tt.wantType)
+			}
+
+			if all, _ := target.GetAll(ctx); len(all) != 1 || all[0].Value.Title != "Kept" {
+				t.Errorf("storage changed after rejected restore: %+v", all)
+			}
+		})
+	}
+}
+
+// storedGzip возвращает gzip без сжатия, который больше size.
+func storedGzip(t *testing.T, size int) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	zw, _ := gzip.NewWriterLevel(&buf, gzip.NoCompression)
+	zw.Write(make([]byte, size))
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
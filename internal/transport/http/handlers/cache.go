@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Ant-Tab-Shift/todos-service/internal/domain"
+)
+
+func taskETag(revision domain.Revision) string {
+	return `"` + strconv.FormatUint(revision.Version, 10) + `"`
+}
+
+// listETag зависит и от формата: представления списка в JSON и CSV различаются побайтово.
+func listETag(revision domain.Revision, mediaType string) string {
+	_, format, _ := strings.Cut(mediaType, "/")
+	return `"` + strconv.FormatUint(revision.Version, 10) + "-" + strings.TrimPrefix(format, "x-") + `"`
+}
+
+func setValidators(w http.ResponseWriter, etag string, modified time.Time) {
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Last-Modified", modified.UTC().Format(http.TimeFormat))
+}
+
+// writeNotModified отвечает 304 Not Modified, если копия клиента совпадает с текущей версией.
+func writeNotModified(w http.ResponseWriter, r *http.Request, etag string, modified time.Time) bool {
+	if !fresh(r, etag, modified) {
+		return false
+	}
+
+	setValidators(w, etag, modified)
+	w.WriteHeader(http.StatusNotModified)
+
+	return true
+}
+
+// fresh проверяет условия запроса по RFC 9110, 13.2.2: при наличии If-None-Match
+// заголовок If-Modified-Since игнорируется.
+func fresh(r *http.Request, etag string, modified time.Time) bool {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+
+	if header := r.Header.Get("If-None-Match"); header != "" {
+		return etagMatches(header, etag)
+	}
+
+	if header := r.Header.Get("If-Modified-Since"); header != "" {
+		since, err := http.ParseTime(header)
+		// Last-Modified передаётся с точностью до секунды
+		return err == nil && !modified.Truncate(time.Second).After(since)
+	}
+
+	return false
+}
+
+// etagMatches сравнивает теги слабым сравнением, как требует If-None-Match:
+// ответ мог быть сжат, и тогда клиент хранит слабый вариант тега.
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+
+	return false
+}
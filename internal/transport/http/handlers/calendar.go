@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Ant-Tab-Shift/todos-service/internal/domain"
+	"github.com/Ant-Tab-Shift/todos-service/internal/transport/http/ical"
+	"github.com/Ant-Tab-Shift/todos-service/internal/validation"
+)
+
+const (
+	calendarProdID = "-//Ant-Tab-Shift//todos-service//EN"
+	// uidSuffix делает UID глобально уникальным, как требует RFC 5545; ID задачи стоит перед ним.
+	uidSuffix = "@todos-service"
+
+	calendarDate     = "20060102"
+	calendarDateTime = "20060102T150405"
+)
+
+// PRIORITY в iCalendar — число от 1 (наивысший) до 9, 0 означает «не задан».
+var calendarPriority = map[domain.Priority]string{
+	domain.PriorityHigh:   "1",
+	domain.PriorityNormal: "5",
+	domain.PriorityLow:    "9",
+}
+
+// calendarEncoder пишет задачи компонентами VTODO одного VCALENDAR.
+type calendarEncoder struct {
+	w *ical.Writer
+	// stamp — DTSTAMP, момент создания выгрузки
+	stamp     string
+	headerErr error
+}
+
+func newCalendarEncoder(w io.Writer, now time.Time) *calendarEncoder {
+	e := &calendarEncoder{w: ical.NewWriter(w), stamp: now.UTC().Format(calendarDateTime + "Z")}
+	e.headerErr = e.write(
+		ical.Property{Name: "BEGIN", Value: "VCALENDAR"},
+		ical.Property{Name: "VERSION", Value: "2.0"},
+		ical.Property{Name: "PRODID", Value: calendarProdID},
+	)
+
+	return e
+}
+
+func (e *calendarEncoder) Encode(task *domain.Task) error {
+	if e.headerErr != nil {
+		return e.headerErr
+	}
+
+	status := "NEEDS-ACTION"
+	if task.IsDone {
+		status = "COMPLETED"
+	}
+
+	props := []ical.Property{
+		{Name: "BEGIN", Value: "VTODO"},
+		{Name: "UID", Value: strconv.FormatUint(task.ID, 10) + uidSuffix},
+		{Name: "DTSTAMP", Value: e.stamp},
+		{Name: "SUMMARY", Value: ical.EscapeText(task.Title)},
+	}
+	if task.Description != "" {
+		props = append(props, ical.Property{Name: "DESCRIPTION", Value: ical.EscapeText(task.Description)})
+	}
+	if priority, ok := calendarPriority[task.Priority]; ok {
+		props = append(props, ical.Property{Name: "PRIORITY", Value: priority})
+	}
+	props = append(props, ical.Property{Name: "STATUS", Value: status})
+	if !task.DueDate.IsZero() {
+		props = append(props, formatDue(task.DueDate))
+	}
+	props = append(props, ical.Property{Name: "END", Value: "VTODO"})
+
+	return e.write(props...)
+}
+
+func (e *calendarEncoder) Close() error {
+	if e.headerErr != nil {
+		return e.headerErr
+	}
+	if err := e.w.End("VCALENDAR"); err != nil {
+		return err
+	}
+	return e.w.Flush()
+}
+
+func (e *calendarEncoder) write(props ...ical.Property) error {
+	for _, p := range props {
+		if err := e.w.WriteProperty(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// formatDue пишет срок в полночь UTC как дату, остальные — как время UTC.
+func formatDue(due time.Time) ical.Property {
+	due = due.UTC()
+	if due.Equal(due.Truncate(24 * time.Hour)) {
+		return ical.Property{Name: "DUE", Params: []ical.Param{{Name: "VALUE", Value: "DATE"}}, Value: due.Format(calendarDate)}
+	}
+	return ical.Property{Name: "DUE", Value: due.Format(calendarDateTime + "Z")}
+}
+
+// parseDue понимает дату, время UTC, время с TZID и «плавающее» время, которое считается UTC.
+func parseDue(p ical.Property) (time.Time, error) {
+	if value, _ := p.Param("VALUE"); strings.EqualFold(value, "DATE") || len(p.Value) == len(calendarDate) {
+		return time.Parse(calendarDate, p.Value)
+	}
+	if strings.HasSuffix(p.Value, "Z") {
+		return time.Parse(calendarDateTime+"Z", p.Value)
+	}
+
+	location := time.UTC
+	if tzid, ok := p.Param("TZID"); ok {
+		var err error
+		if location, err = time.LoadLocation(strings.TrimPrefix(tzid, "/")); err != nil {
+			return time.Time{}, err
+		}
+	}
+	return time.ParseInLocation(calendarDateTime, p.Value, location)
+}
+
+// taskFromVTODO переводит VTODO в задачу. Без preserveID UID не используется.
+func taskFromVTODO(c *ical.Component, preserveID bool) (domain.Task, error) {
+	var (
+		task domain.Task
+		v    validation.Validator
+	)
+
+	if p, ok := c.Property("SUMMARY"); ok {
+		task.Title = ical.UnescapeText(p.Value)
+	}
+	if p, ok := c.Property("DESCRIPTION"); ok {
+		task.Description = ical.UnescapeText(p.Value)
+	}
+
+	status, _ := c.Property("STATUS")
+	_, completed := c.Property("COMPLETED")
+	task.IsDone = strings.EqualFold(status.Value, "COMPLETED") || completed
+
+	if p, ok := c.Property("PRIORITY"); ok {
+		priority, err := strconv.Atoi(p.Value)
+		switch {
+		case err != nil || priority < 0 || priority > 9:
+			v.Add(&validation.FieldError{Field: "PRIORITY", Code: validation.CodeInvalidValue, Message: "must be an integer from 0 to 9"})
+		case priority == 0:
+		case priority <= 4:
+			task.Priority = domain.PriorityHigh
+		case priority == 5:
+			task.Priority = domain.PriorityNormal
+		default:
+			task.Priority = domain.PriorityLow
+		}
+	}
+
+	if p, ok := c.Property("DUE"); ok {
+		due, err := parseDue(p)
+		if err != nil {
+			v.Add(&validation.FieldError{Field: "DUE", Code: validation.CodeInvalidValue, Message: "must be a DATE or DATE-TIME value"})
+		}
+		task.DueDate = due
+	}
+
+	if preserveID {
+		uid, _ := c.Property("UID")
+		id, _, _ := strings.Cut(uid.Value, "@")
+		parsed, err := strconv.ParseUint(id, 10, 64)
+		if err != nil || parsed == 0 {
+			v.Add(&validation.FieldError{Field: "UID", Code: validation.CodeInvalidValue, Message: "must start with a task id, like 42" + uidSuffix + ", when ids=preserve"})
+		}
+		task.ID = parsed
+	}
+
+	return task, v.Err()
+}
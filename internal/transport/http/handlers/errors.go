@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/Ant-Tab-Shift/todos-service/internal/domain"
+	"github.com/Ant-Tab-Shift/todos-service/internal/infrastructure/backup"
+	"github.com/Ant-Tab-Shift/todos-service/internal/transport/http/problem"
+)
+
+var (
+	errMissingID           = errors.New("task id is required")
+	errInvalidID           = errors.New("invalid task id format")
+	errInvalidBody         = errors.New("invalid request body")
+	errBodyTooLarge        = errors.New("request body too large")
+	errNotAcceptable       = errors.New("none of the accepted media types is supported")
+	errInvalidIDsMode      = errors.New("ids must be preserve or reassign")
+	errLineTooLong         = errors.New("import line too long")
+	errIDsUnsupported      = errors.New("ids=preserve is not supported for Markdown")
+	errInvalidExportFormat = errors.New("format must be ndjson, csv, ics or md")
+)
+
+func newProblemRegistry() *problem.Registry {
+	return problem.NewRegistry().
+		Register(domain.ErrNotExists, http.StatusNotFound, "/problems/task-not-found", "Task not found").
+		Register(domain.ErrAlreadyExists, http.StatusConflict, "/problems/task-exists", "Task already exists").
+		Register(domain.ErrEmptyTitle, http.StatusBadRequest, "/problems/empty-title", "Task title is empty").
+		Register(errMissingID, http.StatusBadRequest, "/problems/invalid-id", "Invalid task id").
+		Register(errInvalidID, http.StatusBadRequest, "/problems/invalid-id", "Invalid task id").
+		Register(errInvalidBody, http.StatusBadRequest, "/problems/invalid-body", "Invalid request body").
+		Register(errBodyTooLarge, http.StatusRequestEntityTooLarge, "/problems/body-too-large", "Request body too large").
+		Register(errInvalidIDsMode, http.StatusBadRequest, "/problems/invalid-ids-mode", "Invalid ids mode").
+		Register(errInvalidExportFormat, http.StatusBadRequest, "/problems/invalid-export-format", "Invalid export format").
+		Register(errIDsUnsupported, http.StatusBadRequest, "/problems/invalid-ids-mode", "Invalid ids mode").
+		Register(errLineTooLong, http.StatusRequestEntityTooLarge, "/problems/line-too-long", "Import line too long").
+		Register(backup.ErrInvalid, http.StatusBadRequest, "/problems/invalid-backup", "Invalid backup archive").
+		Register(domain.ErrInvalidSnapshot, http.StatusBadRequest, "/problems/invalid-backup", "Invalid backup archive").
+		Register(backup.ErrTooLarge, http.StatusRequestEntityTooLarge, "/problems/body-too-large", "Request body too large").
+		Register(errNotAcceptable, http.StatusNotAcceptable, "/problems/not-acceptable", "Not acceptable").
+		Register(context.DeadlineExceeded, http.StatusGatewayTimeout, "/problems/timeout", "Request timed out").
+		Register(context.Canceled, http.StatusServiceUnavailable, "/problems/canceled", "Request canceled")
+}
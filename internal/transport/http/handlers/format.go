@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"cmp"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Ant-Tab-Shift/todos-service/internal/domain"
+	"github.com/Ant-Tab-Shift/todos-service/internal/logging"
+	"github.com/Ant-Tab-Shift/todos-service/internal/transport/http/dto"
+)
+
+const (
+	mediaJSON     = "application/json"
+	mediaCSV      = "text/csv"
+	mediaNDJSON   = "application/x-ndjson"
+	mediaCalendar = "text/calendar"
+	mediaMarkdown = "text/markdown"
+)
+
+// listMediaTypes — представления списка задач; первый используется, если клиент не прислал Accept.
+var listMediaTypes = []string{mediaJSON, mediaCSV, mediaNDJSON}
+
+var csvHeader = []string{"id", "title", "description", "is_done", "priority", "due_date"}
+
+// taskEncoder пишет задачи по одной, чтобы выгрузку не нужно было собирать в памяти.
+type taskEncoder interface {
+	Encode(task *domain.Task) error
+	// Close дописывает окончание документа и сбрасывает буфер.
+	Close() error
+}
+
+// newTaskEncoder выставляет Content-Type и возвращает кодировщик для mediaType.
+func newTaskEncoder(w http.ResponseWriter, mediaType string) taskEncoder {
+	switch mediaType {
+	case mediaCSV:
+		// header=present из RFC 4180 подсказывает табличным редакторам, что первая строка — заголовок
+		w.Header().Set("Content-Type", mediaCSV+"; charset=utf-8; header=present")
+		return newCSVEncoder(w)
+	case mediaCalendar:
+		w.Header().Set("Content-Type", mediaCalendar+"; charset=utf-8")
+		return newCalendarEncoder(w, time.Now())
+	case mediaMarkdown:
+		// variant из RFC 7763 указывает диалект: флажки есть только в GFM
+		w.Header().Set("Content-Type", mediaMarkdown+"; charset=utf-8; variant=GFM")
+		return newMarkdownEncoder(w)
+	default:
+		w.Header().Set("Content-Type", mediaNDJSON)
+		return ndjsonEncoder{json.NewEncoder(w)}
+	}
+}
+
+// writeTaskList пишет список задач в формате mediaType, выбранном по заголовку Accept.
+func writeTaskList(w http.ResponseWriter, r *http.Request, mediaType string, tasks []domain.Task) {
+	// хранилище не гарантирует порядок, а выгрузки удобнее сравнивать при стабильном
+	slices.SortFunc(tasks, func(a, b domain.Task) int { return cmp.Compare(a.ID, b.ID) })
+
+	if mediaType == mediaJSON {
+		writeJSON(w, r, dto.ToTaskListResponse(tasks), http.StatusOK)
+		return
+	}
+
+	enc := newTaskEncoder(w, mediaType)
+	w.WriteHeader(http.StatusOK)
+
+	var err error
+	for i := range tasks {
+		if err = enc.Encode(&tasks[i]); err != nil {
+			break
+		}
+	}
+	if err == nil {
+		err = enc.Close()
+	}
+	if err != nil {
+		slog.ErrorContext(r.Context(), "failed to encode response", logging.Err(err), "content_type", mediaType)
+	}
+}
+
+type ndjsonEncoder struct {
+	enc *json.Encoder
+}
+
+func (e ndjsonEncoder) Encode(task *domain.Task) error {
+	return e.enc.Encode(dto.ToTaskResponse(task))
+}
+
+func (e ndjsonEncoder) Close() error {
+	return nil
+}
+
+// csvEncoder пишет CSV по RFC 4180: CRLF, кавычки вокруг полей с запятыми, кавычками и переводами строк.
+type csvEncoder struct {
+	w         *csv.Writer
+	headerErr error
+}
+
+func newCSVEncoder(w io.Writer) *csvEncoder {
+	cw := csv.NewWriter(w)
+	cw.UseCRLF = true
+
+	return &csvEncoder{w: cw, headerErr: cw.Write(csvHeader)}
+}
+
+func (e *csvEncoder) Encode(task *domain.Task) error {
+	if e.headerErr != nil {
+		return e.headerErr
+	}
+
+	var due string
+	if !task.DueDate.IsZero() {
+		due = task.DueDate.Format(time.RFC3339)
+	}
+
+	return e.w.Write([]string{
+		strconv.FormatUint(task.ID, 10),
+		spreadsheetSafe(task.Title),
+		spreadsheetSafe(task.Description),
+		strconv.FormatBool(task.IsDone),
+		string(task.Priority),
+		due,
+	})
+}
+
+func (e *csvEncoder) Close() error {
+	if e.headerErr != nil {
+		return e.headerErr
+	}
+	e.w.Flush()
+	return e.w.Error()
+}
+
+// spreadsheetSafe не даёт табличному редактору выполнить текст задачи как формулу:
+// значения, начинающиеся с =, +, -, @ или табуляции, получают ведущий апостроф.
+func spreadsheetSafe(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
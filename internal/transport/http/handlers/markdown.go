@@ -0,0 +1,340 @@
+package handlers
+
+import (
+	"bufio"
+	"cmp"
+	"io"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/Ant-Tab-Shift/todos-service/internal/domain"
+)
+
+// maxHeadingLevel — заголовков глубже шестого уровня в Markdown нет.
+const maxHeadingLevel = 6
+
+var (
+	headingPattern  = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+(.*?))?(?:[ \t]+#+)?[ \t]*$`)
+	listItemPattern = regexp.MustCompile(`^([ \t]*)([-*+]|\d{1,9}[.)])(?:([ \t]+)(.*))?$`)
+	checkboxPattern = regexp.MustCompile(`^\[([ xX])\](?:[ \t]+(.*))?$`)
+	fencePattern    = regexp.MustCompile("^ {0,3}(```|~~~)")
+	// markdownSyntax — начало строки описания, которое при чтении приняли бы за разметку
+	markdownSyntax = regexp.MustCompile("^([-*+#>]|\\d{1,9}[.)]|```|~~~|\\\\)")
+)
+
+// checklistItem — пункт чек-листа; Parent — номер строки родительского пункта, 0 у пунктов верхнего уровня.
+type checklistItem struct {
+	Line   int
+	Parent int
+	Task   domain.Task
+}
+
+type markdownHeading struct {
+	level int
+	text  string
+}
+
+// openItem — пункт списка, в который могут быть вложены следующие строки.
+type openItem struct {
+	indent  int
+	content int
+	// line — строка пункта с флажком, 0 у обычных пунктов списка
+	line int
+}
+
+type heldLine struct {
+	text    string
+	tooLong bool
+	err     error
+}
+
+// checklistReader читает пункты чек-листа GFM построчно. Заголовки становятся
+// тегами пунктов под ними, строки с отступом под пунктом — его описанием,
+// остальной текст пропускается.
+type checklistReader struct {
+	r        *bufio.Reader
+	line     int
+	held     *heldLine
+	headings []markdownHeading
+	open     []openItem
+	fenced   bool
+
+	// pending — пункт, к которому ещё могут относиться строки описания
+	pending      *checklistItem
+	pendingBlank bool
+}
+
+func newChecklistReader(r *bufio.Reader) *checklistReader {
+	return &checklistReader{r: r}
+}
+
+// Next возвращает следующий пункт. Для слишком длинной строки возвращает её номер
+// и errLineTooLong, после чего чтение можно продолжить; в конце возвращает io.EOF.
+func (c *checklistReader) Next() (checklistItem, error) {
+	for {
+		var l heldLine
+		if c.held != nil {
+			l, c.held = *c.held, nil
+		} else {
+			text, tooLong, err := readLine(c.r, maxBodySize)
+			if err != nil && err != io.EOF {
+				return checklistItem{Line: c.line + 1}, err
+			}
+			c.line++
+			l = heldLine{text: string(text), tooLong: tooLong, err: err}
+		}
+
+		if c.pending != nil && !l.tooLong && c.continues(l.text) {
+			c.appendDescription(l.text)
+			if l.err == nil {
+				continue
+			}
+			l.text = ""
+		}
+
+		// строка завершает отложенный пункт: отдаём его и разберём строку при следующем вызове
+		if c.pending != nil {
+			item := *c.pending
+			c.pending, c.pendingBlank = nil, false
+			c.held = &l
+			return item, nil
+		}
+
+		if l.tooLong {
+			return checklistItem{Line: c.line}, errLineTooLong
+		}
+		c.parse(l.text)
+		if c.pending == nil && l.err == io.EOF {
+			return checklistItem{}, io.EOF
+		}
+		if l.err == io.EOF {
+			// у последней строки нет продолжения, пункт отдаётся на следующем шаге
+			c.held = &heldLine{err: io.EOF}
+		}
+	}
+}
+
+// continues сообщает, относится ли строка к описанию отложенного пункта.
+func (c *checklistReader) continues(text string) bool {
+	if strings.TrimSpace(text) == "" {
+		c.pendingBlank = c.pending.Task.Description != ""
+		return true
+	}
+	if c.fenced || listItemPattern.MatchString(text) || headingPattern.MatchString(text) {
+		return false
+	}
+	indent, _ := measureIndent(text)
+	return indent >= c.open[len(c.open)-1].content
+}
+
+func (c *checklistReader) appendDescription(text string) {
+	if strings.TrimSpace(text) == "" {
+		return
+	}
+
+	_, rest := measureIndent(text)
+	// обратная косая черта ставится при выгрузке перед строками, похожими на разметку
+	if strings.HasPrefix(rest, `\`) && markdownSyntax.MatchString(rest[1:]) {
+		rest = rest[1:]
+	}
+
+	task := &c.pending.Task
+	switch {
+	case task.Description == "":
+	case c.pendingBlank:
+		task.Description += "\n\n"
+	default:
+		task.Description += "\n"
+	}
+	task.Description += strings.TrimRight(rest, " \t")
+	c.pendingBlank = false
+}
+
+func (c *checklistReader) parse(text string) {
+	if fencePattern.MatchString(text) {
+		c.fenced = !c.fenced
+		return
+	}
+	if c.fenced || strings.TrimSpace(text) == "" {
+		return
+	}
+
+	if m := headingPattern.FindStringSubmatch(text); m != nil {
+		level := len(m[1])
+		for len(c.headings) > 0 && c.headings[len(c.headings)-1].level >= level {
+			c.headings = c.headings[:len(c.headings)-1]
+		}
+		c.headings = append(c.headings, markdownHeading{level: level, text: strings.TrimSpace(m[2])})
+		// заголовок завершает список
+		c.open = c.open[:0]
+		return
+	}
+
+	indent, _ := measureIndent(text)
+	m := listItemPattern.FindStringSubmatch(text)
+	// пункт вложен в открытый, только если начинается не левее его содержимого
+	for len(c.open) > 0 && c.open[len(c.open)-1].content > indent {
+		c.open = c.open[:len(c.open)-1]
+	}
+	if m == nil {
+		return
+	}
+
+	spacing, _ := measureIndent(m[3])
+	item := openItem{indent: indent, content: indent + len(m[2]) + max(min(spacing, 4), 1)}
+
+	box := checkboxPattern.FindStringSubmatch(m[4])
+	if box != nil {
+		item.line = c.line
+		c.pending = &checklistItem{
+			Line: c.line,
+			Task: domain.Task{TaskSchema: domain.TaskSchema{
+				Title:  strings.TrimSpace(box[2]),
+				IsDone: box[1] != " ",
+				Tags:   c.tags(),
+			}},
+		}
+		if len(c.open) > 0 {
+			c.pending.Parent = c.open[len(c.open)-1].line
+		}
+	}
+	c.open = append(c.open, item)
+}
+
+func (c *checklistReader) tags() []string {
+	var tags []string
+	for _, h := range c.headings {
+		if h.text != "" {
+			tags = append(tags, h.text)
+		}
+	}
+	return tags
+}
+
+// measureIndent считает ширину начальных пробелов, раскрывая табуляцию до кратного четырём.
+func measureIndent(text string) (int, string) {
+	width := 0
+	for i, r := range text {
+		switch r {
+		case ' ':
+			width++
+		case '\t':
+			width += 4 - width%4
+		default:
+			return width, text[i:]
+		}
+	}
+	return width, ""
+}
+
+// markdownEncoder выводит задачи чек-листом GFM: задачи верхнего уровня группируются
+// под заголовками из их тегов, подзадачи вкладываются в родителя. Дерево строится
+// целиком, поэтому задачи собираются в памяти до Close.
+type markdownEncoder struct {
+	w       *bufio.Writer
+	tasks   []domain.Task
+	started bool
+}
+
+func newMarkdownEncoder(w io.Writer) *markdownEncoder {
+	return &markdownEncoder{w: bufio.NewWriter(w)}
+}
+
+func (e *markdownEncoder) Encode(task *domain.Task) error {
+	e.tasks = append(e.tasks, *task)
+	return nil
+}
+
+func (e *markdownEncoder) Close() error {
+	present := make(map[uint64]bool, len(e.tasks))
+	for _, task := range e.tasks {
+		present[task.ID] = true
+	}
+
+	var roots []domain.Task
+	children := make(map[uint64][]domain.Task)
+	for _, task := range e.tasks {
+		// подзадачи удалённой задачи выводятся на верхнем уровне
+		if task.ParentID != 0 && present[task.ParentID] {
+			children[task.ParentID] = append(children[task.ParentID], task)
+		} else {
+			roots = append(roots, task)
+		}
+	}
+	slices.SortStableFunc(roots, func(a, b domain.Task) int {
+		return cmp.Or(slices.Compare(a.Tags, b.Tags), cmp.Compare(a.ID, b.ID))
+	})
+
+	written := make(map[uint64]bool, len(e.tasks))
+	var path []string
+	for _, task := range roots {
+		e.writeHeadings(path, task.Tags)
+		path = task.Tags
+		e.writeTree(task, 0, children, written)
+	}
+	// задачи, замкнутые в цикл через parent_id, от корней не достижимы
+	for _, task := range e.tasks {
+		if !written[task.ID] {
+			e.writeTree(task, 0, children, written)
+		}
+	}
+
+	return e.w.Flush()
+}
+
+// writeHeadings выводит заголовки тегов, которых не было у предыдущей группы.
+func (e *markdownEncoder) writeHeadings(prev, tags []string) {
+	common := 0
+	for common < len(prev) && common < len(tags) && prev[common] == tags[common] {
+		common++
+	}
+	// группы отсортированы, поэтому новый путь тегов не короче общей части
+	if common == len(tags) {
+		return
+	}
+
+	if e.started {
+		e.w.WriteString("\n")
+	}
+	for i := common; i < len(tags); i++ {
+		e.w.WriteString(strings.Repeat("#", min(i+1, maxHeadingLevel)) + " " + singleLine(tags[i]) + "\n\n")
+	}
+}
+
+func (e *markdownEncoder) writeTree(task domain.Task, depth int, children map[uint64][]domain.Task, written map[uint64]bool) {
+	if written[task.ID] {
+		return
+	}
+	written[task.ID] = true
+	e.started = true
+
+	indent := strings.Repeat("  ", depth)
+	box := "[ ]"
+	if task.IsDone {
+		box = "[x]"
+	}
+	e.w.WriteString(indent + "- " + box + " " + singleLine(task.Title) + "\n")
+
+	if task.Description != "" {
+		for _, line := range strings.Split(strings.ReplaceAll(task.Description, "\r\n", "\n"), "\n") {
+			if strings.TrimSpace(line) == "" {
+				e.w.WriteString("\n")
+				continue
+			}
+			line = strings.TrimLeft(line, " \t")
+			if markdownSyntax.MatchString(line) {
+				line = `\` + line
+			}
+			e.w.WriteString(indent + "  " + line + "\n")
+		}
+	}
+
+	for _, child := range children[task.ID] {
+		e.writeTree(child, depth+1, children, written)
+	}
+}
+
+func singleLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
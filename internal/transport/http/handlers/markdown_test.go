@@ -0,0 +1,177 @@
+package handlers
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Ant-Tab-Shift/todos-service/internal/domain"
+)
+
+func readChecklist(t *testing.T, input string) []checklistItem {
+	t.Helper()
+
+	c := newChecklistReader(bufio.NewReader(strings.NewReader(input)))
+	var items []checklistItem
+	for {
+		item, err := c.Next()
+		if err == io.EOF {
+			return items
+		}
+		if err != nil {
+			t.Fatalf("Next failed: %v", err)
+		}
+		items = append(items, item)
+	}
+}
+
+func TestChecklistReader(t *testing.T) {
+	input := strings.Join([]string{
+		"Intro paragraph",
+		"- [ ] untagged",
+		"# Work",
+		"",
+		"- [ ] Write report",
+		"  due on Friday,",
+		"  \\- not a list item",
+		"",
+		"  second paragraph",
+		"  - [x] Collect numbers",
+		"    - plain note",
+		"      - [ ] under a plain item",
+		"  * [X] Draw charts",
+		"- [ ] Review",
+		"",
+		"## Meetings #",
+		"1. [ ] Standup",
+		"```",
+		"- [ ] inside a code block",
+		"```",
+		"# Home",
+		"\t- [ ] tab indented",
+		"-  [ ]   spaced   ",
+		"- [] not a checkbox",
+		"- [ ] last without newline",
+	}, "\n")
+
+	type want struct {
+		line, parent int
+		title        string
+		done         bool
+		tags         []string
+		description  string
+	}
+	wants := []want{
+		{2, 0, "untagged", false, nil, ""},
+		{5, 0, "Write report", false, []string{"Work"}, "due on Friday,\n- not a list item\n\nsecond paragraph"},
+		{10, 5, "Collect numbers", true, []string{"Work"}, ""},
+		{12, 0, "under a plain item", false, []string{"Work"}, ""},
+		{13, 5, "Draw charts", true, []string{"Work"}, ""},
+		{14, 0, "Review", false, []string{"Work"}, ""},
+		{17, 0, "Standup", false, []string{"Work", "Meetings"}, ""},
+		{22, 0, "tab indented", false, []string{"Home"}, ""},
+		{23, 0, "spaced", false, []string{"Home"}, ""},
+		{25, 0, "last without newline", false, []string{"Home"}, ""},
+	}
+
+	items := readChecklist(t, input)
+	if len(items) != len(wants) {
+		t.Fatalf("got %d items, want %d: %+v", len(items), len(wants), items)
+	}
+	for i, w := range wants {
+		got := items[i]
+		if got.Line != w.line || got.Parent != w.parent || got.Task.Title != w.title || got.Task.IsDone != w.done ||
+			!reflect.DeepEqual(got.Task.Tags, w.tags) || got.Task.Description != w.description {
+			t.Errorf("item %d = %+v, want %+v", i, got, w)
+		}
+	}
+}
+
+func TestChecklistReader_TooLongLine(t *testing.T) {
+	input := "- [ ] first\n- [ ] " + strings.Repeat("a", maxBodySize) + "\n- [ ] third\n"
+
+	c := newChecklistReader(bufio.NewReader(strings.NewReader(input)))
+	var lines []int
+	for {
+		item, err := c.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil && !errors.Is(err, errLineTooLong) {
+			t.Fatalf("Next failed: %v", err)
+		}
+		if err != nil {
+			lines = append(lines, -item.Line)
+		} else {
+			lines = append(lines, item.Line)
+		}
+	}
+
+	if want := []int{1, -2, 3}; !reflect.DeepEqual(lines, want) {
+		t.Errorf("lines = %v, want %v (negative for errors)", lines, want)
+	}
+}
+
+func TestMarkdownEncoder(t *testing.T) {
+	tasks := []domain.Task{
+		{ID: 1, TaskSchema: domain.TaskSchema{Title: "Report", Tags: []string{"Work"}, Description: "line one\n- dash line\n\nafter blank"}},
+		{ID: 2, TaskSchema: domain.TaskSchema{Title: "Numbers", IsDone: true, ParentID: 1, Tags: []string{"Work"}}},
+		{ID: 3, TaskSchema: domain.TaskSchema{Title: "Loose\nend"}},
+		{ID: 4, TaskSchema: domain.TaskSchema{Title: "Standup", Tags: []string{"Work", "Meetings"}}},
+		{ID: 5, TaskSchema: domain.TaskSchema{Title: "Orphan", ParentID: 99, Tags: []string{"Home"}}},
+		{ID: 6, TaskSchema: domain.TaskSchema{Title: "Deep", ParentID: 2}},
+		{ID: 7, TaskSchema: domain.TaskSchema{Title: "Cycle A", ParentID: 8}},
+		{ID: 8, TaskSchema: domain.TaskSchema{Title: "Cycle B", ParentID: 7}},
+	}
+
+	var b strings.Builder
+	enc := newMarkdownEncoder(&b)
+	for i := range tasks {
+		if err := enc.Encode(&tasks[i]); err != nil {
+			t.Fatalf("Encode failed: %v", err)
+		}
+	}
+	if err := enc.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	want := "- [ ] Loose end\n" +
+		"\n" +
+		"# Home\n" +
+		"\n" +
+		"- [ ] Orphan\n" +
+		"\n" +
+		"# Work\n" +
+		"\n" +
+		"- [ ] Report\n" +
+		"  line one\n" +
+		"  \\- dash line\n" +
+		"\n" +
+		"  after blank\n" +
+		"  - [x] Numbers\n" +
+		"    - [ ] Deep\n" +
+		"\n" +
+		"## Meetings\n" +
+		"\n" +
+		"- [ ] Standup\n" +
+		"- [ ] Cycle A\n" +
+		"  - [ ] Cycle B\n"
+	if got := b.String(); got != want {
+		t.Errorf("output =\n%s\nwant\n%s", got, want)
+	}
+
+	// выгрузка читается обратно с теми же заголовками, флажками, описаниями и вложенностью
+	items := readChecklist(t, want)
+	if len(items) != len(tasks) {
+		t.Fatalf("read back %d items, want %d", len(items), len(tasks))
+	}
+	if report := items[2].Task; report.Title != "Report" || report.Description != tasks[0].Description || !reflect.DeepEqual(report.Tags, []string{"Work"}) {
+		t.Errorf("read back %+v, want %+v", report, tasks[0])
+	}
+	if deep := items[4]; deep.Task.Title != "Deep" || deep.Parent != items[3].Line {
+		t.Errorf("read back %+v, want Deep nested in Numbers", deep)
+	}
+}
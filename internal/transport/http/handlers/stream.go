@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"time"
+)
+
+// streamIdleTimeout — сколько поток выгрузки или загрузки может стоять между фрагментами.
+// http.read_timeout и http.write_timeout рассчитаны на обычные запросы, а поток
+// длится, пока клиент успевает передавать данные, поэтому дедлайн продлевается на каждом фрагменте.
+const streamIdleTimeout = 30 * time.Second
+
+// streamWriter продлевает дедлайн записи соединения перед каждой записью.
+type streamWriter struct {
+	http.ResponseWriter
+	rc *http.ResponseController
+}
+
+func newStreamWriter(w http.ResponseWriter) *streamWriter {
+	return &streamWriter{ResponseWriter: w, rc: http.NewResponseController(w)}
+}
+
+func (sw *streamWriter) Write(b []byte) (int, error) {
+	// без поддержки дедлайнов (например, в тестах) действует дедлайн сервера
+	_ = sw.rc.SetWriteDeadline(time.Now().Add(streamIdleTimeout))
+	return sw.ResponseWriter.Write(b)
+}
+
+func (sw *streamWriter) Unwrap() http.ResponseWriter {
+	return sw.ResponseWriter
+}
+
+// streamReader продлевает дедлайн чтения соединения перед каждым чтением тела.
+type streamReader struct {
+	io.ReadCloser
+	rc *http.ResponseController
+}
+
+func newStreamReader(w http.ResponseWriter, body io.ReadCloser) *streamReader {
+	return &streamReader{ReadCloser: body, rc: http.NewResponseController(w)}
+}
+
+func (sr *streamReader) Read(p []byte) (int, error) {
+	_ = sr.rc.SetReadDeadline(time.Now().Add(streamIdleTimeout))
+	return sr.ReadCloser.Read(p)
+}
@@ -4,103 +4,134 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
+	"log/slog"
 	"net/http"
 	"strconv"
 
 	"github.com/Ant-Tab-Shift/todos-service/internal/domain"
+	"github.com/Ant-Tab-Shift/todos-service/internal/logging"
 	"github.com/Ant-Tab-Shift/todos-service/internal/transport/http/dto"
 	"github.com/Ant-Tab-Shift/todos-service/internal/transport/http/models"
+	"github.com/Ant-Tab-Shift/todos-service/internal/transport/http/negotiate"
+	"github.com/Ant-Tab-Shift/todos-service/internal/transport/http/problem"
+	"github.com/Ant-Tab-Shift/todos-service/internal/validation"
 )
 
+const maxBodySize = 1 << 20
+
 type TaskService interface {
-	Create(ctx context.Context, title, description string) (domain.Task, error)
+	Create(ctx context.Context, task domain.TaskSchema) (domain.Task, error)
 	GetByID(ctx context.Context, id uint64) (domain.Task, error)
+	GetByIDWithRevision(ctx context.Context, id uint64) (domain.Task, domain.Revision, error)
+	Revision(ctx context.Context) (domain.Revision, error)
 	GetAll(ctx context.Context) ([]domain.Task, error)
-	Update(ctx context.Context, id uint64, title, description string, completed bool) error
+	Update(ctx context.Context, id uint64, task domain.TaskSchema, keep ...domain.TaskField) error
 	Delete(ctx context.Context, id uint64) error
+	Export(ctx context.Context, fn func(domain.Task) error) error
+	Import(ctx context.Context, task domain.Task, preserveID bool) (domain.Task, error)
+	DetachUnresolvedParents(ctx context.Context, ids []uint64) (map[uint64]error, error)
 }
 
 type TaskHandler struct {
-	service TaskService
+	service  TaskService
+	problems *problem.Registry
 }
 
 func NewTaskHandler(service TaskService) *TaskHandler {
-	return &TaskHandler{service: service}
+	return &TaskHandler{
+		service:  service,
+		problems: newProblemRegistry(),
+	}
 }
 
 func (h *TaskHandler) Create(w http.ResponseWriter, r *http.Request) {
 	var req dto.CreateTaskRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeJSON(w, dto.ErrorResponse{Error: "invalid request body"}, http.StatusBadRequest)
+	if err := decodeJSON(w, r, &req); err != nil {
+		h.writeError(w, r, err)
 		return
 	}
 
-	task, err := h.service.Create(r.Context(), req.Title, req.Description)
+	task, err := h.service.Create(r.Context(), dto.FromCreateTaskRequest(req))
 	if err != nil {
-		if errors.Is(err, domain.ErrEmptyTitle) {
-			writeJSON(w, dto.ErrorResponse{Error: err.Error()}, http.StatusBadRequest)
-			return
-		}
-		writeJSON(w, dto.ErrorResponse{Error: "internal server error"}, http.StatusInternalServerError)
+		h.writeError(w, r, err)
 		return
 	}
 
-	writeJSON(w, dto.ToTaskResponse(&task), http.StatusCreated)
+	writeJSON(w, r, dto.ToTaskResponse(&task), http.StatusCreated)
 }
 
 func (h *TaskHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 	id, err := parseIDFromPath(r)
 	if err != nil {
-		writeJSON(w, dto.ErrorResponse{Error: err.Error()}, http.StatusBadRequest)
+		h.writeError(w, r, err)
 		return
 	}
 
-	task, err := h.service.GetByID(r.Context(), id)
+	task, revision, err := h.service.GetByIDWithRevision(r.Context(), id)
 	if err != nil {
-		if errors.Is(err, domain.ErrNotExists) {
-			writeJSON(w, dto.ErrorResponse{Error: "task not found"}, http.StatusNotFound)
-			return
-		}
-		writeJSON(w, dto.ErrorResponse{Error: "internal server error"}, http.StatusInternalServerError)
+		h.writeError(w, r, err)
 		return
 	}
 
-	writeJSON(w, dto.ToTaskResponse(&task), http.StatusOK)
+	etag := taskETag(revision)
+	if writeNotModified(w, r, etag, revision.ModifiedAt) {
+		return
+	}
+
+	setValidators(w, etag, revision.ModifiedAt)
+	writeJSON(w, r, dto.ToTaskResponse(&task), http.StatusOK)
 }
 
 func (h *TaskHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	w.Header().Add("Vary", "Accept")
+
+	mediaType, ok := negotiate.ContentType(r.Header.Get("Accept"), listMediaTypes...)
+	if !ok {
+		h.writeError(w, r, errNotAcceptable)
+		return
+	}
+
+	// версия читается раньше списка: если список успеет измениться, клиент получит
+	// новые данные со старым тегом и при следующем запросе просто скачает их снова
+	revision, err := h.service.Revision(r.Context())
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	etag := listETag(revision, mediaType)
+	if writeNotModified(w, r, etag, revision.ModifiedAt) {
+		return
+	}
+
 	tasks, err := h.service.GetAll(r.Context())
 	if err != nil {
-		writeJSON(w, dto.ErrorResponse{Error: "internal server error"}, http.StatusInternalServerError)
+		h.writeError(w, r, err)
 		return
 	}
 
-	writeJSON(w, dto.ToTaskListResponse(tasks), http.StatusOK)
+	setValidators(w, etag, revision.ModifiedAt)
+	writeTaskList(w, r, mediaType, tasks)
 }
 
 func (h *TaskHandler) Update(w http.ResponseWriter, r *http.Request) {
 	id, err := parseIDFromPath(r)
 	if err != nil {
-		writeJSON(w, dto.ErrorResponse{Error: err.Error()}, http.StatusBadRequest)
+		h.writeError(w, r, err)
 		return
 	}
 
 	var req dto.UpdateTaskRequest
-	if err = json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeJSON(w, dto.ErrorResponse{Error: "invalid request body"}, http.StatusBadRequest)
+	if err = decodeJSON(w, r, &req); err != nil {
+		h.writeError(w, r, err)
 		return
 	}
 
-	if err = h.service.Update(r.Context(), id, req.Title, req.Description, req.IsDone); err != nil {
-		if errors.Is(err, domain.ErrNotExists) {
-			writeJSON(w, dto.ErrorResponse{Error: "task not found"}, http.StatusNotFound)
-			return
-		}
-		if errors.Is(err, domain.ErrEmptyTitle) {
-			writeJSON(w, dto.ErrorResponse{Error: err.Error()}, http.StatusBadRequest)
-			return
-		}
-		writeJSON(w, dto.ErrorResponse{Error: "internal server error"}, http.StatusInternalServerError)
+	task, keep := dto.FromUpdateTaskRequest(req)
+	if err = h.service.Update(r.Context(), id, task, keep...); err != nil {
+		h.writeError(w, r, err)
 		return
 	}
 
@@ -110,16 +141,12 @@ func (h *TaskHandler) Update(w http.ResponseWriter, r *http.Request) {
 func (h *TaskHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	id, err := parseIDFromPath(r)
 	if err != nil {
-		writeJSON(w, dto.ErrorResponse{Error: err.Error()}, http.StatusBadRequest)
+		h.writeError(w, r, err)
 		return
 	}
 
 	if err = h.service.Delete(r.Context(), id); err != nil {
-		if errors.Is(err, domain.ErrNotExists) {
-			writeJSON(w, dto.ErrorResponse{Error: "task not found"}, http.StatusNotFound)
-			return
-		}
-		writeJSON(w, dto.ErrorResponse{Error: "internal server error"}, http.StatusInternalServerError)
+		h.writeError(w, r, err)
 		return
 	}
 
@@ -127,35 +154,184 @@ func (h *TaskHandler) Delete(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *TaskHandler) Handlers() []models.Endpoint {
+	idParam := map[string]any{"id": uint64(0)}
+	errorBody := problem.Problem{}
+
 	return []models.Endpoint{
-		{Pattern: "POST /todos", Func: h.Create},
-		{Pattern: "GET /todos/{id}", Func: h.GetByID},
-		{Pattern: "GET /todos", Func: h.GetAll},
-		{Pattern: "PUT /todos/{id}", Func: h.Update},
-		{Pattern: "DELETE /todos/{id}", Func: h.Delete},
+		{
+			Pattern: "POST /todos",
+			Func:    h.Create,
+			Doc: &models.Doc{
+				Summary: "Create a task",
+				Tags:    []string{"todos"},
+				Request: dto.CreateTaskRequest{},
+				Responses: map[int]models.Response{
+					http.StatusCreated:               {Body: dto.TaskResponse{}},
+					http.StatusBadRequest:            {Description: "Invalid body, with a list of invalid fields", Body: errorBody},
+					http.StatusRequestEntityTooLarge: {Body: errorBody},
+					http.StatusInternalServerError:   {Body: errorBody},
+					http.StatusGatewayTimeout:        {Description: "Request deadline exceeded", Body: errorBody},
+				},
+			},
+		},
+		{
+			Pattern: "GET /todos/{id}",
+			Func:    h.GetByID,
+			Doc: &models.Doc{
+				Summary:    "Get a task by id",
+				Tags:       []string{"todos"},
+				PathParams: idParam,
+				Responses: map[int]models.Response{
+					http.StatusOK:                  {Description: "Task with ETag and Last-Modified validators", Body: dto.TaskResponse{}},
+					http.StatusNotModified:         {Description: "Task has not changed since If-None-Match or If-Modified-Since"},
+					http.StatusBadRequest:          {Description: "Invalid task id", Body: errorBody},
+					http.StatusNotFound:            {Body: errorBody},
+					http.StatusInternalServerError: {Body: errorBody},
+					http.StatusGatewayTimeout:      {Description: "Request deadline exceeded", Body: errorBody},
+				},
+			},
+		},
+		{
+			Pattern: "GET /todos",
+			Func:    h.GetAll,
+			Doc: &models.Doc{
+				Summary:     "List all tasks",
+				Description: "Returns application/json (default), text/csv or application/x-ndjson according to the Accept header.",
+				Tags:        []string{"todos"},
+				Responses: map[int]models.Response{
+					http.StatusOK:                  {Description: "Task list with ETag and Last-Modified validators", Body: dto.TaskListResponse{}},
+					http.StatusNotModified:         {Description: "Task list has not changed since If-None-Match or If-Modified-Since"},
+					http.StatusNotAcceptable:       {Description: "None of the supported media types is acceptable", Body: errorBody},
+					http.StatusInternalServerError: {Body: errorBody},
+					http.StatusGatewayTimeout:      {Description: "Request deadline exceeded", Body: errorBody},
+				},
+			},
+		},
+		{
+			Pattern: "GET /todos/export",
+			Func:    h.Export,
+			Doc: &models.Doc{
+				Summary: "Export all tasks",
+				Description: "Streams every task ordered by id as application/x-ndjson (default), RFC 4180 text/csv, RFC 5545 text/calendar with one VTODO per task, " +
+					"or a text/markdown checklist with tag headings and nested subtasks. " +
+					"The format is taken from the format parameter (ndjson, csv, ics or md) or negotiated by the Accept header. " +
+					"The stream is not a snapshot: changes made during the export may be partially included.",
+				Tags:        []string{"todos"},
+				QueryParams: map[string]any{"format": ""},
+				Responses: map[int]models.Response{
+					http.StatusOK:                  {Description: "Tasks as an attachment; the schema describes one application/x-ndjson line", Body: dto.TaskLine{}},
+					http.StatusBadRequest:          {Description: "Unknown format", Body: errorBody},
+					http.StatusNotAcceptable:       {Description: "None of the supported media types is acceptable", Body: errorBody},
+					http.StatusInternalServerError: {Body: errorBody},
+					http.StatusGatewayTimeout:      {Description: "Request deadline exceeded", Body: errorBody},
+				},
+			},
+		},
+		{
+			Pattern: "POST /todos/import",
+			Func:    h.Import,
+			Doc: &models.Doc{
+				Summary: "Import tasks",
+				Description: "Reads tasks as one JSON object per line, VTODO components when Content-Type is text/calendar, or checklist items when it is text/markdown, " +
+					"and validates each like a created task, keeping is_done. " +
+					"Errors point to the line of the JSON object, of BEGIN:VTODO or of the checklist item; a VTODO keeps its id with ids=preserve when its UID starts with it. " +
+					"Markdown headings become tags and nested items become subtasks; ids=preserve is rejected for Markdown, and NDJSON keeps parent_id only with ids=preserve. " +
+					"With ids=preserve tasks keep their ids and an occupied id fails the line; with ids=reassign (default) new ids are assigned. " +
+					"Invalid lines are skipped and reported; the import stops on a storage failure or an unreadable body.",
+				Tags:        []string{"todos"},
+				QueryParams: map[string]any{"ids": ""},
+				Request:     dto.ImportTaskRequest{},
+				Responses: map[int]models.Response{
+					http.StatusOK:                    {Description: "Import report with per-line errors", Body: dto.ImportResponse{}},
+					http.StatusBadRequest:            {Description: "Invalid or unsupported ids mode (problem details), or the body could not be read or parsed as iCalendar (import report)", Body: dto.ImportResponse{}},
+					http.StatusRequestEntityTooLarge: {Description: "Body exceeds 64 MB (import report)", Body: dto.ImportResponse{}},
+					http.StatusInternalServerError:   {Description: "Storage failure (import report)", Body: dto.ImportResponse{}},
+					http.StatusGatewayTimeout:        {Description: "Request deadline exceeded (import report)", Body: dto.ImportResponse{}},
+				},
+			},
+		},
+		{
+			Pattern: "PUT /todos/{id}",
+			Func:    h.Update,
+			Doc: &models.Doc{
+				Summary:    "Update a task",
+				Tags:       []string{"todos"},
+				PathParams: idParam,
+				Request:    dto.UpdateTaskRequest{},
+				Responses: map[int]models.Response{
+					http.StatusNoContent:             {Description: "Task updated"},
+					http.StatusBadRequest:            {Description: "Invalid id or body, with a list of invalid fields", Body: errorBody},
+					http.StatusRequestEntityTooLarge: {Body: errorBody},
+					http.StatusNotFound:              {Body: errorBody},
+					http.StatusInternalServerError:   {Body: errorBody},
+					http.StatusGatewayTimeout:        {Description: "Request deadline exceeded", Body: errorBody},
+				},
+			},
+		},
+		{
+			Pattern: "DELETE /todos/{id}",
+			Func:    h.Delete,
+			Doc: &models.Doc{
+				Summary:    "Delete a task",
+				Tags:       []string{"todos"},
+				PathParams: idParam,
+				Responses: map[int]models.Response{
+					http.StatusNoContent:           {Description: "Task deleted"},
+					http.StatusBadRequest:          {Description: "Invalid task id", Body: errorBody},
+					http.StatusNotFound:            {Body: errorBody},
+					http.StatusInternalServerError: {Body: errorBody},
+					http.StatusGatewayTimeout:      {Description: "Request deadline exceeded", Body: errorBody},
+				},
+			},
+		},
 	}
 }
 
 func parseIDFromPath(r *http.Request) (uint64, error) {
 	idStr := r.PathValue("id")
 	if idStr == "" {
-		return 0, errors.New("task id is required")
+		return 0, errMissingID
 	}
 
 	id, err := strconv.ParseUint(idStr, 10, 64)
 	if err != nil {
-		return 0, errors.New("invalid task id format")
+		return 0, errInvalidID
 	}
 
 	return id, nil
 }
 
-func writeJSON(w http.ResponseWriter, data interface{}, status int) {
+func decodeJSON(w http.ResponseWriter, r *http.Request, dst any) error {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodySize))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return errBodyTooLarge
+		}
+		return fmt.Errorf("%w: %w", errInvalidBody, err)
+	}
+
+	return validation.DecodeJSON(body, dst)
+}
+
+func (h *TaskHandler) writeError(w http.ResponseWriter, r *http.Request, err error) {
+	writeProblem(w, r, h.problems, err)
+}
+
+func writeProblem(w http.ResponseWriter, r *http.Request, problems *problem.Registry, err error) {
+	p := problems.Resolve(err)
+	if p.Status >= http.StatusInternalServerError {
+		slog.ErrorContext(r.Context(), "request failed", logging.Err(err))
+	}
+	problem.Write(w, r, p)
+}
+
+func writeJSON(w http.ResponseWriter, r *http.Request, data interface{}, status int) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
 
 	if err := json.NewEncoder(w).Encode(data); err != nil {
-		// логирование ошибки (TODO: использовать logger)
-		http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		// заголовки уже отправлены, поменять статус нельзя - остаётся только записать в лог
+		slog.ErrorContext(r.Context(), "failed to encode response", logging.Err(err))
 	}
 }
@@ -0,0 +1,220 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Ant-Tab-Shift/todos-service/internal/domain"
+	"github.com/Ant-Tab-Shift/todos-service/internal/transport/http/dto"
+)
+
+type stubService struct {
+	TaskService
+	tasks    []domain.Task
+	revision domain.Revision
+}
+
+func (s *stubService) GetAll(ctx context.Context) ([]domain.Task, error) {
+	return s.tasks, nil
+}
+
+func (s *stubService) Revision(ctx context.Context) (domain.Revision, error) {
+	return s.revision, nil
+}
+
+func (s *stubService) GetByIDWithRevision(ctx context.Context, id uint64) (domain.Task, domain.Revision, error) {
+	for _, task := range s.tasks {
+		if task.ID == id {
+			return task, s.revision, nil
+		}
+	}
+	return domain.Task{}, domain.Revision{}, domain.ErrNotExists
+}
+
+var testModified = time.Date(2026, time.March, 1, 12, 30, 15, 500, time.UTC)
+
+func newTestHandler() *TaskHandler {
+	return NewTaskHandler(&stubService{
+		tasks: []domain.Task{
+			{ID: 2, TaskSchema: domain.TaskSchema{Title: "Buy milk, eggs", Description: "say \"hi\"", Priority: domain.PriorityHigh}},
+			{ID: 1, TaskSchema: domain.TaskSchema{Title: "Write report", IsDone: true, Priority: domain.PriorityNormal}},
+		},
+		revision: domain.Revision{Version: 42, ModifiedAt: testModified},
+	})
+}
+
+func TestTaskHandler_GetAll_Negotiation(t *testing.T) {
+	tests := []struct {
+		name            string
+		accept          string
+		wantStatus      int
+		wantContentType string
+		wantBody        string
+	}{
+		{
+			name:            "csv",
+			accept:          "text/csv",
+			wantStatus:      http.StatusOK,
+			wantContentType: "text/csv; charset=utf-8; header=present",
+			wantBody: "id,title,description,is_done,priority,due_date\r\n" +
+				"1,Write report,,true,normal,\r\n" +
+				"2,\"Buy milk, eggs\",\"say \"\"hi\"\"\",false,high,\r\n",
+		},
+		{
+			name:            "ndjson preferred by q",
+			accept:          "application/json;q=0.5, application/x-ndjson",
+			wantStatus:      http.StatusOK,
+			wantContentType: "application/x-ndjson",
+			wantBody: `{"id":1,"title":"Write report","description":"","is_done":true,"priority":"normal"}` + "\n" +
+				`{"id":2,"title":"Buy milk, eggs","description":"say \"hi\"","is_done":false,"priority":"high"}` + "\n",
+		},
+		{
+			name:            "wildcard falls back to json",
+			accept:          "*/*",
+			wantStatus:      http.StatusOK,
+			wantContentType: "application/json",
+		},
+		{
+			name:            "unsupported type",
+			accept:          "application/xml",
+			wantStatus:      http.StatusNotAcceptable,
+			wantContentType: "application/problem+json",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/todos", nil)
+			req.Header.Set("Accept", tt.accept)
+			rec := httptest.NewRecorder()
+			newTestHandler().GetAll(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if got := rec.Header().Get("Content-Type"); got != tt.wantContentType {
+				t.Errorf("Content-Type = %q, want %q", got, tt.wantContentType)
+			}
+			if got := rec.Header().Get("Vary"); got != "Accept" {
+				t.Errorf("Vary = %q, want Accept", got)
+			}
+			if tt.wantBody != "" && rec.Body.String() != tt.wantBody {
+				t.Errorf("body = %q, want %q", rec.Body.String(), tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestTaskHandler_GetAll_DefaultsToJSON(t *testing.T) {
+	rec := httptest.NewRecorder()
+	newTestHandler().GetAll(rec, httptest.NewRequest(http.MethodGet, "/todos", nil))
+
+	if got := rec.Header().Get("Content-Type"); got != "application/json" {
+		t.Fatalf("Content-Type = %q, want application/json", got)
+	}
+
+	var list dto.TaskListResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &list); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if list.Total != 2 || list.Tasks[0].ID != 1 || list.Tasks[1].ID != 2 {
+		t.Errorf("list = %+v, want tasks 1 and 2 in order", list)
+	}
+}
+
+func TestTaskHandler_ConditionalGet(t *testing.T) {
+	lastModified := testModified.Format(http.TimeFormat)
+
+	tests := []struct {
+		name       string
+		path       string
+		accept     string
+		header     map[string]string
+		wantStatus int
+		wantETag   string
+	}{
+		{name: "task validators", path: "/todos/1", wantStatus: http.StatusOK, wantETag: `"42"`},
+		{name: "task matching etag", path: "/todos/1", header: map[string]string{"If-None-Match": `"7", "42"`}, wantStatus: http.StatusNotModified, wantETag: `"42"`},
+		{name: "task weak etag of compressed response", path: "/todos/1", header: map[string]string{"If-None-Match": `W/"42"`}, wantStatus: http.StatusNotModified, wantETag: `"42"`},
+		{name: "task stale etag", path: "/todos/1", header: map[string]string{"If-None-Match": `"41"`}, wantStatus: http.StatusOK, wantETag: `"42"`},
+		{name: "task not modified since", path: "/todos/1", header: map[string]string{"If-Modified-Since": lastModified}, wantStatus: http.StatusNotModified, wantETag: `"42"`},
+		{name: "task modified since", path: "/todos/1", header: map[string]string{"If-Modified-Since": testModified.Add(-time.Second).Format(http.TimeFormat)}, wantStatus: http.StatusOK, wantETag: `"42"`},
+		{name: "etag takes precedence", path: "/todos/1", header: map[string]string{"If-None-Match": `"41"`, "If-Modified-Since": lastModified}, wantStatus: http.StatusOK, wantETag: `"42"`},
+		{name: "missing task has no validators", path: "/todos/9", header: map[string]string{"If-None-Match": "*"}, wantStatus: http.StatusNotFound},
+		{name: "list validators", path: "/todos", wantStatus: http.StatusOK, wantETag: `"42-json"`},
+		{name: "list matching etag", path: "/todos", header: map[string]string{"If-None-Match": `"42-json"`}, wantStatus: http.StatusNotModified, wantETag: `"42-json"`},
+		{name: "list etag depends on format", path: "/todos", accept: "text/csv", header: map[string]string{"If-None-Match": `"42-json"`}, wantStatus: http.StatusOK, wantETag: `"42-csv"`},
+	}
+
+	mux := http.NewServeMux()
+	for _, endpoint := range newTestHandler().Handlers() {
+		mux.HandleFunc(endpoint.Pattern, endpoint.Func)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			for name, value := range tt.header {
+				req.Header.Set(name, value)
+			}
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if got := rec.Header().Get("ETag"); got != tt.wantETag {
+				t.Errorf("ETag = %q, want %q", got, tt.wantETag)
+			}
+			if tt.wantETag != "" {
+				if got := rec.Header().Get("Last-Modified"); got != lastModified {
+					t.Errorf("Last-Modified = %q, want %q", got, lastModified)
+				}
+			}
+			if rec.Code == http.StatusNotModified && rec.Body.Len() != 0 {
+				t.Errorf("304 response has a body: %q", rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestTaskHandler_Update_OmittedFields(t *testing.T) {
+	ctx := context.Background()
+	handler, service := newTransferHandler(t)
+	due := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	parent, _ := service.Create(ctx, domain.TaskSchema{Title: "Parent"})
+	task, _ := service.Create(ctx, domain.TaskSchema{Title: "Child", DueDate: due, Tags: []string{"work"}, ParentID: parent.ID})
+
+	update := func(body string) domain.Task {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/todos/2", strings.NewReader(body)))
+		if rec.Code != http.StatusNoContent {
+			t.Fatalf("status = %d, body = %s", rec.Code, rec.Body)
+		}
+		updated, err := service.GetByID(ctx, task.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return updated
+	}
+
+	kept := update(`{"title":"Renamed","description":"","is_done":true}`)
+	if kept.Title != "Renamed" || !kept.DueDate.Equal(due) || !reflect.DeepEqual(kept.Tags, []string{"work"}) || kept.ParentID != parent.ID {
+		t.Errorf("after update without optional fields = %+v", kept)
+	}
+
+	cleared := update(`{"title":"Renamed","description":"","is_done":true,"due_date":null,"tags":[],"parent_id":0}`)
+	if !cleared.DueDate.IsZero() || len(cleared.Tags) != 0 || cleared.ParentID != 0 {
+		t.Errorf("after clearing optional fields = %+v", cleared)
+	}
+}
@@ -0,0 +1,380 @@
+package handlers
+
+import (
+	"bufio"
+	"bytes"
+	"cmp"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"mime"
+	"net/http"
+	"slices"
+
+	"github.com/Ant-Tab-Shift/todos-service/internal/domain"
+	"github.com/Ant-Tab-Shift/todos-service/internal/logging"
+	"github.com/Ant-Tab-Shift/todos-service/internal/transport/http/dto"
+	"github.com/Ant-Tab-Shift/todos-service/internal/transport/http/ical"
+	"github.com/Ant-Tab-Shift/todos-service/internal/transport/http/negotiate"
+	"github.com/Ant-Tab-Shift/todos-service/internal/transport/http/problem"
+	"github.com/Ant-Tab-Shift/todos-service/internal/validation"
+)
+
+const (
+	// maxImportSize ограничивает весь импорт; отдельная строка ограничена maxBodySize.
+	maxImportSize = 64 << 20
+	// maxImportErrors — сколько ошибок строк попадает в отчёт; счётчик failed при этом точный.
+	maxImportErrors = 100
+
+	idsPreserve = "preserve"
+	idsReassign = "reassign"
+)
+
+// exportFormats сопоставляет значения параметра format с типом и именем файла выгрузки.
+var exportFormats = map[string]struct{ mediaType, filename string }{
+	"ndjson": {mediaNDJSON, "todos.ndjson"},
+	"csv":    {mediaCSV, "todos.csv"},
+	"ics":    {mediaCalendar, "todos.ics"},
+	"md":     {mediaMarkdown, "todos.md"},
+}
+
+// exportMediaTypes — форматы выгрузки для Accept; первый используется по умолчанию.
+var exportMediaTypes = []string{mediaNDJSON, mediaCSV, mediaCalendar, mediaMarkdown}
+
+// Export выгружает все задачи в NDJSON, CSV, iCalendar или чек-листом Markdown; кроме
+// Markdown, задачи не собираются в памяти целиком. Формат задаётся параметром format,
+// а без него выбирается по заголовку Accept.
+func (h *TaskHandler) Export(w http.ResponseWriter, r *http.Request) {
+	w = newStreamWriter(w)
+	w.Header().Add("Vary", "Accept")
+
+	mediaType, filename, err := exportFormat(r)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+
+	started := false
+	enc := newTaskEncoder(w, mediaType)
+	err = h.service.Export(r.Context(), func(task domain.Task) error {
+		started = true
+		return enc.Encode(&task)
+	})
+	if err == nil {
+		if err = enc.Close(); err == nil {
+			return
+		}
+		started = true
+	}
+
+	if !started {
+		w.Header().Del("Content-Disposition")
+		h.writeError(w, r, err)
+		return
+	}
+
+	// статус уже отправлен: обрываем соединение, чтобы клиент не принял
+	// обрезанную выгрузку за полную
+	slog.ErrorContext(r.Context(), "task export aborted", logging.Err(err))
+	panic(http.ErrAbortHandler)
+}
+
+func exportFormat(r *http.Request) (mediaType, filename string, err error) {
+	if name := r.URL.Query().Get("format"); name != "" {
+		format, ok := exportFormats[name]
+		if !ok {
+			return "", "", errInvalidExportFormat
+		}
+		return format.mediaType, format.filename, nil
+	}
+
+	mediaType, ok := negotiate.ContentType(r.Header.Get("Accept"), exportMediaTypes...)
+	if !ok {
+		return "", "", errNotAcceptable
+	}
+	for _, format := range exportFormats {
+		if format.mediaType == mediaType {
+			filename = format.filename
+		}
+	}
+	return mediaType, filename, nil
+}
+
+// Import читает задачи из NDJSON, из компонентов VTODO (Content-Type text/calendar)
+// или из чек-листа Markdown (text/markdown). Ошибочные задачи пропускаются и попадают
+// в отчёт; сбой хранилища или тела запроса прерывает импорт.
+func (h *TaskHandler) Import(w http.ResponseWriter, r *http.Request) {
+	var preserveID bool
+	switch r.URL.Query().Get("ids") {
+	case "", idsReassign:
+	case idsPreserve:
+		preserveID = true
+	default:
+		h.writeError(w, r, errInvalidIDsMode)
+		return
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	// в чек-листе нет ID, подзадачи связываются с только что созданными родителями
+	if mediaType == mediaMarkdown && preserveID {
+		h.writeError(w, r, errIDsUnsupported)
+		return
+	}
+
+	im := &importer{
+		h:          h,
+		r:          r,
+		preserveID: preserveID,
+		report:     dto.ImportResponse{Errors: []dto.ImportLineError{}},
+		status:     http.StatusOK,
+	}
+	body := bufio.NewReader(http.MaxBytesReader(w, newStreamReader(w, r.Body), maxImportSize))
+
+	switch mediaType {
+	case mediaCalendar:
+		im.readCalendar(body)
+	case mediaMarkdown:
+		im.readMarkdown(body)
+	default:
+		im.readNDJSON(body)
+	}
+	im.resolveParents()
+
+	writeJSON(w, r, im.report, im.status)
+}
+
+// importer собирает отчёт об импорте; номер строки в ошибках указывает на строку
+// NDJSON или на BEGIN:VTODO.
+type importer struct {
+	h          *TaskHandler
+	r          *http.Request
+	preserveID bool
+	report     dto.ImportResponse
+	status     int
+	// parents — строки задач, импортированных с parent_id; с ids=preserve родитель
+	// может прийти позже подзадачи, поэтому ссылки проверяются в конце
+	parents map[uint64]int
+}
+
+func (im *importer) readNDJSON(body *bufio.Reader) {
+	for lineNo := 1; ; lineNo++ {
+		line, tooLong, err := readLine(body, maxBodySize)
+		if err != nil && err != io.EOF {
+			im.abort(lineNo, bodyError(err))
+			return
+		}
+
+		switch {
+		case tooLong:
+			im.fail(lineNo, errLineTooLong)
+		case len(bytes.TrimSpace(line)) > 0:
+			if !im.add(lineNo, im.importLine(lineNo, line)) {
+				return
+			}
+		}
+
+		if err == io.EOF {
+			return
+		}
+	}
+}
+
+func (im *importer) importLine(lineNo int, line []byte) error {
+	var req dto.ImportTaskRequest
+	if err := validation.DecodeJSON(line, &req); err != nil {
+		return err
+	}
+	if im.preserveID && req.ID == 0 {
+		return validation.Errors{{Field: "id", Code: validation.CodeRequired, Message: "is required when ids=preserve"}}
+	}
+
+	task := dto.FromImportTaskRequest(req)
+	// новые ID родителей неизвестны, поэтому без ids=preserve связь не переносится
+	if !im.preserveID {
+		task.ParentID = 0
+	}
+
+	return im.importTask(lineNo, task)
+}
+
+func (im *importer) importTask(line int, task domain.Task) error {
+	imported, err := im.h.service.Import(im.r.Context(), task, im.preserveID)
+	if err == nil && im.preserveID && imported.ParentID != 0 {
+		if im.parents == nil {
+			im.parents = make(map[uint64]int)
+		}
+		im.parents[imported.ID] = line
+	}
+	return err
+}
+
+// readCalendar импортирует VTODO и пропускает остальные компоненты (VEVENT, VTIMEZONE, ...).
+func (im *importer) readCalendar(body io.Reader) {
+	dec := ical.NewDecoder(body)
+	for {
+		c, err := dec.Next()
+		if err == io.EOF {
+			return
+		}
+
+		var syntax *ical.SyntaxError
+		if errors.As(err, &syntax) {
+			// после синтаксической ошибки границы компонентов неизвестны, продолжать нельзя
+			im.abort(syntax.Line, validation.Errors{{Code: validation.CodeInvalidValue, Message: "invalid iCalendar: " + syntax.Msg}})
+			return
+		}
+		if err != nil {
+			im.abort(0, bodyError(err))
+			return
+		}
+
+		if c.Name != "VTODO" {
+			continue
+		}
+
+		task, err := taskFromVTODO(c, im.preserveID)
+		if err == nil {
+			err = im.importTask(c.Line, task)
+		}
+		if !im.add(c.Line, err) {
+			return
+		}
+	}
+}
+
+// readMarkdown создаёт задачи из пунктов чек-листа по порядку, так что родитель
+// сохраняется раньше подзадач. Подзадачи родителя, который не удалось импортировать,
+// попадают на верхний уровень.
+func (im *importer) readMarkdown(body *bufio.Reader) {
+	// номер строки пункта → ID созданной задачи
+	created := make(map[int]uint64)
+	checklist := newChecklistReader(body)
+	for {
+		item, err := checklist.Next()
+		switch {
+		case err == io.EOF:
+			return
+		case errors.Is(err, errLineTooLong):
+			im.fail(item.Line, err)
+			continue
+		case err != nil:
+			im.abort(item.Line, bodyError(err))
+			return
+		}
+
+		item.Task.ParentID = created[item.Parent]
+		task, err := im.h.service.Import(im.r.Context(), item.Task, false)
+		if err == nil {
+			created[item.Line] = task.ID
+		}
+		if !im.add(item.Line, err) {
+			return
+		}
+	}
+}
+
+// resolveParents снимает ссылки на родителей, которых не оказалось ни в хранилище,
+// ни в импорте, и добавляет эти задачи в отчёт.
+func (im *importer) resolveParents() {
+	if len(im.parents) == 0 || im.status >= http.StatusInternalServerError {
+		return
+	}
+
+	ids := slices.Sorted(maps.Keys(im.parents))
+	detached, err := im.h.service.DetachUnresolvedParents(im.r.Context(), ids)
+	if err != nil {
+		im.abort(0, err)
+		return
+	}
+
+	for _, id := range ids {
+		if err, ok := detached[id]; ok {
+			im.report.Detached++
+			im.report.Errors = appendLineError(im.report.Errors, lineError(im.parents[id], im.h.problems.Resolve(err)))
+		}
+	}
+	slices.SortStableFunc(im.report.Errors, func(a, b dto.ImportLineError) int { return cmp.Compare(a.Line, b.Line) })
+}
+
+// add учитывает результат импорта одной задачи и сообщает, можно ли продолжать.
+func (im *importer) add(line int, err error) bool {
+	if err == nil {
+		im.report.Imported++
+		return true
+	}
+
+	// сбой хранилища повторится и на следующих задачах
+	p := im.fail(line, err)
+	if p.Status >= http.StatusInternalServerError {
+		slog.ErrorContext(im.r.Context(), "task import aborted", logging.Err(err), "line", line)
+		im.status = p.Status
+		return false
+	}
+	return true
+}
+
+// abort записывает ошибку, после которой тело дальше не прочитать.
+func (im *importer) abort(line int, err error) {
+	p := im.fail(line, err)
+	if p.Status >= http.StatusInternalServerError {
+		slog.ErrorContext(im.r.Context(), "task import aborted", logging.Err(err), "line", line)
+	}
+	im.status = p.Status
+}
+
+func (im *importer) fail(line int, err error) problem.Problem {
+	p := im.h.problems.Resolve(err)
+	im.report.Failed++
+	im.report.Errors = appendLineError(im.report.Errors, lineError(line, p))
+	return p
+}
+
+func appendLineError(errs []dto.ImportLineError, e dto.ImportLineError) []dto.ImportLineError {
+	if len(errs) < maxImportErrors {
+		errs = append(errs, e)
+	}
+	return errs
+}
+
+func bodyError(err error) error {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return errBodyTooLarge
+	}
+	return fmt.Errorf("%w: %w", errInvalidBody, err)
+}
+
+func lineError(line int, p problem.Problem) dto.ImportLineError {
+	return dto.ImportLineError{
+		Line:   line,
+		Type:   p.Type,
+		Title:  p.Title,
+		Status: p.Status,
+		Detail: p.Detail,
+		Errors: p.Errors,
+	}
+}
+
+// readLine читает строку без завершающего перевода строки. Строка длиннее limit
+// дочитывается и отбрасывается, чтобы импорт продолжился со следующей.
+func readLine(r *bufio.Reader, limit int) (line []byte, tooLong bool, err error) {
+	for {
+		chunk, err := r.ReadSlice('\n')
+		if !tooLong {
+			if len(line)+len(chunk) > limit+len("\r\n") {
+				line, tooLong = nil, true
+			} else {
+				line = append(line, chunk...)
+			}
+		}
+		if err == bufio.ErrBufferFull {
+			continue
+		}
+
+		return bytes.TrimRight(line, "\r\n"), tooLong, err
+	}
+}
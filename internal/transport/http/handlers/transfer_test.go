@@ -0,0 +1,530 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Ant-Tab-Shift/todos-service/internal/domain"
+	"github.com/Ant-Tab-Shift/todos-service/internal/infrastructure/storage"
+	"github.com/Ant-Tab-Shift/todos-service/internal/transport/http/dto"
+	"github.com/Ant-Tab-Shift/todos-service/internal/usecases"
+)
+
+func newTransferHandler(t *testing.T) (http.Handler, *usecases.TaskService) {
+	t.Helper()
+
+	service := usecases.NewTaskService(storage.NewInMemory[domain.TaskSchema](), nil)
+	mux := http.NewServeMux()
+	for _, endpoint := range NewTaskHandler(service).Handlers() {
+		mux.HandleFunc(endpoint.Pattern, endpoint.Func)
+	}
+
+	return mux, service
+}
+
+func importTasks(t *testing.T, handler http.Handler, query, body string) (int, dto.ImportResponse) {
+	t.Helper()
+	return importTasksAs(t, handler, "", query, body)
+}
+
+func importTasksAs(t *testing.T, handler http.Handler, contentType, query, body string) (int, dto.ImportResponse) {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodPost, "/todos/import"+query, strings.NewReader(body))
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	var report dto.ImportResponse
+	if rec.Header().Get("Content-Type") == "application/json" {
+		if err := json.Unmarshal(rec.Body.Bytes(), &report); err != nil {
+			t.Fatalf("failed to decode import report: %v", err)
+		}
+	}
+	return rec.Code, report
+}
+
+func exportTasks(t *testing.T, handler http.Handler) string {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/todos/export", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("export status = %d, want 200", rec.Code)
+	}
+	if got := rec.Header().Get("Content-Type"); got != mediaNDJSON {
+		t.Errorf("Content-Type = %q, want %q", got, mediaNDJSON)
+	}
+	return rec.Body.String()
+}
+
+func exportTasksAs(handler http.Handler, query, accept string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/todos/export"+query, nil)
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestTaskHandler_Export(t *testing.T) {
+	handler, service := newTransferHandler(t)
+	ctx := context.Background()
+	for _, title := range []string{"first", "second", "third"} {
+		if _, err := service.Create(ctx, domain.TaskSchema{Title: title}); err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+	}
+	if err := service.Delete(ctx, 2); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+
+	want := `{"id":1,"title":"first","description":"","is_done":false,"priority":"normal"}` + "\n" +
+		`{"id":3,"title":"third","description":"","is_done":false,"priority":"normal"}` + "\n"
+	if got := exportTasks(t, handler); got != want {
+		t.Errorf("export = %q, want %q", got, want)
+	}
+}
+
+func TestTaskHandler_Import_ReportsLineErrors(t *testing.T) {
+	handler, service := newTransferHandler(t)
+
+	body := strings.Join([]string{
+		`{"id": 40, "title": "Write report"}`,
+		`{"title": "broken"`,
+		`{"title": "Call Bob", "owner": "me"}`,
+		`{"title": "  "}`,
+		``,
+		`{"title": "Pay rent", "is_done": true, "priority": "high", "description": "by Friday"}`,
+	}, "\n")
+
+	status, report := importTasks(t, handler, "", body)
+	if status != http.StatusOK {
+		t.Fatalf("status = %d, want 200", status)
+	}
+	if report.Imported != 2 || report.Failed != 3 {
+		t.Errorf("imported = %d, failed = %d, want 2 and 3", report.Imported, report.Failed)
+	}
+
+	wantLines := []struct {
+		line   int
+		status int
+		field  string
+	}{
+		{2, http.StatusBadRequest, ""},
+		{3, http.StatusBadRequest, "owner"},
+		{4, http.StatusBadRequest, "title"},
+	}
+	if len(report.Errors) != len(wantLines) {
+		t.Fatalf("errors = %+v, want %d entries", report.Errors, len(wantLines))
+	}
+	for i, want := range wantLines {
+		got := report.Errors[i]
+		if got.Line != want.line || got.Status != want.status || len(got.Errors) == 0 || got.Errors[0].Field != want.field {
+			t.Errorf("errors[%d] = %+v, want line %d, status %d, field %q", i, got, want.line, want.status, want.field)
+		}
+	}
+
+	// без ids=preserve ID из строки игнорируется, а статус задачи сохраняется
+	tasks, _ := service.GetAll(context.Background())
+	if len(tasks) != 2 {
+		t.Fatalf("stored %d tasks, want 2", len(tasks))
+	}
+	if task, err := service.GetByID(context.Background(), 2); err != nil || task.Title != "Pay rent" || !task.IsDone {
+		t.Errorf("task 2 = %+v, %v, want done Pay rent", task, err)
+	}
+}
+
+func TestTaskHandler_Import_PreservesIDs(t *testing.T) {
+	handler, service := newTransferHandler(t)
+
+	body := `{"id": 5, "title": "five"}
+{"id": 10, "title": "ten", "is_done": true}
+{"id": 5, "title": "again"}
+{"title": "no id"}
+`
+	status, report := importTasks(t, handler, "?ids=preserve", body)
+	if status != http.StatusOK {
+		t.Fatalf("status = %d, want 200", status)
+	}
+	if report.Imported != 2 || report.Failed != 2 {
+		t.Fatalf("report = %+v, want 2 imported and 2 failed", report)
+	}
+	if got := report.Errors[0]; got.Line != 3 || got.Status != http.StatusConflict {
+		t.Errorf("errors[0] = %+v, want conflict on line 3", got)
+	}
+	if got := report.Errors[1]; got.Line != 4 || len(got.Errors) != 1 || got.Errors[0].Field != "id" {
+		t.Errorf("errors[1] = %+v, want missing id on line 4", got)
+	}
+
+	// новые задачи не должны занять импортированные ID
+	created, err := service.Create(context.Background(), domain.TaskSchema{Title: "new"})
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if created.ID != 11 {
+		t.Errorf("created.ID = %d, want 11", created.ID)
+	}
+}
+
+func TestTaskHandler_Import_DetachesUnresolvedParents(t *testing.T) {
+	handler, service := newTransferHandler(t)
+	ctx := context.Background()
+	service.Import(ctx, domain.Task{ID: 1, TaskSchema: domain.TaskSchema{Title: "stored"}}, true)
+
+	// 2 ссылается на задачу, пришедшую позже, 4 — на отсутствующую, 5 и 6 — друг на друга
+	body := `{"id": 2, "title": "child", "parent_id": 3}
+{"id": 3, "title": "parent", "parent_id": 1}
+{"id": 4, "title": "orphan", "parent_id": 9}
+{"id": 5, "title": "cycle a", "parent_id": 6}
+{"id": 6, "title": "cycle b", "parent_id": 5}
+`
+	status, report := importTasks(t, handler, "?ids=preserve", body)
+	if status != http.StatusOK {
+		t.Fatalf("status = %d, want 200", status)
+	}
+	if report.Imported != 5 || report.Failed != 0 || report.Detached != 2 {
+		t.Fatalf("report = %+v, want 5 imported and 2 detached", report)
+	}
+	var lines []int
+	for _, e := range report.Errors {
+		lines = append(lines, e.Line)
+		if len(e.Errors) != 1 || e.Errors[0].Field != "parent_id" {
+			t.Errorf("error = %+v, want parent_id", e)
+		}
+	}
+	if !reflect.DeepEqual(lines, []int{3, 4}) {
+		t.Errorf("error lines = %v, want [3 4]", lines)
+	}
+
+	want := map[uint64]uint64{2: 3, 3: 1, 4: 0, 5: 0, 6: 5}
+	for id, parent := range want {
+		task, _ := service.GetByID(ctx, id)
+		if task.ParentID != parent {
+			t.Errorf("task %d parent = %d, want %d", id, task.ParentID, parent)
+		}
+	}
+}
+
+func TestTaskHandler_Import_SkipsTooLongLine(t *testing.T) {
+	handler, _ := newTransferHandler(t)
+
+	body := `{"title": "` + strings.Repeat("a", maxBodySize) + `"}` + "\n" + `{"title": "short"}`
+	status, report := importTasks(t, handler, "", body)
+	if status != http.StatusOK {
+		t.Fatalf("status = %d, want 200", status)
+	}
+	if report.Imported != 1 || report.Failed != 1 || report.Errors[0].Line != 1 || report.Errors[0].Status != http.StatusRequestEntityTooLarge {
+		t.Errorf("report = %+v, want line 1 rejected as too long and line 2 imported", report)
+	}
+}
+
+func TestTaskHandler_Import_InvalidIDsMode(t *testing.T) {
+	handler, _ := newTransferHandler(t)
+
+	status, _ := importTasks(t, handler, "?ids=keep", `{"title": "task"}`)
+	if status != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400", status)
+	}
+}
+
+func TestTaskHandler_ExportImportRoundTrip(t *testing.T) {
+	source, service := newTransferHandler(t)
+	ctx := context.Background()
+	for i, title := range []string{"one", "two", "three"} {
+		task, err := service.Create(ctx, domain.TaskSchema{Title: title, Description: "d", Priority: domain.PriorityLow})
+		if err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+		if i == 2 {
+			service.Update(ctx, task.ID, domain.TaskSchema{Title: title, DueDate: time.Date(2026, time.May, 9, 18, 30, 0, 0, time.UTC)})
+		}
+		if i == 1 {
+			service.Update(ctx, task.ID, domain.TaskSchema{Title: title, IsDone: true})
+		}
+	}
+	service.Delete(ctx, 1)
+
+	exported := exportTasks(t, source)
+	if !strings.Contains(exported, `"due_date":"2026-05-09T18:30:00Z"`) {
+		t.Errorf("export = %q, want due_date of task 3", exported)
+	}
+
+	target, _ := newTransferHandler(t)
+	if status, report := importTasks(t, target, "?ids=preserve", exported); status != http.StatusOK || report.Failed != 0 {
+		t.Fatalf("import status = %d, report = %+v", status, report)
+	}
+	if got := exportTasks(t, target); got != exported {
+		t.Errorf("round trip changed tasks:\n got %q\nwant %q", got, exported)
+	}
+}
+
+func TestTaskHandler_Export_Formats(t *testing.T) {
+	handler, service := newTransferHandler(t)
+	ctx := context.Background()
+	due := time.Date(2026, time.March, 1, 0, 0, 0, 0, time.UTC)
+	if _, err := service.Create(ctx, domain.TaskSchema{Title: "=SUM(A1)", Description: "a, b; c\nd", Priority: domain.PriorityHigh, DueDate: due}); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if err := service.Update(ctx, 1, domain.TaskSchema{Title: "=SUM(A1)", Description: "a, b; c\nd", IsDone: true, DueDate: due.Add(90 * time.Minute)}); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+
+	tests := []struct {
+		name            string
+		query, accept   string
+		wantStatus      int
+		wantContentType string
+		wantFilename    string
+		wantBody        []string
+	}{
+		{
+			name:            "csv by format",
+			query:           "?format=csv",
+			wantStatus:      http.StatusOK,
+			wantContentType: "text/csv; charset=utf-8; header=present",
+			wantFilename:    "todos.csv",
+			wantBody: []string{
+				"id,title,description,is_done,priority,due_date\r\n" +
+					"1,'=SUM(A1),\"a, b; c\r\nd\",true,high,2026-03-01T01:30:00Z\r\n",
+			},
+		},
+		{
+			name:            "ics by accept",
+			accept:          "text/calendar",
+			wantStatus:      http.StatusOK,
+			wantContentType: "text/calendar; charset=utf-8",
+			wantFilename:    "todos.ics",
+			wantBody: []string{
+				"BEGIN:VCALENDAR\r\nVERSION:2.0\r\n",
+				"BEGIN:VTODO\r\nUID:1@todos-service\r\n",
+				"SUMMARY:=SUM(A1)\r\nDESCRIPTION:a\\, b\\; c\\nd\r\nPRIORITY:1\r\nSTATUS:COMPLETED\r\nDUE:20260301T013000Z\r\nEND:VTODO\r\n",
+				"END:VCALENDAR\r\n",
+			},
+		},
+		{
+			name:            "format wins over accept",
+			query:           "?format=ndjson",
+			accept:          "text/csv",
+			wantStatus:      http.StatusOK,
+			wantContentType: mediaNDJSON,
+			wantFilename:    "todos.ndjson",
+		},
+		{
+			name:            "unknown format",
+			query:           "?format=xlsx",
+			wantStatus:      http.StatusBadRequest,
+			wantContentType: "application/problem+json",
+		},
+		{
+			name:            "unsupported accept",
+			accept:          "application/json",
+			wantStatus:      http.StatusNotAcceptable,
+			wantContentType: "application/problem+json",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := exportTasksAs(handler, tt.query, tt.accept)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if got := rec.Header().Get("Content-Type"); got != tt.wantContentType {
+				t.Errorf("Content-Type = %q, want %q", got, tt.wantContentType)
+			}
+			if got := rec.Header().Get("Vary"); got != "Accept" {
+				t.Errorf("Vary = %q, want Accept", got)
+			}
+			if tt.wantFilename != "" && !strings.Contains(rec.Header().Get("Content-Disposition"), tt.wantFilename) {
+				t.Errorf("Content-Disposition = %q, want filename %s", rec.Header().Get("Content-Disposition"), tt.wantFilename)
+			}
+			for _, part := range tt.wantBody {
+				if !strings.Contains(rec.Body.String(), part) {
+					t.Errorf("body = %q, want it to contain %q", rec.Body.String(), part)
+				}
+			}
+		})
+	}
+}
+
+func TestTaskHandler_Import_Calendar(t *testing.T) {
+	handler, service := newTransferHandler(t)
+
+	body := "BEGIN:VCALENDAR\r\n" +
+		"VERSION:2.0\r\n" +
+		"PRODID:-//Example//EN\r\n" +
+		"BEGIN:VTIMEZONE\r\nTZID:Europe/Moscow\r\nEND:VTIMEZONE\r\n" +
+		"BEGIN:VTODO\r\n" +
+		"UID:7@todos-service\r\n" +
+		"SUMMARY:Pay rent\\, water\r\n" +
+		"DESCRIPTION:first line\\nsecond line that is long enough to be folded by\r\n  the calendar client\r\n" +
+		"PRIORITY:2\r\n" +
+		"STATUS:COMPLETED\r\n" +
+		"DUE;VALUE=DATE:20260301\r\n" +
+		"BEGIN:VALARM\r\nACTION:DISPLAY\r\nTRIGGER:-PT15M\r\nEND:VALARM\r\n" +
+		"END:VTODO\r\n" +
+		"BEGIN:VEVENT\r\nUID:event\r\nSUMMARY:Not a task\r\nEND:VEVENT\r\n" +
+		"BEGIN:VTODO\r\n" +
+		"UID:other@example.com\r\n" +
+		"SUMMARY:Call Bob\r\n" +
+		"DUE;TZID=Europe/Moscow:20260301T120000\r\n" +
+		"END:VTODO\r\n" +
+		"BEGIN:VTODO\r\n" +
+		"SUMMARY:Broken\r\n" +
+		"PRIORITY:high\r\n" +
+		"DUE:tomorrow\r\n" +
+		"END:VTODO\r\n" +
+		"BEGIN:VTODO\r\n" +
+		"DESCRIPTION:no summary\r\n" +
+		"END:VTODO\r\n" +
+		"END:VCALENDAR\r\n"
+
+	status, report := importTasksAs(t, handler, "text/calendar; charset=utf-8", "", body)
+	if status != http.StatusOK {
+		t.Fatalf("status = %d, want 200", status)
+	}
+	if report.Imported != 2 || report.Failed != 2 {
+		t.Fatalf("report = %+v, want 2 imported and 2 failed", report)
+	}
+	if got := report.Errors[0]; got.Line != 29 || len(got.Errors) != 2 || got.Errors[0].Field != "PRIORITY" || got.Errors[1].Field != "DUE" {
+		t.Errorf("errors[0] = %+v, want PRIORITY and DUE errors at line 29", got)
+	}
+	if got := report.Errors[1]; got.Line != 34 || len(got.Errors) == 0 || got.Errors[0].Field != "title" {
+		t.Errorf("errors[1] = %+v, want blank title at line 34", got)
+	}
+
+	first, err := service.GetByID(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("GetByID failed: %v", err)
+	}
+	want := domain.TaskSchema{
+		Title:       "Pay rent, water",
+		Description: "first line\nsecond line that is long enough to be folded by the calendar client",
+		IsDone:      true,
+		Priority:    domain.PriorityHigh,
+		DueDate:     time.Date(2026, time.March, 1, 0, 0, 0, 0, time.UTC),
+	}
+	if !reflect.DeepEqual(first.TaskSchema, want) {
+		t.Errorf("task 1 = %+v, want %+v", first.TaskSchema, want)
+	}
+
+	second, err := service.GetByID(context.Background(), 2)
+	if err != nil {
+		t.Fatalf("GetByID failed: %v", err)
+	}
+	if second.IsDone || second.Priority != domain.PriorityNormal || !second.DueDate.Equal(time.Date(2026, time.March, 1, 9, 0, 0, 0, time.UTC)) {
+		t.Errorf("task 2 = %+v, want open normal task due 09:00 UTC", second)
+	}
+}
+
+func TestTaskHandler_Import_CalendarSyntaxError(t *testing.T) {
+	handler, _ := newTransferHandler(t)
+
+	body := "BEGIN:VCALENDAR\r\n" +
+		"BEGIN:VTODO\r\nSUMMARY:ok\r\nEND:VTODO\r\n" +
+		"BEGIN:VTODO\r\nSUMMARY without colon\r\nEND:VTODO\r\n" +
+		"END:VCALENDAR\r\n"
+
+	status, report := importTasksAs(t, handler, "text/calendar", "", body)
+	if status != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400", status)
+	}
+	if report.Imported != 1 || report.Failed != 1 || report.Errors[0].Line != 6 {
+		t.Errorf("report = %+v, want 1 imported and a syntax error at line 6", report)
+	}
+}
+
+func TestTaskHandler_CalendarRoundTrip(t *testing.T) {
+	source, service := newTransferHandler(t)
+	ctx := context.Background()
+	tasks := []domain.TaskSchema{
+		{Title: "date only", Priority: domain.PriorityLow, DueDate: time.Date(2026, time.May, 9, 0, 0, 0, 0, time.UTC)},
+		{Title: "with time", Description: strings.Repeat("длинное описание, ", 10), Priority: domain.PriorityHigh, DueDate: time.Date(2026, time.May, 9, 18, 30, 0, 0, time.UTC)},
+		{Title: "no due; done", Priority: domain.PriorityNormal, IsDone: true},
+	}
+	for _, task := range tasks {
+		if _, err := service.Import(ctx, domain.Task{TaskSchema: task}, false); err != nil {
+			t.Fatalf("Import failed: %v", err)
+		}
+	}
+
+	rec := exportTasksAs(source, "?format=ics", "")
+	for _, line := range strings.Split(rec.Body.String(), "\r\n") {
+		if len(line) > 75 {
+			t.Errorf("line %q is %d octets, want at most 75", line, len(line))
+		}
+	}
+
+	target, imported := newTransferHandler(t)
+	if status, report := importTasksAs(t, target, "text/calendar", "?ids=preserve", rec.Body.String()); status != http.StatusOK || report.Failed != 0 {
+		t.Fatalf("import status = %d, report = %+v", status, report)
+	}
+	for i, want := range tasks {
+		got, err := imported.GetByID(ctx, uint64(i+1))
+		if err != nil || !reflect.DeepEqual(got.TaskSchema, want) {
+			t.Errorf("task %d = %+v, %v, want %+v", i+1, got.TaskSchema, err, want)
+		}
+	}
+}
+
+func TestTaskHandler_MarkdownRoundTrip(t *testing.T) {
+	handler, service := newTransferHandler(t)
+
+	body := "# Release\n" +
+		"- [ ] Prepare notes\n" +
+		"  - [x] Collect changes\n" +
+		"  - [ ]\n" +
+		"    - [ ] Orphaned by a blank title\n" +
+		"## QA\n" +
+		"- [ ] Smoke test\n"
+
+	if status, _ := importTasksAs(t, handler, "text/markdown", "?ids=preserve", body); status != http.StatusBadRequest {
+		t.Errorf("ids=preserve status = %d, want 400", status)
+	}
+
+	status, report := importTasksAs(t, handler, "text/markdown; charset=utf-8", "", body)
+	if status != http.StatusOK {
+		t.Fatalf("status = %d, want 200", status)
+	}
+	if report.Imported != 4 || report.Failed != 1 || report.Errors[0].Line != 4 {
+		t.Fatalf("report = %+v, want 4 imported and a blank title at line 4", report)
+	}
+
+	ctx := context.Background()
+	wantTasks := []domain.TaskSchema{
+		{Title: "Prepare notes", Priority: domain.PriorityNormal, Tags: []string{"Release"}},
+		{Title: "Collect changes", IsDone: true, Priority: domain.PriorityNormal, Tags: []string{"Release"}, ParentID: 1},
+		{Title: "Orphaned by a blank title", Priority: domain.PriorityNormal, Tags: []string{"Release"}},
+		{Title: "Smoke test", Priority: domain.PriorityNormal, Tags: []string{"Release", "QA"}},
+	}
+	for i, want := range wantTasks {
+		got, err := service.GetByID(ctx, uint64(i+1))
+		if err != nil || !reflect.DeepEqual(got.TaskSchema, want) {
+			t.Errorf("task %d = %+v, %v, want %+v", i+1, got.TaskSchema, err, want)
+		}
+	}
+
+	rec := exportTasksAs(handler, "?format=md", "")
+	if got := rec.Header().Get("Content-Type"); got != "text/markdown; charset=utf-8; variant=GFM" {
+		t.Errorf("Content-Type = %q", got)
+	}
+	want := "# Release\n\n" +
+		"- [ ] Prepare notes\n" +
+		"  - [x] Collect changes\n" +
+		"- [ ] Orphaned by a blank title\n" +
+		"\n## QA\n\n" +
+		"- [ ] Smoke test\n"
+	if rec.Body.String() != want {
+		t.Errorf("export =\n%s\nwant\n%s", rec.Body.String(), want)
+	}
+}
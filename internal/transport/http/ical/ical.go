@@ -0,0 +1,342 @@
+// Package ical читает и пишет iCalendar (RFC 5545) на уровне строк содержимого
+// и компонентов; смысл свойств остаётся вызывающему коду.
+package ical
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// maxLineOctets — предел длины строки без CRLF, после которого строка переносится (RFC 5545, 3.1).
+	maxLineOctets = 75
+	// maxLineLength ограничивает строку после склейки переносов.
+	maxLineLength = 1 << 20
+	// maxDepth ограничивает вложенность компонентов внутри VCALENDAR (VTODO → VALARM — это 2),
+	// чтобы файл из одних BEGIN не исчерпал стек.
+	maxDepth = 8
+)
+
+type Param struct {
+	Name  string
+	Value string
+}
+
+type Property struct {
+	Name   string
+	Params []Param
+	// Value хранится как в файле; текстовые значения раскрываются через UnescapeText.
+	Value string
+}
+
+// Param возвращает значение параметра name без учёта регистра.
+func (p Property) Param(name string) (string, bool) {
+	for _, param := range p.Params {
+		if strings.EqualFold(param.Name, name) {
+			return param.Value, true
+		}
+	}
+	return "", false
+}
+
+type Component struct {
+	Name       string
+	Properties []Property
+	Components []*Component
+	// Line — номер строки с BEGIN, чтобы ошибки можно было сопоставить с файлом.
+	Line int
+}
+
+// Property возвращает первое свойство name без учёта регистра.
+func (c *Component) Property(name string) (Property, bool) {
+	for _, p := range c.Properties {
+		if strings.EqualFold(p.Name, name) {
+			return p, true
+		}
+	}
+	return Property{}, false
+}
+
+type SyntaxError struct {
+	Line int
+	Msg  string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Msg)
+}
+
+var textEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`, "\r", `\n`)
+
+// EscapeText экранирует значение типа TEXT (RFC 5545, 3.3.11).
+func EscapeText(s string) string {
+	return textEscaper.Replace(s)
+}
+
+// UnescapeText раскрывает экранирование значения типа TEXT.
+func UnescapeText(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i == len(s)-1 {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'n', 'N':
+			b.WriteByte('\n')
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
+
+// Writer пишет строки содержимого с CRLF и переносом длинных строк.
+type Writer struct {
+	w *bufio.Writer
+}
+
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: bufio.NewWriter(w)}
+}
+
+func (w *Writer) Begin(name string) error {
+	return w.WriteProperty(Property{Name: "BEGIN", Value: name})
+}
+
+func (w *Writer) End(name string) error {
+	return w.WriteProperty(Property{Name: "END", Value: name})
+}
+
+// WriteProperty пишет свойство; текстовое значение должно быть уже экранировано EscapeText.
+func (w *Writer) WriteProperty(p Property) error {
+	var b strings.Builder
+	b.WriteString(p.Name)
+	for _, param := range p.Params {
+		b.WriteString(";" + param.Name + "=")
+		if strings.ContainsAny(param.Value, ":;,") {
+			b.WriteString(`"` + param.Value + `"`)
+		} else {
+			b.WriteString(param.Value)
+		}
+	}
+	b.WriteString(":" + p.Value)
+
+	return w.writeFolded(b.String())
+}
+
+func (w *Writer) Flush() error {
+	return w.w.Flush()
+}
+
+// writeFolded делит строку на части не длиннее 75 октетов, не разрывая символы UTF-8;
+// каждая следующая часть начинается с пробела, который входит в её длину.
+func (w *Writer) writeFolded(line string) error {
+	limit := maxLineOctets
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		if _, err := w.w.WriteString(line[:cut] + "\r\n "); err != nil {
+			return err
+		}
+		line = line[cut:]
+		limit = maxLineOctets - 1
+	}
+
+	_, err := w.w.WriteString(line + "\r\n")
+	return err
+}
+
+// Decoder читает компоненты из потока iCalendar, не загружая файл целиком.
+type Decoder struct {
+	r    *bufio.Reader
+	line int
+	// inCalendar — внутри ли VCALENDAR; в одном потоке их может быть несколько
+	inCalendar bool
+}
+
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{r: bufio.NewReader(r)}
+}
+
+// Next возвращает следующий компонент, вложенный прямо в VCALENDAR (VTODO, VEVENT,
+// VTIMEZONE, ...), вместе с его подкомпонентами. В конце потока возвращает io.EOF.
+func (d *Decoder) Next() (*Component, error) {
+	for {
+		p, line, err := d.readProperty()
+		if err == io.EOF {
+			if d.inCalendar {
+				return nil, &SyntaxError{Line: d.line, Msg: "unexpected end of file, want END:VCALENDAR"}
+			}
+			return nil, io.EOF
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case !d.inCalendar:
+			if p.Name != "BEGIN" || !strings.EqualFold(p.Value, "VCALENDAR") {
+				return nil, &SyntaxError{Line: line, Msg: "want BEGIN:VCALENDAR"}
+			}
+			d.inCalendar = true
+		case p.Name == "END":
+			if !strings.EqualFold(p.Value, "VCALENDAR") {
+				return nil, &SyntaxError{Line: line, Msg: fmt.Sprintf("unexpected END:%s", p.Value)}
+			}
+			d.inCalendar = false
+		case p.Name == "BEGIN":
+			return d.readComponent(strings.ToUpper(p.Value), line, 1)
+		}
+		// свойства самого календаря (VERSION, PRODID) вызывающему коду не нужны
+	}
+}
+
+func (d *Decoder) readComponent(name string, line, depth int) (*Component, error) {
+	if depth > maxDepth {
+		return nil, &SyntaxError{Line: line, Msg: fmt.Sprintf("components nested deeper than %d levels", maxDepth)}
+	}
+
+	c := &Component{Name: name, Line: line}
+	for {
+		p, pline, err := d.readProperty()
+		if err == io.EOF {
+			return nil, &SyntaxError{Line: d.line, Msg: fmt.Sprintf("unexpected end of file, want END:%s", name)}
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch p.Name {
+		case "BEGIN":
+			child, err := d.readComponent(strings.ToUpper(p.Value), pline, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			c.Components = append(c.Components, child)
+		case "END":
+			if !strings.EqualFold(p.Value, name) {
+				return nil, &SyntaxError{Line: pline, Msg: fmt.Sprintf("unexpected END:%s, want END:%s", p.Value, name)}
+			}
+			return c, nil
+		default:
+			c.Properties = append(c.Properties, p)
+		}
+	}
+}
+
+// readProperty читает одну строку содержимого, склеивая перенесённые части.
+func (d *Decoder) readProperty() (Property, int, error) {
+	for {
+		raw, err := d.readPhysical()
+		if err != nil {
+			return Property{}, 0, err
+		}
+		start := d.line
+
+		for {
+			next, err := d.r.Peek(1)
+			if err != nil || (next[0] != ' ' && next[0] != '\t') {
+				break
+			}
+			d.r.Discard(1)
+
+			cont, err := d.readPhysical()
+			if err != nil && err != io.EOF {
+				return Property{}, 0, err
+			}
+			if len(raw)+len(cont) > maxLineLength {
+				return Property{}, 0, &SyntaxError{Line: start, Msg: "content line too long"}
+			}
+			raw += cont
+		}
+
+		// пустые строки RFC не допускает, но их оставляют многие генераторы
+		if raw == "" {
+			continue
+		}
+
+		p, err := parseProperty(raw)
+		if err != nil {
+			return Property{}, 0, &SyntaxError{Line: start, Msg: err.Error()}
+		}
+		return p, start, nil
+	}
+}
+
+// readPhysical читает физическую строку без CRLF.
+func (d *Decoder) readPhysical() (string, error) {
+	var b []byte
+	for {
+		chunk, err := d.r.ReadSlice('\n')
+		if len(b)+len(chunk) > maxLineLength+len("\r\n") {
+			return "", &SyntaxError{Line: d.line + 1, Msg: "content line too long"}
+		}
+		b = append(b, chunk...)
+		if err == bufio.ErrBufferFull {
+			continue
+		}
+		if err == io.EOF && len(b) > 0 {
+			err = nil
+		}
+		if err != nil {
+			return "", err
+		}
+
+		d.line++
+		return strings.TrimRight(string(b), "\r\n"), nil
+	}
+}
+
+// parseProperty разбирает строку вида NAME;PARAM=value;PARAM="a:b":VALUE.
+func parseProperty(line string) (Property, error) {
+	end := strings.IndexAny(line, ";:")
+	if end <= 0 {
+		return Property{}, errors.New("want NAME:VALUE")
+	}
+	p := Property{Name: strings.ToUpper(line[:end])}
+
+	rest := line[end:]
+	for rest[0] == ';' {
+		rest = rest[1:]
+		eq := strings.IndexByte(rest, '=')
+		if eq <= 0 {
+			return Property{}, fmt.Errorf("invalid parameter in %s", p.Name)
+		}
+		param := Param{Name: strings.ToUpper(rest[:eq])}
+		rest = rest[eq+1:]
+
+		if strings.HasPrefix(rest, `"`) {
+			closing := strings.IndexByte(rest[1:], '"')
+			if closing < 0 {
+				return Property{}, fmt.Errorf("unterminated quoted parameter %s", param.Name)
+			}
+			param.Value, rest = rest[1:closing+1], rest[closing+2:]
+		} else {
+			stop := strings.IndexAny(rest, ";:")
+			if stop < 0 {
+				return Property{}, fmt.Errorf("missing value of %s", p.Name)
+			}
+			param.Value, rest = rest[:stop], rest[stop:]
+		}
+		p.Params = append(p.Params, param)
+
+		if rest == "" || (rest[0] != ';' && rest[0] != ':') {
+			return Property{}, fmt.Errorf("invalid parameter %s", param.Name)
+		}
+	}
+
+	p.Value = rest[1:]
+	return p, nil
+}
@@ -0,0 +1,166 @@
+package ical
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestEscapeText(t *testing.T) {
+	tests := []struct {
+		text, escaped string
+	}{
+		{"plain", "plain"},
+		{"a, b; c", `a\, b\; c`},
+		{`back\slash`, `back\\slash`},
+		{"two\nlines", `two\nlines`},
+		{"crlf\r\nline", `crlf\nline`},
+	}
+
+	for _, tt := range tests {
+		if got := EscapeText(tt.text); got != tt.escaped {
+			t.Errorf("EscapeText(%q) = %q, want %q", tt.text, got, tt.escaped)
+		}
+		if want := strings.ReplaceAll(tt.text, "\r\n", "\n"); UnescapeText(tt.escaped) != want {
+			t.Errorf("UnescapeText(%q) = %q, want %q", tt.escaped, UnescapeText(tt.escaped), want)
+		}
+	}
+
+	if got := UnescapeText(`upper\Ncase and trailing\`); got != "upper\ncase and trailing\\" {
+		t.Errorf("UnescapeText() = %q", got)
+	}
+}
+
+func TestWriter_Folds(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	value := strings.Repeat("задача ", 30)
+	if err := w.WriteProperty(Property{Name: "SUMMARY", Params: []Param{{Name: "ALTREP", Value: "cid:part1"}, {Name: "LANGUAGE", Value: "ru"}}, Value: value}); err != nil {
+		t.Fatalf("WriteProperty failed: %v", err)
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+
+	out := buf.String()
+	if !strings.HasPrefix(out, `SUMMARY;ALTREP="cid:part1";LANGUAGE=ru:`) || !strings.HasSuffix(out, "\r\n") {
+		t.Fatalf("output = %q", out)
+	}
+	lines := strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n")
+	if len(lines) < 2 {
+		t.Fatalf("got %d lines, want a folded property", len(lines))
+	}
+	for i, line := range lines {
+		if len(line) > maxLineOctets {
+			t.Errorf("line %d is %d octets", i, len(line))
+		}
+		if i > 0 && !strings.HasPrefix(line, " ") {
+			t.Errorf("continuation %d = %q, want leading space", i, line)
+		}
+		if !utf8.ValidString(line) {
+			t.Errorf("line %d splits a UTF-8 sequence: %q", i, line)
+		}
+	}
+
+	// после склейки переносов значение должно совпасть с исходным
+	dec := NewDecoder(strings.NewReader("BEGIN:VCALENDAR\r\nBEGIN:VTODO\r\n" + out + "END:VTODO\r\nEND:VCALENDAR\r\n"))
+	c, err := dec.Next()
+	if err != nil {
+		t.Fatalf("Next failed: %v", err)
+	}
+	p, _ := c.Property("summary")
+	if p.Value != value {
+		t.Errorf("decoded value = %q, want %q", p.Value, value)
+	}
+	if altrep, _ := p.Param("altrep"); altrep != "cid:part1" {
+		t.Errorf("ALTREP = %q, want cid:part1", altrep)
+	}
+}
+
+func TestDecoder(t *testing.T) {
+	input := "BEGIN:VCALENDAR\n" +
+		"VERSION:2.0\n" +
+		"\n" +
+		"BEGIN:VTODO\r\n" +
+		"UID:1@example.com\r\n" +
+		"DESCRIPTION:folded\r\n\t value\r\n" +
+		"BEGIN:VALARM\r\n" +
+		"TRIGGER;RELATED=END:-PT5M\r\n" +
+		"END:VALARM\r\n" +
+		"END:VTODO\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"SUMMARY:event\r\n" +
+		"END:VEVENT\r\n" +
+		"END:VCALENDAR\r\n" +
+		"BEGIN:VCALENDAR\r\n" +
+		"BEGIN:VTODO\r\n" +
+		"SUMMARY:second calendar\r\n" +
+		"END:VTODO\r\n" +
+		"END:VCALENDAR"
+
+	dec := NewDecoder(strings.NewReader(input))
+	var got []*Component
+	for {
+		c, err := dec.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Next failed: %v", err)
+		}
+		got = append(got, c)
+	}
+
+	if len(got) != 3 || got[0].Name != "VTODO" || got[1].Name != "VEVENT" || got[2].Name != "VTODO" {
+		t.Fatalf("components = %+v, want VTODO, VEVENT, VTODO", got)
+	}
+	if got[0].Line != 4 || got[1].Line != 12 || got[2].Line != 17 {
+		t.Errorf("lines = %d, %d, %d, want 4, 12, 17", got[0].Line, got[1].Line, got[2].Line)
+	}
+	if p, _ := got[0].Property("DESCRIPTION"); p.Value != "folded value" {
+		t.Errorf("DESCRIPTION = %q, want \"folded value\"", p.Value)
+	}
+	if len(got[0].Components) != 1 || got[0].Components[0].Name != "VALARM" {
+		t.Fatalf("nested = %+v, want VALARM", got[0].Components)
+	}
+	if related, _ := got[0].Components[0].Properties[0].Param("RELATED"); related != "END" {
+		t.Errorf("RELATED = %q, want END", related)
+	}
+}
+
+func TestDecoder_SyntaxErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		wantLine int
+	}{
+		{"no calendar", "BEGIN:VTODO\r\nEND:VTODO\r\n", 1},
+		{"missing colon", "BEGIN:VCALENDAR\r\nBEGIN:VTODO\r\nSUMMARY\r\nEND:VTODO\r\nEND:VCALENDAR\r\n", 3},
+		{"unterminated quote", "BEGIN:VCALENDAR\r\nBEGIN:VTODO\r\nDUE;TZID=\"Europe/Moscow:20260101\r\nEND:VTODO\r\nEND:VCALENDAR\r\n", 3},
+		{"mismatched end", "BEGIN:VCALENDAR\r\nBEGIN:VTODO\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n", 3},
+		{"truncated component", "BEGIN:VCALENDAR\r\nBEGIN:VTODO\r\nSUMMARY:x\r\n", 3},
+		{"truncated calendar", "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n", 2},
+		{"nested too deep", "BEGIN:VCALENDAR\r\n" + strings.Repeat("BEGIN:A\r\n", 100000), maxDepth + 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dec := NewDecoder(strings.NewReader(tt.input))
+			var err error
+			for err == nil {
+				_, err = dec.Next()
+			}
+
+			var syntax *SyntaxError
+			if !errors.As(err, &syntax) {
+				t.Fatalf("err = %v, want *SyntaxError", err)
+			}
+			if syntax.Line != tt.wantLine {
+				t.Errorf("line = %d, want %d (%v)", syntax.Line, tt.wantLine, err)
+			}
+		})
+	}
+}
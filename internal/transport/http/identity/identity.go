@@ -0,0 +1,16 @@
+package identity
+
+import "context"
+
+type contextKey struct{}
+
+// NewContext сохраняет идентификатор аутентифицированного клиента.
+func NewContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext возвращает идентификатор клиента, если запрос аутентифицирован.
+func FromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(contextKey{}).(string)
+	return id, ok && id != ""
+}
@@ -5,4 +5,21 @@ import "net/http"
 type Endpoint struct {
 	Pattern string
 	Func    http.HandlerFunc
+	Doc     *Doc
+}
+
+type Doc struct {
+	Summary     string
+	Description string
+	Tags        []string
+	PathParams  map[string]any
+	// QueryParams — необязательные параметры строки запроса; тип схемы берётся из значения.
+	QueryParams map[string]any
+	Request     any
+	Responses   map[int]Response
+}
+
+type Response struct {
+	Description string
+	Body        any
 }
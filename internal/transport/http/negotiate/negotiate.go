@@ -0,0 +1,108 @@
+package negotiate
+
+import (
+	"strconv"
+	"strings"
+)
+
+type preference struct {
+	value string
+	q     float64
+}
+
+// parse разбирает список вида "text/csv;q=0.5, application/json" по RFC 9110.
+// Параметры, кроме q, отбрасываются; некорректный q считается нулевым.
+func parse(header string) []preference {
+	var prefs []preference
+	for _, part := range strings.Split(header, ",") {
+		value, params, _ := strings.Cut(part, ";")
+		value = strings.ToLower(strings.TrimSpace(value))
+		if value == "" {
+			continue
+		}
+
+		q := 1.0
+		for _, param := range strings.Split(params, ";") {
+			name, raw, ok := strings.Cut(strings.TrimSpace(param), "=")
+			if !ok || !strings.EqualFold(strings.TrimSpace(name), "q") {
+				continue
+			}
+			parsed, err := strconv.ParseFloat(strings.TrimSpace(raw), 64)
+			if err != nil || parsed < 0 || parsed > 1 {
+				parsed = 0
+			}
+			q = parsed
+		}
+
+		prefs = append(prefs, preference{value: value, q: q})
+	}
+	return prefs
+}
+
+// ContentType выбирает из offers тип с наибольшим q по заголовку Accept;
+// при равных q побеждает тип, стоящий в offers раньше. Пустой Accept разрешает первый тип.
+func ContentType(accept string, offers ...string) (string, bool) {
+	if strings.TrimSpace(accept) == "" {
+		if len(offers) == 0 {
+			return "", false
+		}
+		return offers[0], true
+	}
+
+	prefs := parse(accept)
+	return best(offers, func(offer string) float64 {
+		q, specificity := 0.0, -1
+		for _, p := range prefs {
+			if s := mediaMatch(p.value, offer); s > specificity {
+				q, specificity = p.q, s
+			}
+		}
+		return q
+	})
+}
+
+// mediaMatch возвращает точность совпадения диапазона с типом: 2 — точное,
+// 1 — "type/*", 0 — "*/*", -1 — не совпадает.
+func mediaMatch(mediaRange, offer string) int {
+	switch {
+	case mediaRange == offer:
+		return 2
+	case mediaRange == "*/*":
+		return 0
+	case strings.HasSuffix(mediaRange, "/*") && strings.HasPrefix(offer, strings.TrimSuffix(mediaRange, "*")):
+		return 1
+	default:
+		return -1
+	}
+}
+
+// Encoding выбирает кодирование по Accept-Encoding; пустая строка означает identity.
+func Encoding(acceptEncoding string, offers ...string) string {
+	prefs := parse(acceptEncoding)
+	encoding, _ := best(offers, func(offer string) float64 {
+		wildcard := -1.0
+		for _, p := range prefs {
+			if p.value == offer {
+				return p.q
+			}
+			if p.value == "*" {
+				wildcard = p.q
+			}
+		}
+		return max(wildcard, 0)
+	})
+	return encoding
+}
+
+func best(offers []string, quality func(string) float64) (string, bool) {
+	var (
+		chosen string
+		bestQ  float64
+	)
+	for _, offer := range offers {
+		if q := quality(offer); q > bestQ {
+			chosen, bestQ = offer, q
+		}
+	}
+	return chosen, bestQ > 0
+}
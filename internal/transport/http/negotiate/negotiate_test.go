@@ -0,0 +1,59 @@
+package negotiate
+
+import "testing"
+
+func TestContentType(t *testing.T) {
+	offers := []string{"application/json", "text/csv", "application/x-ndjson"}
+
+	tests := []struct {
+		name   string
+		accept string
+		want   string
+		wantOK bool
+	}{
+		{"empty header", "", "application/json", true},
+		{"exact", "text/csv", "text/csv", true},
+		{"case insensitive", "Text/CSV", "text/csv", true},
+		{"highest q wins", "application/json;q=0.5, application/x-ndjson", "application/x-ndjson", true},
+		{"tie keeps offer order", "text/csv, application/json", "application/json", true},
+		{"type wildcard", "text/*", "text/csv", true},
+		{"any", "*/*", "application/json", true},
+		{"specific overrides wildcard", "*/*, application/json;q=0", "text/csv", true},
+		{"browser default", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8", "application/json", true},
+		{"unsupported", "application/xml", "", false},
+		{"explicitly refused", "text/csv;q=0", "", false},
+		{"invalid q ignored", "text/csv;q=abc", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ContentType(tt.accept, offers...)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("ContentType(%q) = %q, %v; want %q, %v", tt.accept, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestEncoding(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{"", ""},
+		{"gzip", "gzip"},
+		{"deflate", "deflate"},
+		{"gzip, deflate, br", "gzip"},
+		{"deflate, gzip;q=0.5", "deflate"},
+		{"gzip;q=0", ""},
+		{"*", "gzip"},
+		{"*, gzip;q=0", "deflate"},
+		{"identity", ""},
+	}
+
+	for _, tt := range tests {
+		if got := Encoding(tt.header, "gzip", "deflate"); got != tt.want {
+			t.Errorf("Encoding(%q) = %q, want %q", tt.header, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/Ant-Tab-Shift/todos-service/internal/transport/http/models"
+)
+
+type Handler struct {
+	document []byte
+}
+
+func NewHandler(info Info, handlers ...Documented) (*Handler, error) {
+	h := &Handler{}
+
+	spec, err := Generate(info, append(handlers, h)...)
+	if err != nil {
+		return nil, err
+	}
+
+	h.document, err = json.Marshal(spec)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode openapi spec: %w", err)
+	}
+
+	return h, nil
+}
+
+func (h *Handler) Serve(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(h.document)
+}
+
+func (h *Handler) Handlers() []models.Endpoint {
+	return []models.Endpoint{
+		{
+			Pattern: "GET /openapi.json",
+			Func:    h.Serve,
+			Doc: &models.Doc{
+				Summary: "Get the OpenAPI specification",
+				Tags:    []string{"meta"},
+				Responses: map[int]models.Response{
+					http.StatusOK: {Description: "OpenAPI 3 document", Body: map[string]any{}},
+				},
+			},
+		},
+	}
+}
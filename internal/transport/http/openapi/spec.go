@@ -0,0 +1,357 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Ant-Tab-Shift/todos-service/internal/transport/http/models"
+)
+
+const version = "3.0.3"
+
+var pathParamPattern = regexp.MustCompile(`\{([^}.]+)(\.\.\.)?\}`)
+
+type Documented interface {
+	Handlers() []models.Endpoint
+}
+
+type Info struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+type Spec struct {
+	OpenAPI    string                          `json:"openapi"`
+	Info       Info                            `json:"info"`
+	Paths      map[string]map[string]Operation `json:"paths"`
+	Components Components                      `json:"components"`
+}
+
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas"`
+}
+
+type Operation struct {
+	Summary     string              `json:"summary"`
+	Description string              `json:"description,omitempty"`
+	Tags        []string            `json:"tags,omitempty"`
+	Parameters  []Parameter         `json:"parameters,omitempty"`
+	RequestBody *RequestBody        `json:"requestBody,omitempty"`
+	Responses   map[string]Response `json:"responses"`
+}
+
+type Parameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required"`
+	Schema   *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+}
+
+type UndocumentedError struct {
+	Pattern string
+	Reason  string
+}
+
+func (e *UndocumentedError) Error() string {
+	return fmt.Sprintf("endpoint %q is not documented: %s", e.Pattern, e.Reason)
+}
+
+func Generate(info Info, handlers ...Documented) (*Spec, error) {
+	spec := &Spec{
+		OpenAPI:    version,
+		Info:       info,
+		Paths:      make(map[string]map[string]Operation),
+		Components: Components{Schemas: make(map[string]*Schema)},
+	}
+	g := &generator{schemas: spec.Components.Schemas}
+
+	for _, handler := range handlers {
+		for _, endpoint := range handler.Handlers() {
+			method, path, err := splitPattern(endpoint.Pattern)
+			if err != nil {
+				return nil, err
+			}
+			if err = Validate(endpoint); err != nil {
+				return nil, err
+			}
+
+			op, err := g.operation(path, endpoint.Doc)
+			if err != nil {
+				return nil, fmt.Errorf("endpoint %q: %w", endpoint.Pattern, err)
+			}
+
+			if spec.Paths[path] == nil {
+				spec.Paths[path] = make(map[string]Operation)
+			}
+			spec.Paths[path][strings.ToLower(method)] = op
+		}
+	}
+
+	return spec, nil
+}
+
+func Validate(endpoint models.Endpoint) error {
+	switch {
+	case endpoint.Doc == nil:
+		return &UndocumentedError{Pattern: endpoint.Pattern, Reason: "missing Doc"}
+	case strings.TrimSpace(endpoint.Doc.Summary) == "":
+		return &UndocumentedError{Pattern: endpoint.Pattern, Reason: "missing summary"}
+	case len(endpoint.Doc.Responses) == 0:
+		return &UndocumentedError{Pattern: endpoint.Pattern, Reason: "no responses"}
+	}
+
+	_, path, err := splitPattern(endpoint.Pattern)
+	if err != nil {
+		return err
+	}
+	for _, match := range pathParamPattern.FindAllStringSubmatch(path, -1) {
+		if _, ok := endpoint.Doc.PathParams[match[1]]; !ok {
+			return &UndocumentedError{Pattern: endpoint.Pattern, Reason: fmt.Sprintf("path parameter %q has no type", match[1])}
+		}
+	}
+
+	return nil
+}
+
+func splitPattern(pattern string) (string, string, error) {
+	method, path, ok := strings.Cut(pattern, " ")
+	if !ok || method == "" || !strings.HasPrefix(path, "/") {
+		return "", "", fmt.Errorf("endpoint %q must have a method-qualified pattern", pattern)
+	}
+
+	return method, pathParamPattern.ReplaceAllString(path, "{$1}"), nil
+}
+
+type generator struct {
+	schemas map[string]*Schema
+}
+
+func (g *generator) operation(path string, doc *models.Doc) (Operation, error) {
+	op := Operation{
+		Summary:     doc.Summary,
+		Description: doc.Description,
+		Tags:        doc.Tags,
+		Responses:   make(map[string]Response, len(doc.Responses)),
+	}
+
+	for _, match := range pathParamPattern.FindAllStringSubmatch(path, -1) {
+		op.Parameters = append(op.Parameters, Parameter{
+			Name:     match[1],
+			In:       "path",
+			Required: true,
+			Schema:   g.schema(reflect.TypeOf(doc.PathParams[match[1]])),
+		})
+	}
+	for _, name := range slices.Sorted(maps.Keys(doc.QueryParams)) {
+		op.Parameters = append(op.Parameters, Parameter{
+			Name:   name,
+			In:     "query",
+			Schema: g.schema(reflect.TypeOf(doc.QueryParams[name])),
+		})
+	}
+
+	if doc.Request != nil {
+		op.RequestBody = &RequestBody{
+			Required: true,
+			Content: map[string]MediaType{
+				contentType(doc.Request): {Schema: g.schema(reflect.TypeOf(doc.Request))},
+			},
+		}
+	}
+
+	statuses := make([]int, 0, len(doc.Responses))
+	for status := range doc.Responses {
+		statuses = append(statuses, status)
+	}
+	slices.Sort(statuses)
+
+	for _, status := range statuses {
+		resp := doc.Responses[status]
+		description := resp.Description
+		if description == "" {
+			description = http.StatusText(status)
+		}
+		if description == "" {
+			return Operation{}, fmt.Errorf("status %d has no description", status)
+		}
+
+		out := Response{Description: description}
+		if resp.Body != nil {
+			out.Content = map[string]MediaType{
+				contentType(resp.Body): {Schema: g.schema(reflect.TypeOf(resp.Body))},
+			}
+		}
+		op.Responses[strconv.Itoa(status)] = out
+	}
+
+	return op, nil
+}
+
+// contentType берёт тип тела из метода ContentType, если он есть; по умолчанию — JSON.
+func contentType(body any) string {
+	if typed, ok := body.(interface{ ContentType() string }); ok {
+		return typed.ContentType()
+	}
+	return "application/json"
+}
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+)
+
+func (g *generator) schema(t reflect.Type) *Schema {
+	if t == nil || t == rawMessageType {
+		return &Schema{}
+	}
+	// обёртки вроде dto.Optional описываются типом, который они передают
+	if typed, ok := reflect.Zero(t).Interface().(interface{ SchemaType() reflect.Type }); ok {
+		return g.schema(typed.SchemaType())
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		inner := g.schema(t.Elem())
+		if inner.Ref != "" {
+			return inner
+		}
+		inner.Nullable = true
+		return inner
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Int8, reflect.Int16, reflect.Int32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		zero := 0.0
+		return &Schema{Type: "integer", Format: "int64", Minimum: &zero}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: g.schema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schema(t.Elem())}
+	case reflect.Struct:
+		if t == timeType {
+			return &Schema{Type: "string", Format: "date-time"}
+		}
+		return g.structRef(t)
+	default:
+		return &Schema{}
+	}
+}
+
+func (g *generator) structRef(t reflect.Type) *Schema {
+	name := t.Name()
+	if name == "" {
+		return g.structSchema(t)
+	}
+
+	pkg := t.PkgPath()
+	if i := strings.LastIndex(pkg, "/"); i >= 0 {
+		pkg = pkg[i+1:]
+	}
+	// одинаковые имена в разных пакетах (Request, Response) различаем префиксом пакета
+	if pkg != "dto" && !strings.HasPrefix(strings.ToLower(name), pkg) {
+		name = strings.ToUpper(pkg[:1]) + pkg[1:] + name
+	}
+
+	ref := &Schema{Ref: "#/components/schemas/" + name}
+	if _, ok := g.schemas[name]; ok {
+		return ref
+	}
+
+	// заглушка защищает от бесконечной рекурсии на ссылающихся на себя типах
+	g.schemas[name] = &Schema{Type: "object"}
+	g.schemas[name] = g.structSchema(t)
+
+	return ref
+}
+
+func (g *generator) structSchema(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object"}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" && opts == "" {
+			continue
+		}
+
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				inner := g.structSchema(embedded)
+				for prop, propSchema := range inner.Properties {
+					if schema.Properties == nil {
+						schema.Properties = make(map[string]*Schema)
+					}
+					schema.Properties[prop] = propSchema
+				}
+				schema.Required = append(schema.Required, inner.Required...)
+				continue
+			}
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+		if schema.Properties == nil {
+			schema.Properties = make(map[string]*Schema)
+		}
+		schema.Properties[name] = g.schema(field.Type)
+
+		if !strings.Contains(opts, "omitempty") && field.Type.Kind() != reflect.Pointer {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+	slices.Sort(schema.Required)
+
+	return schema
+}
@@ -0,0 +1,183 @@
+package openapi
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/Ant-Tab-Shift/todos-service/internal/health"
+	"github.com/Ant-Tab-Shift/todos-service/internal/metrics"
+	"github.com/Ant-Tab-Shift/todos-service/internal/transport/graphql"
+	"github.com/Ant-Tab-Shift/todos-service/internal/transport/http/handlers"
+	"github.com/Ant-Tab-Shift/todos-service/internal/transport/http/models"
+	"github.com/Ant-Tab-Shift/todos-service/internal/transport/jsonrpc"
+	"github.com/Ant-Tab-Shift/todos-service/internal/transport/ws"
+)
+
+type staticHandler []models.Endpoint
+
+func (s staticHandler) Handlers() []models.Endpoint {
+	return s
+}
+
+func registeredHandlers() []Documented {
+	return []Documented{
+		handlers.NewTaskHandler(nil),
+		ws.NewHandler(nil, nil, nil),
+		jsonrpc.NewHandler(nil),
+		graphql.NewHandler(nil),
+		metrics.NewHandler(nil),
+		health.NewHandler(nil),
+		handlers.NewAdminHandler(nil),
+	}
+}
+
+func TestEveryRegisteredEndpointIsDocumented(t *testing.T) {
+	for _, handler := range registeredHandlers() {
+		for _, endpoint := range handler.Handlers() {
+			if err := Validate(endpoint); err != nil {
+				t.Errorf("%v", err)
+			}
+		}
+	}
+}
+
+func TestGenerate_UndocumentedEndpointFails(t *testing.T) {
+	noop := func(http.ResponseWriter, *http.Request) {}
+
+	tests := []struct {
+		name     string
+		endpoint models.Endpoint
+	}{
+		{"missing doc", models.Endpoint{Pattern: "GET /things", Func: noop}},
+		{"missing summary", models.Endpoint{Pattern: "GET /things", Func: noop, Doc: &models.Doc{
+			Responses: map[int]models.Response{http.StatusOK: {}},
+		}}},
+		{"missing responses", models.Endpoint{Pattern: "GET /things", Func: noop, Doc: &models.Doc{Summary: "List"}}},
+		{"untyped path param", models.Endpoint{Pattern: "GET /things/{id}", Func: noop, Doc: &models.Doc{
+			Summary:   "Get",
+			Responses: map[int]models.Response{http.StatusOK: {}},
+		}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Generate(Info{}, staticHandler{tt.endpoint})
+			var undocumented *UndocumentedError
+			if !errors.As(err, &undocumented) {
+				t.Errorf("error = %v, want UndocumentedError", err)
+			}
+		})
+	}
+}
+
+func TestGenerate_TaskEndpoints(t *testing.T) {
+	spec, err := Generate(Info{Title: "todos", Version: "1"}, handlers.NewTaskHandler(nil))
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+
+	item, ok := spec.Paths["/todos/{id}"]
+	if !ok {
+		t.Fatal("path /todos/{id} not documented")
+	}
+
+	put, ok := item["put"]
+	if !ok {
+		t.Fatal("PUT /todos/{id} not documented")
+	}
+	if len(put.Parameters) != 1 || put.Parameters[0].Name != "id" || put.Parameters[0].Schema.Type != "integer" {
+		t.Errorf("parameters = %+v, want integer id", put.Parameters)
+	}
+	if put.RequestBody == nil || put.RequestBody.Content["application/json"].Schema.Ref != "#/components/schemas/UpdateTaskRequest" {
+		t.Errorf("request body = %+v, want UpdateTaskRequest ref", put.RequestBody)
+	}
+	for _, status := range []string{"204", "400", "404", "500"} {
+		if _, ok := put.Responses[status]; !ok {
+			t.Errorf("PUT response %s not documented", status)
+		}
+	}
+
+	update := spec.Components.Schemas["UpdateTaskRequest"]
+	if update == nil {
+		t.Fatal("UpdateTaskRequest schema missing")
+	}
+	for name, typ := range map[string]string{"due_date": "string", "tags": "array", "parent_id": "integer"} {
+		if prop := update.Properties[name]; prop == nil || prop.Type != typ || !prop.Nullable {
+			t.Errorf("UpdateTaskRequest.%s = %+v, want nullable %s", name, prop, typ)
+		}
+	}
+	if want := []string{"description", "is_done", "title"}; !slices.Equal(update.Required, want) {
+		t.Errorf("UpdateTaskRequest required = %v, want %v", update.Required, want)
+	}
+
+	task, ok := spec.Components.Schemas["TaskResponse"]
+	if !ok {
+		t.Fatal("TaskResponse schema missing")
+	}
+	wantProps := map[string]string{"id": "integer", "title": "string", "description": "string", "is_done": "boolean"}
+	for name, typ := range wantProps {
+		prop, ok := task.Properties[name]
+		if !ok {
+			t.Errorf("TaskResponse.%s missing", name)
+			continue
+		}
+		if prop.Type != typ {
+			t.Errorf("TaskResponse.%s type = %s, want %s", name, prop.Type, typ)
+		}
+	}
+
+	list := spec.Components.Schemas["TaskListResponse"]
+	if list == nil || list.Properties["tasks"].Items.Ref != "#/components/schemas/TaskResponse" {
+		t.Errorf("TaskListResponse = %+v, want tasks array of TaskResponse", list)
+	}
+
+	for _, name := range []string{"CreateTaskRequest", "Problem", "ProblemFieldError"} {
+		if _, ok := spec.Components.Schemas[name]; !ok {
+			t.Errorf("schema %s missing", name)
+		}
+	}
+
+	notFound := put.Responses["404"].Content["application/problem+json"]
+	if notFound.Schema == nil || notFound.Schema.Ref != "#/components/schemas/Problem" {
+		t.Errorf("404 content = %+v, want problem+json Problem ref", put.Responses["404"].Content)
+	}
+
+	imports := spec.Paths["/todos/import"]["post"]
+	if len(imports.Parameters) != 1 || imports.Parameters[0].In != "query" || imports.Parameters[0].Required {
+		t.Errorf("import parameters = %+v, want optional query parameter ids", imports.Parameters)
+	}
+	if imports.RequestBody == nil || imports.RequestBody.Content["application/x-ndjson"].Schema == nil {
+		t.Errorf("import request body = %+v, want application/x-ndjson", imports.RequestBody)
+	}
+}
+
+func TestHandler_ServesSpec(t *testing.T) {
+	h, err := NewHandler(Info{Title: "todos", Version: "1"}, registeredHandlers()...)
+	if err != nil {
+		t.Fatalf("NewHandler failed: %v", err)
+	}
+
+	rec := httptest.NewRecorder()
+	h.Serve(rec, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+
+	var spec Spec
+	if err = json.Unmarshal(rec.Body.Bytes(), &spec); err != nil {
+		t.Fatalf("decode spec failed: %v", err)
+	}
+	if spec.OpenAPI != version {
+		t.Errorf("openapi = %q, want %q", spec.OpenAPI, version)
+	}
+	for _, path := range []string{"/todos", "/todos/{id}", "/ws", "/rpc", "/graphql", "/metrics", "/healthz", "/readyz", "/admin/backup", "/admin/restore", "/openapi.json"} {
+		if _, ok := spec.Paths[path]; !ok {
+			t.Errorf("path %s missing from spec", path)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package problem
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/Ant-Tab-Shift/todos-service/internal/logging"
+	"github.com/Ant-Tab-Shift/todos-service/internal/transport/http/requestid"
+	"github.com/Ant-Tab-Shift/todos-service/internal/validation"
+)
+
+const (
+	ContentType = "application/problem+json"
+
+	TypeValidation = "/problems/validation"
+)
+
+type Problem struct {
+	Type      string       `json:"type"`
+	Title     string       `json:"title"`
+	Status    int          `json:"status"`
+	Detail    string       `json:"detail,omitempty"`
+	Instance  string       `json:"instance,omitempty"`
+	RequestID string       `json:"request_id,omitempty"`
+	Errors    []FieldError `json:"errors,omitempty"`
+}
+
+type FieldError struct {
+	Field   string `json:"field,omitempty"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (Problem) ContentType() string {
+	return ContentType
+}
+
+func New(status int, detail string) Problem {
+	return Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+	}
+}
+
+type entry struct {
+	target error
+	status int
+	typ    string
+	title  string
+}
+
+type Registry struct {
+	entries []entry
+}
+
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Register сопоставляет ошибку target (через errors.Is) с ответом; при совпадении
+// нескольких записей побеждает зарегистрированная раньше.
+func (r *Registry) Register(target error, status int, typ, title string) *Registry {
+	r.entries = append(r.entries, entry{target: target, status: status, typ: typ, title: title})
+	return r
+}
+
+// Resolve никогда не раскрывает текст незарегистрированных ошибок - они
+// превращаются в 500 без деталей.
+func (r *Registry) Resolve(err error) Problem {
+	var invalid validation.Errors
+	if errors.As(err, &invalid) {
+		return fromValidation(invalid)
+	}
+
+	for _, e := range r.entries {
+		if errors.Is(err, e.target) {
+			return Problem{
+				Type:   e.typ,
+				Title:  e.title,
+				Status: e.status,
+				Detail: e.target.Error(),
+			}
+		}
+	}
+
+	return New(http.StatusInternalServerError, "internal server error")
+}
+
+func fromValidation(errs validation.Errors) Problem {
+	return Problem{
+		Type:   TypeValidation,
+		Title:  "Validation failed",
+		Status: http.StatusBadRequest,
+		Detail: "request contains invalid fields",
+		Errors: FieldErrors(errs),
+	}
+}
+
+// FieldErrors переводит ошибки валидации в вид, в котором они отдаются клиенту.
+func FieldErrors(errs validation.Errors) []FieldError {
+	details := make([]FieldError, 0, len(errs))
+	for _, err := range errs {
+		details = append(details, FieldError{
+			Field:   err.Field,
+			Code:    err.Code,
+			Message: err.Message,
+		})
+	}
+
+	return details
+}
+
+func Write(w http.ResponseWriter, r *http.Request, p Problem) {
+	if p.Instance == "" {
+		p.Instance = r.URL.Path
+	}
+	if p.RequestID == "" {
+		p.RequestID = requestid.FromContext(r.Context())
+	}
+
+	w.Header().Set("Content-Type", ContentType)
+	w.WriteHeader(p.Status)
+
+	if err := json.NewEncoder(w).Encode(p); err != nil {
+		slog.ErrorContext(r.Context(), "failed to encode problem response", logging.Err(err))
+	}
+}
@@ -0,0 +1,95 @@
+package problem
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Ant-Tab-Shift/todos-service/internal/transport/http/requestid"
+	"github.com/Ant-Tab-Shift/todos-service/internal/validation"
+)
+
+var (
+	errMissing = errors.New("missing")
+	errBroken  = errors.New("broken")
+)
+
+func TestRegistry_Resolve(t *testing.T) {
+	registry := NewRegistry().
+		Register(errMissing, http.StatusNotFound, "/problems/missing", "Missing").
+		Register(errBroken, http.StatusConflict, "/problems/broken", "Broken")
+
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantType   string
+		wantDetail string
+	}{
+		{"registered", errMissing, http.StatusNotFound, "/problems/missing", "missing"},
+		{"wrapped", fmt.Errorf("lookup: %w", errBroken), http.StatusConflict, "/problems/broken", "broken"},
+		{"first match wins", errors.Join(errBroken, errMissing), http.StatusNotFound, "/problems/missing", "missing"},
+		{"unknown is not leaked", errors.New("db password is hunter2"), http.StatusInternalServerError, "about:blank", "internal server error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := registry.Resolve(tt.err)
+			if p.Status != tt.wantStatus || p.Type != tt.wantType || p.Detail != tt.wantDetail {
+				t.Errorf("Resolve() = %+v, want status %d, type %s, detail %q", p, tt.wantStatus, tt.wantType, tt.wantDetail)
+			}
+		})
+	}
+}
+
+func TestRegistry_ResolveValidation(t *testing.T) {
+	err := fmt.Errorf("validation failed: %w", validation.Errors{
+		{Field: "title", Code: validation.CodeRequired, Message: "must not be blank", Err: errMissing},
+		{Field: "is_done", Code: validation.CodeInvalidType, Message: "must be a boolean"},
+	})
+
+	// ошибки валидации важнее доменных, которые они оборачивают
+	p := NewRegistry().Register(errMissing, http.StatusNotFound, "/problems/missing", "Missing").Resolve(err)
+
+	if p.Status != http.StatusBadRequest || p.Type != TypeValidation {
+		t.Fatalf("Resolve() = %+v, want validation problem", p)
+	}
+	if len(p.Errors) != 2 || p.Errors[0].Field != "title" || p.Errors[1].Code != validation.CodeInvalidType {
+		t.Errorf("Errors = %+v", p.Errors)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/todos/7", nil)
+	r = r.WithContext(requestid.NewContext(r.Context(), "req-1"))
+	rec := httptest.NewRecorder()
+
+	Write(rec, r, New(http.StatusNotFound, "task not found"))
+
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != ContentType {
+		t.Errorf("Content-Type = %q, want %q", ct, ContentType)
+	}
+
+	var got Problem
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+	want := Problem{
+		Type:      "about:blank",
+		Title:     "Not Found",
+		Status:    http.StatusNotFound,
+		Detail:    "task not found",
+		Instance:  "/todos/7",
+		RequestID: "req-1",
+	}
+	if got.Type != want.Type || got.Title != want.Title || got.Status != want.Status ||
+		got.Detail != want.Detail || got.Instance != want.Instance || got.RequestID != want.RequestID {
+		t.Errorf("problem = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,45 @@
+package requestid
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMiddleware(t *testing.T) {
+	tests := []struct {
+		name     string
+		incoming string
+		wantSame bool
+	}{
+		{"propagates client id", "abc-123.def", true},
+		{"generates when missing", "", false},
+		{"replaces unsafe id", "bad id\r\ninjected: 1", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var seen string
+			handler := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				seen = FromContext(r.Context())
+			}))
+
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.incoming != "" {
+				r.Header.Set(Header, tt.incoming)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, r)
+
+			if seen == "" {
+				t.Fatal("request id missing from context")
+			}
+			if got := rec.Header().Get(Header); got != seen {
+				t.Errorf("response header = %q, context = %q", got, seen)
+			}
+			if (seen == tt.incoming) != tt.wantSame {
+				t.Errorf("id = %q, incoming = %q, wantSame = %v", seen, tt.incoming, tt.wantSame)
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/Ant-Tab-Shift/todos-service/internal/transport/http/identity"
+	"github.com/Ant-Tab-Shift/todos-service/internal/transport/http/problem"
+)
+
+// adminPrefix — пути, доступные только администраторам.
+const adminPrefix = "/admin/"
+
+var errAdminRequired = errors.New("admin identity is required")
+
+// AdminAccess хранит субъекты клиентских сертификатов администраторов
+// в том виде, в каком их возвращает identity.FromContext.
+type AdminAccess struct {
+	mu         sync.RWMutex
+	identities []string
+}
+
+func NewAdminAccess(identities []string) *AdminAccess {
+	return &AdminAccess{identities: identities}
+}
+
+func (a *AdminAccess) SetIdentities(identities []string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.identities = identities
+}
+
+func (a *AdminAccess) allowed(id string) bool {
+	if a == nil {
+		return false
+	}
+
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	return slices.Contains(a.identities, id)
+}
+
+// adminMiddleware пропускает к /admin/ только администраторов; без AdminAccess
+// эти пути закрыты для всех.
+func adminMiddleware(access *AdminAccess, mux *http.ServeMux, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, adminPrefix) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		id, ok := identity.FromContext(r.Context())
+		if ok && access.allowed(id) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		_, r.Pattern = mux.Handler(r)
+		if !ok {
+			problem.Write(w, r, problem.New(http.StatusUnauthorized, errClientCertRequired.Error()))
+			return
+		}
+		problem.Write(w, r, problem.New(http.StatusForbidden, errAdminRequired.Error()))
+	})
+}
@@ -0,0 +1,57 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Ant-Tab-Shift/todos-service/internal/transport/http/identity"
+)
+
+func TestAdminMiddleware(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /admin/backup", func(w http.ResponseWriter, r *http.Request) {})
+	mux.HandleFunc("POST /todos", func(w http.ResponseWriter, r *http.Request) {})
+
+	access := NewAdminAccess([]string{"CN=admin,O=todos"})
+	handler := adminMiddleware(access, mux, mux)
+
+	tests := []struct {
+		name     string
+		path     string
+		identity string
+		access   *AdminAccess
+		want     int
+	}{
+		{name: "admin", path: "/admin/backup", identity: "CN=admin,O=todos", access: access, want: http.StatusOK},
+		{name: "anonymous", path: "/admin/backup", access: access, want: http.StatusUnauthorized},
+		{name: "not an admin", path: "/admin/backup", identity: "CN=alice,O=todos", access: access, want: http.StatusForbidden},
+		{name: "no admins configured", path: "/admin/backup", identity: "CN=admin,O=todos", want: http.StatusForbidden},
+		{name: "other path", path: "/todos", access: access, want: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tt.path, nil)
+			if tt.identity != "" {
+				req = req.WithContext(identity.NewContext(req.Context(), tt.identity))
+			}
+
+			rec := httptest.NewRecorder()
+			adminMiddleware(tt.access, mux, mux).ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d", rec.Code, tt.want)
+			}
+		})
+	}
+
+	// список администраторов меняется при перезагрузке конфигурации
+	access.SetIdentities([]string{"CN=alice,O=todos"})
+	req := httptest.NewRequest(http.MethodPost, "/admin/backup", nil)
+	req = req.WithContext(identity.NewContext(req.Context(), "CN=admin,O=todos"))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("status after SetIdentities = %d, want 403", rec.Code)
+	}
+}
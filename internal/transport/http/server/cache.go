@@ -0,0 +1,64 @@
+package server
+
+import (
+	"net/http"
+)
+
+// cacheControlWriter дописывает Cache-Control к ответу, если обработчик не задал его сам.
+type cacheControlWriter struct {
+	http.ResponseWriter
+	policy      string
+	wroteHeader bool
+}
+
+func (cw *cacheControlWriter) WriteHeader(code int) {
+	if !cw.wroteHeader {
+		cw.wroteHeader = true
+		// ошибки кешировать не нужно, а 304 должен повторять политику исходного ответа
+		if (code == http.StatusOK || code == http.StatusNotModified) && cw.Header().Get("Cache-Control") == "" {
+			cw.Header().Set("Cache-Control", cw.policy)
+		}
+	}
+	cw.ResponseWriter.WriteHeader(code)
+}
+
+func (cw *cacheControlWriter) Write(b []byte) (int, error) {
+	if !cw.wroteHeader {
+		cw.WriteHeader(http.StatusOK)
+	}
+	return cw.ResponseWriter.Write(b)
+}
+
+func (cw *cacheControlWriter) Flush() {
+	if !cw.wroteHeader {
+		cw.WriteHeader(http.StatusOK)
+	}
+	if f, ok := cw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap открывает http.ResponseController доступ к дедлайнам соединения.
+func (cw *cacheControlWriter) Unwrap() http.ResponseWriter {
+	return cw.ResponseWriter
+}
+
+// cacheControlMiddleware задаёт Cache-Control успешным ответам на GET и HEAD
+// по шаблону маршрута, например {"GET /todos/{id}": "private, no-cache"}.
+func cacheControlMiddleware(policies map[string]string, mux *http.ServeMux, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		_, route := mux.Handler(r)
+		policy, ok := policies[route]
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		next.ServeHTTP(&cacheControlWriter{ResponseWriter: w, policy: policy}, r)
+	})
+}
@@ -0,0 +1,58 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCacheControlMiddleware(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /todos/{id}", func(w http.ResponseWriter, r *http.Request) {
+		switch r.PathValue("id") {
+		case "1":
+			w.Write([]byte("{}"))
+		case "2":
+			w.WriteHeader(http.StatusNotModified)
+		case "3":
+			w.Header().Set("Cache-Control", "no-store")
+			w.Write([]byte("{}"))
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	mux.HandleFunc("DELETE /todos/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("GET /metrics", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("up 1"))
+	})
+
+	handler := cacheControlMiddleware(map[string]string{"GET /todos/{id}": "private, no-cache"}, mux, mux)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		want   string
+	}{
+		{name: "configured route", method: http.MethodGet, path: "/todos/1", want: "private, no-cache"},
+		{name: "head uses get route", method: http.MethodHead, path: "/todos/1", want: "private, no-cache"},
+		{name: "not modified", method: http.MethodGet, path: "/todos/2", want: "private, no-cache"},
+		{name: "handler policy wins", method: http.MethodGet, path: "/todos/3", want: "no-store"},
+		{name: "error is not cached", method: http.MethodGet, path: "/todos/9"},
+		{name: "unsafe method", method: http.MethodDelete, path: "/todos/1"},
+		{name: "other route", method: http.MethodGet, path: "/metrics"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, nil))
+
+			if got := rec.Header().Get("Cache-Control"); got != tt.want {
+				t.Errorf("Cache-Control = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package server
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCompressionMiddleware(t *testing.T) {
+	large := strings.Repeat(`{"title":"task"}`, 100)
+
+	tests := []struct {
+		name           string
+		method         string
+		acceptEncoding string
+		contentType    string
+		body           string
+		status         int
+		wantEncoding   string
+	}{
+		{name: "gzip", acceptEncoding: "gzip", contentType: "application/json", body: large, wantEncoding: "gzip"},
+		{name: "deflate", acceptEncoding: "deflate", contentType: "application/json", body: large, wantEncoding: "deflate"},
+		{name: "preferred by q", acceptEncoding: "gzip;q=0.5, deflate", contentType: "text/csv", body: large, wantEncoding: "deflate"},
+		{name: "below threshold", acceptEncoding: "gzip", contentType: "application/json", body: `{}`},
+		{name: "not accepted", acceptEncoding: "br", contentType: "application/json", body: large},
+		{name: "gzip refused", acceptEncoding: "gzip;q=0", contentType: "application/json", body: large},
+		{name: "binary content", acceptEncoding: "gzip", contentType: "image/png", body: large},
+		{name: "problem json", acceptEncoding: "*", contentType: "application/problem+json", body: large, status: http.StatusBadRequest, wantEncoding: "gzip"},
+		{name: "head", method: http.MethodHead, acceptEncoding: "gzip", contentType: "application/json"},
+		{name: "no content", acceptEncoding: "gzip", status: http.StatusNoContent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status := tt.status
+			if status == 0 {
+				status = http.StatusOK
+			}
+			handler := compressionMiddleware(256, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tt.contentType != "" {
+					w.Header().Set("Content-Type", tt.contentType)
+				}
+				w.WriteHeader(status)
+				// тело пишется частями, чтобы порог проверялся по суммарному размеру
+				half := len(tt.body) / 2
+				io.WriteString(w, tt.body[:half])
+				io.WriteString(w, tt.body[half:])
+			}))
+
+			method := tt.method
+			if method == "" {
+				method = http.MethodGet
+			}
+			req := httptest.NewRequest(method, "/todos", nil)
+			req.Header.Set("Accept-Encoding", tt.acceptEncoding)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != status {
+				t.Errorf("status = %d, want %d", rec.Code, status)
+			}
+			if got := rec.Header().Get("Content-Encoding"); got != tt.wantEncoding {
+				t.Fatalf("Content-Encoding = %q, want %q", got, tt.wantEncoding)
+			}
+			if got := rec.Header().Get("Vary"); got != "Accept-Encoding" {
+				t.Errorf("Vary = %q, want Accept-Encoding", got)
+			}
+			if tt.body == "" {
+				return
+			}
+
+			if got := decode(t, tt.wantEncoding, rec.Body); got != tt.body {
+				t.Errorf("body length = %d, want %d", len(got), len(tt.body))
+			}
+		})
+	}
+}
+
+func TestCompressionMiddleware_FlushStreams(t *testing.T) {
+	flushed := make(chan struct{})
+	handler := compressionMiddleware(1024, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		io.WriteString(w, "{\"id\":1}\n")
+		w.(http.Flusher).Flush()
+		close(flushed)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/todos", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	<-flushed
+
+	if !rec.Flushed {
+		t.Error("response was not flushed")
+	}
+	if got := rec.Header().Get("Content-Encoding"); got != "gzip" {
+		t.Fatalf("Content-Encoding = %q, want gzip", got)
+	}
+	if got := decode(t, "gzip", rec.Body); got != "{\"id\":1}\n" {
+		t.Errorf("body = %q", got)
+	}
+}
+
+func TestCompressionMiddleware_WeakensETag(t *testing.T) {
+	body := strings.Repeat("a", 512)
+	handler := compressionMiddleware(256, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("ETag", `"42"`)
+		io.WriteString(w, body)
+	}))
+
+	for encoding, want := range map[string]string{"gzip": `W/"42"`, "identity": `"42"`} {
+		req := httptest.NewRequest(http.MethodGet, "/todos/1", nil)
+		req.Header.Set("Accept-Encoding", encoding)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		if got := rec.Header().Get("ETag"); got != want {
+			t.Errorf("%s: ETag = %q, want %q", encoding, got, want)
+		}
+	}
+}
+
+func TestCompressionMiddleware_SkipsUpgrade(t *testing.T) {
+	handler := compressionMiddleware(1, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := w.(*compressWriter); ok {
+			t.Error("upgrade request must not be wrapped")
+		}
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/ws", nil)
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Accept-Encoding", "gzip")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+}
+
+func decode(t *testing.T, encoding string, body io.Reader) string {
+	t.Helper()
+
+	var (
+		r   io.Reader = body
+		err error
+	)
+	switch encoding {
+	case "gzip":
+		r, err = gzip.NewReader(body)
+	case "deflate":
+		r, err = zlib.NewReader(body)
+	}
+	if err != nil {
+		t.Fatalf("failed to open %s stream: %v", encoding, err)
+	}
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("failed to read %s stream: %v", encoding, err)
+	}
+	return string(data)
+}
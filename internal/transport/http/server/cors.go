@@ -0,0 +1,188 @@
+package server
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Ant-Tab-Shift/todos-service/internal/transport/http/problem"
+)
+
+type CORSConfig struct {
+	// AllowedOrigins содержит точные origin вида "https://app.example.com" или "*".
+	AllowedOrigins []string
+	// AllowedMethods ограничивает методы; пустой список разрешает все зарегистрированные.
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	ExposedHeaders   []string
+	AllowCredentials bool
+	MaxAge           time.Duration
+}
+
+type CORS struct {
+	mu  sync.RWMutex
+	cfg CORSConfig
+}
+
+func NewCORS(cfg CORSConfig) *CORS {
+	return &CORS{cfg: cfg}
+}
+
+func (c *CORS) SetConfig(cfg CORSConfig) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.cfg = cfg
+}
+
+// AllowsOrigin сообщает, разрешён ли origin текущими настройками. Нужен обработчикам,
+// запросы к которым браузер не проверяет по CORS, например рукопожатию WebSocket.
+func (c *CORS) AllowsOrigin(origin string) bool {
+	return c.config().allowOrigin(origin) != ""
+}
+
+func (c *CORS) config() CORSConfig {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.cfg
+}
+
+// allowOrigin возвращает значение Access-Control-Allow-Origin или пустую строку.
+func (cfg CORSConfig) allowOrigin(origin string) string {
+	for _, allowed := range cfg.AllowedOrigins {
+		switch {
+		case allowed == "*" && !cfg.AllowCredentials:
+			return "*"
+		case allowed == "*", allowed == origin:
+			return origin
+		}
+	}
+	return ""
+}
+
+func (cfg CORSConfig) allowHeaders(requested string) bool {
+	if slices.Contains(cfg.AllowedHeaders, "*") && !cfg.AllowCredentials {
+		return true
+	}
+
+	for _, header := range strings.Split(requested, ",") {
+		header = strings.TrimSpace(header)
+		if header == "" {
+			continue
+		}
+		if !slices.ContainsFunc(cfg.AllowedHeaders, func(allowed string) bool {
+			return strings.EqualFold(allowed, header)
+		}) {
+			return false
+		}
+	}
+	return true
+}
+
+var routeMethodCandidates = []string{
+	http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete,
+}
+
+// routeMethods находит методы, для которых в mux зарегистрирован шаблон с путём запроса.
+func routeMethods(mux *http.ServeMux, r *http.Request) []string {
+	var methods []string
+	for _, method := range routeMethodCandidates {
+		probe := r.Clone(r.Context())
+		probe.Method = method
+		if _, pattern := mux.Handler(probe); pattern != "" {
+			methods = append(methods, method)
+		}
+	}
+	return methods
+}
+
+// corsMiddleware отвечает на OPTIONS сам: шаблоны mux привязаны к методам,
+// и без этого preflight-запрос браузера получал бы 405.
+func corsMiddleware(c *CORS, mux *http.ServeMux, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cfg := c.config()
+		origin := r.Header.Get("Origin")
+
+		if r.Method == http.MethodOptions {
+			methods := routeMethods(mux, r)
+			if len(methods) == 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			requested := r.Header.Get("Access-Control-Request-Method")
+			if origin == "" || requested == "" {
+				w.Header().Set("Allow", strings.Join(append(methods, http.MethodOptions), ", "))
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+
+			preflight(w, r, cfg, methods, origin, requested)
+			return
+		}
+
+		if origin != "" {
+			if allowed := cfg.allowOrigin(origin); allowed != "" {
+				setOriginHeaders(w.Header(), cfg, allowed)
+				if len(cfg.ExposedHeaders) > 0 {
+					w.Header().Set("Access-Control-Expose-Headers", strings.Join(cfg.ExposedHeaders, ", "))
+				}
+			}
+		}
+		// ответ зависит от Origin всегда, кроме статического "*"
+		if len(cfg.AllowedOrigins) > 0 && !(slices.Contains(cfg.AllowedOrigins, "*") && !cfg.AllowCredentials) {
+			w.Header().Add("Vary", "Origin")
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func preflight(w http.ResponseWriter, r *http.Request, cfg CORSConfig, methods []string, origin, requested string) {
+	w.Header().Add("Vary", "Origin, Access-Control-Request-Method, Access-Control-Request-Headers")
+
+	allowed := cfg.allowOrigin(origin)
+	if allowed == "" {
+		problem.Write(w, r, problem.New(http.StatusForbidden, "origin is not allowed"))
+		return
+	}
+
+	if len(cfg.AllowedMethods) > 0 {
+		methods = slices.DeleteFunc(methods, func(m string) bool {
+			return !slices.Contains(cfg.AllowedMethods, m)
+		})
+	}
+	if !slices.Contains(methods, requested) {
+		problem.Write(w, r, problem.New(http.StatusForbidden, "method "+requested+" is not allowed"))
+		return
+	}
+
+	requestedHeaders := r.Header.Get("Access-Control-Request-Headers")
+	if !cfg.allowHeaders(requestedHeaders) {
+		problem.Write(w, r, problem.New(http.StatusForbidden, "requested headers are not allowed"))
+		return
+	}
+
+	header := w.Header()
+	setOriginHeaders(header, cfg, allowed)
+	header.Set("Access-Control-Allow-Methods", strings.Join(methods, ", "))
+	if requestedHeaders != "" {
+		// разрешённые заголовки уже проверены, поэтому возвращаем запрошенные как есть
+		header.Set("Access-Control-Allow-Headers", requestedHeaders)
+	}
+	if cfg.MaxAge > 0 {
+		header.Set("Access-Control-Max-Age", strconv.Itoa(int(cfg.MaxAge.Seconds())))
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func setOriginHeaders(header http.Header, cfg CORSConfig, allowed string) {
+	header.Set("Access-Control-Allow-Origin", allowed)
+	if cfg.AllowCredentials {
+		header.Set("Access-Control-Allow-Credentials", "true")
+	}
+}
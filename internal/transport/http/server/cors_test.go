@@ -0,0 +1,181 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func newCORSMux(cfg CORSConfig) http.Handler {
+	mux := http.NewServeMux()
+	ok := func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) }
+	mux.HandleFunc("GET /todos", ok)
+	mux.HandleFunc("POST /todos", ok)
+	mux.HandleFunc("GET /todos/{id}", ok)
+	mux.HandleFunc("PUT /todos/{id}", ok)
+	mux.HandleFunc("DELETE /todos/{id}", ok)
+
+	return corsMiddleware(NewCORS(cfg), mux, mux)
+}
+
+var testCORSConfig = CORSConfig{
+	AllowedOrigins:   []string{"https://app.example.com"},
+	AllowedHeaders:   []string{"Content-Type", "X-Request-ID"},
+	ExposedHeaders:   []string{"X-Request-ID"},
+	AllowCredentials: true,
+	MaxAge:           10 * time.Minute,
+}
+
+func TestCORS_Preflight(t *testing.T) {
+	handler := newCORSMux(testCORSConfig)
+
+	tests := []struct {
+		name        string
+		path        string
+		origin      string
+		method      string
+		headers     string
+		wantStatus  int
+		wantOrigin  string
+		wantMethods string
+	}{
+		{
+			name: "allowed", path: "/todos/1", origin: "https://app.example.com", method: http.MethodPut,
+			headers: "content-type, x-request-id", wantStatus: http.StatusNoContent,
+			wantOrigin: "https://app.example.com", wantMethods: "GET, HEAD, PUT, DELETE",
+		},
+		{
+			name: "collection route", path: "/todos", origin: "https://app.example.com", method: http.MethodPost,
+			wantStatus: http.StatusNoContent, wantOrigin: "https://app.example.com", wantMethods: "GET, HEAD, POST",
+		},
+		{
+			name: "unknown origin", path: "/todos", origin: "https://evil.example.com", method: http.MethodPost,
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name: "method not registered for path", path: "/todos/1", origin: "https://app.example.com", method: http.MethodPost,
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name: "header not allowed", path: "/todos", origin: "https://app.example.com", method: http.MethodPost,
+			headers: "Content-Type, X-Custom", wantStatus: http.StatusForbidden,
+		},
+		{
+			name: "unknown path", path: "/missing", origin: "https://app.example.com", method: http.MethodGet,
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodOptions, tt.path, nil)
+			req.Header.Set("Origin", tt.origin)
+			req.Header.Set("Access-Control-Request-Method", tt.method)
+			if tt.headers != "" {
+				req.Header.Set("Access-Control-Request-Headers", tt.headers)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			header := rec.Header()
+			if got := header.Get("Access-Control-Allow-Origin"); got != tt.wantOrigin {
+				t.Errorf("Allow-Origin = %q, want %q", got, tt.wantOrigin)
+			}
+			if got := header.Get("Access-Control-Allow-Methods"); got != tt.wantMethods {
+				t.Errorf("Allow-Methods = %q, want %q", got, tt.wantMethods)
+			}
+			if tt.wantStatus != http.StatusNoContent {
+				return
+			}
+			if got := header.Get("Access-Control-Allow-Credentials"); got != "true" {
+				t.Errorf("Allow-Credentials = %q, want true", got)
+			}
+			if got := header.Get("Access-Control-Max-Age"); got != "600" {
+				t.Errorf("Max-Age = %q, want 600", got)
+			}
+			if got := header.Get("Access-Control-Allow-Headers"); got != tt.headers {
+				t.Errorf("Allow-Headers = %q, want %q", got, tt.headers)
+			}
+		})
+	}
+}
+
+func TestCORS_PlainOptionsListsMethods(t *testing.T) {
+	rec := httptest.NewRecorder()
+	newCORSMux(CORSConfig{}).ServeHTTP(rec, httptest.NewRequest(http.MethodOptions, "/todos", nil))
+
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("status = %d, want 204", rec.Code)
+	}
+	if got := rec.Header().Get("Allow"); got != "GET, HEAD, POST, OPTIONS" {
+		t.Errorf("Allow = %q", got)
+	}
+}
+
+func TestCORS_ActualRequest(t *testing.T) {
+	tests := []struct {
+		name       string
+		cfg        CORSConfig
+		origin     string
+		wantOrigin string
+		wantVary   string
+		wantExpose string
+	}{
+		{
+			name: "allowed origin", cfg: testCORSConfig, origin: "https://app.example.com",
+			wantOrigin: "https://app.example.com", wantVary: "Origin", wantExpose: "X-Request-ID",
+		},
+		{
+			name: "unknown origin", cfg: testCORSConfig, origin: "https://evil.example.com",
+			wantVary: "Origin",
+		},
+		{
+			name: "wildcard", cfg: CORSConfig{AllowedOrigins: []string{"*"}}, origin: "https://any.example.com",
+			wantOrigin: "*",
+		},
+		{
+			name: "wildcard with credentials echoes origin",
+			cfg:  CORSConfig{AllowedOrigins: []string{"*"}, AllowCredentials: true}, origin: "https://any.example.com",
+			wantOrigin: "https://any.example.com", wantVary: "Origin",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/todos", nil)
+			req.Header.Set("Origin", tt.origin)
+			rec := httptest.NewRecorder()
+			newCORSMux(tt.cfg).ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, want 200", rec.Code)
+			}
+			header := rec.Header()
+			if got := header.Get("Access-Control-Allow-Origin"); got != tt.wantOrigin {
+				t.Errorf("Allow-Origin = %q, want %q", got, tt.wantOrigin)
+			}
+			if got := header.Get("Vary"); got != tt.wantVary {
+				t.Errorf("Vary = %q, want %q", got, tt.wantVary)
+			}
+			if got := header.Get("Access-Control-Expose-Headers"); got != tt.wantExpose {
+				t.Errorf("Expose-Headers = %q, want %q", got, tt.wantExpose)
+			}
+		})
+	}
+}
+
+func TestCORS_AllowsOrigin(t *testing.T) {
+	cors := NewCORS(CORSConfig{AllowedOrigins: []string{"https://app.example.com"}})
+	if !cors.AllowsOrigin("https://app.example.com") || cors.AllowsOrigin("https://evil.example.com") {
+		t.Error("AllowsOrigin must match the configured origins exactly")
+	}
+
+	cors.SetConfig(CORSConfig{AllowedOrigins: []string{"*"}})
+	if !cors.AllowsOrigin("https://evil.example.com") {
+		t.Error("AllowsOrigin must follow the reloaded config")
+	}
+}
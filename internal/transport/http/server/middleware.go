@@ -1,21 +1,96 @@
 package server
 
 import (
-	"log"
+	"bufio"
+	"errors"
+	"log/slog"
+	"net"
 	"net/http"
+	"runtime/debug"
+	"strconv"
 	"time"
+
+	"github.com/Ant-Tab-Shift/todos-service/internal/logging"
+	"github.com/Ant-Tab-Shift/todos-service/internal/metrics"
+	"github.com/Ant-Tab-Shift/todos-service/internal/tracing"
+	"github.com/Ant-Tab-Shift/todos-service/internal/transport/http/problem"
 )
 
 type responseWriter struct {
 	http.ResponseWriter
-	statusCode int
+	statusCode  int
+	wroteHeader bool
 }
 
 func (rw *responseWriter) WriteHeader(code int) {
-	rw.statusCode = code
+	if !rw.wroteHeader {
+		rw.statusCode = code
+		rw.wroteHeader = true
+	}
 	rw.ResponseWriter.WriteHeader(code)
 }
 
+func (rw *responseWriter) Write(b []byte) (int, error) {
+	rw.wroteHeader = true
+	return rw.ResponseWriter.Write(b)
+}
+
+func (rw *responseWriter) Flush() {
+	if f, ok := rw.ResponseWriter.(http.Flusher); ok {
+		rw.wroteHeader = true
+		f.Flush()
+	}
+}
+
+// Unwrap открывает http.ResponseController доступ к дедлайнам соединения.
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
+func (rw *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hj, ok := rw.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("underlying response writer does not support hijacking")
+	}
+
+	conn, buf, err := hj.Hijack()
+	if err == nil {
+		rw.statusCode = http.StatusSwitchingProtocols
+	}
+
+	return conn, buf, err
+}
+
+// recoveryMiddleware превращает панику обработчика в ответ 500 с problem+json.
+// Если ответ уже начат, соединение обрывается: дописать корректный ответ нельзя.
+func recoveryMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rw := &responseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+			if recovered == http.ErrAbortHandler {
+				panic(recovered)
+			}
+
+			slog.ErrorContext(r.Context(), "panic recovered",
+				slog.Any("panic", recovered),
+				slog.String("stack", string(debug.Stack())),
+			)
+
+			if rw.wroteHeader {
+				panic(http.ErrAbortHandler)
+			}
+			problem.Write(w, r, problem.New(http.StatusInternalServerError, "internal server error"))
+		}()
+
+		next.ServeHTTP(rw, r)
+	})
+}
+
 func loggingMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
@@ -24,12 +99,108 @@ func loggingMiddleware(next http.Handler) http.Handler {
 
 		next.ServeHTTP(rw, r)
 
-		log.Printf(
-			"%s %s %d %s",
-			r.Method,
-			r.URL.Path,
-			rw.statusCode,
-			time.Since(start),
+		level := slog.LevelInfo
+		if rw.statusCode >= http.StatusInternalServerError {
+			level = slog.LevelError
+		}
+
+		slog.LogAttrs(r.Context(), level, "request served",
+			slog.String("method", r.Method),
+			slog.String("path", r.URL.Path),
+			slog.String("pattern", r.Pattern),
+			slog.Int("status", rw.statusCode),
+			slog.Duration("duration", time.Since(start)),
+			slog.String("remote_addr", r.RemoteAddr),
+		)
+	})
+}
+
+type httpMetrics struct {
+	requests *metrics.CounterVec
+	duration *metrics.HistogramVec
+}
+
+func newHTTPMetrics(registry *metrics.Registry) *httpMetrics {
+	return &httpMetrics{
+		requests: registry.NewCounterVec("http_requests_total",
+			"Total number of HTTP requests by route and status code.", "method", "route", "status"),
+		duration: registry.NewHistogramVec("http_request_duration_seconds",
+			"HTTP request latency by route.", metrics.DefBuckets, "method", "route"),
+	}
+}
+
+// metricsMiddleware должен стоять ниже всех, кто подменяет запрос через WithContext:
+// r.Pattern заполняется маршрутизатором на том же запросе, и сырой путь в метки не попадает.
+func metricsMiddleware(m *httpMetrics, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		rw := &responseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+
+		next.ServeHTTP(rw, r)
+
+		route := r.Pattern
+		if route == "" {
+			route = "unmatched"
+		}
+
+		method := methodLabel(r.Method)
+		m.requests.Inc(method, route, strconv.Itoa(rw.statusCode))
+		m.duration.Observe(time.Since(start).Seconds(), method, route)
+	})
+}
+
+// methodLabel сводит метод к стандартному набору: клиент может прислать любой
+// токен, и каждый новый породил бы отдельный временной ряд.
+func methodLabel(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+		http.MethodDelete, http.MethodConnect, http.MethodOptions, http.MethodTrace:
+		return method
+	default:
+		return "other"
+	}
+}
+
+// tracingMiddleware продолжает трассу из traceparent/tracestate входящего запроса
+// или начинает новую и возвращает контекст серверного спана в заголовках ответа.
+func tracingMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		if parent, ok := tracing.ParseTraceparent(r.Header.Get("traceparent")); ok {
+			parent.TraceState = r.Header.Get("tracestate")
+			ctx = tracing.ContextWithRemote(ctx, parent)
+		}
+
+		ctx, span := tracing.Default().Start(ctx, r.Method, tracing.KindServer)
+		defer span.End()
+
+		sc := span.Context()
+		w.Header().Set("traceparent", sc.Traceparent())
+		if sc.TraceState != "" {
+			w.Header().Set("tracestate", sc.TraceState)
+		}
+		ctx = logging.WithAttrs(ctx,
+			slog.String("trace_id", sc.TraceID.String()),
+			slog.String("span_id", sc.SpanID.String()),
+		)
+
+		rw := &responseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+		r = r.WithContext(ctx)
+
+		next.ServeHTTP(rw, r)
+
+		if r.Pattern != "" {
+			span.SetName(r.Pattern)
+		}
+		span.SetAttributes(
+			tracing.Attribute{Key: "http.request.method", Value: r.Method},
+			tracing.Attribute{Key: "url.path", Value: r.URL.Path},
+			tracing.Attribute{Key: "http.route", Value: r.Pattern},
+			tracing.Attribute{Key: "http.response.status_code", Value: rw.statusCode},
 		)
+		if rw.statusCode >= http.StatusInternalServerError {
+			span.SetStatus(tracing.StatusError, http.StatusText(rw.statusCode))
+		}
 	})
 }
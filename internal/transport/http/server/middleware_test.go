@@ -0,0 +1,133 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Ant-Tab-Shift/todos-service/internal/metrics"
+	"github.com/Ant-Tab-Shift/todos-service/internal/transport/http/problem"
+)
+
+func TestRecoveryMiddleware(t *testing.T) {
+	t.Run("panic before response", func(t *testing.T) {
+		handler := recoveryMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			panic("boom")
+		}))
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/todos", nil))
+
+		if rec.Code != http.StatusInternalServerError {
+			t.Errorf("status = %d, want 500", rec.Code)
+		}
+		if got := rec.Header().Get("Content-Type"); got != problem.ContentType {
+			t.Errorf("Content-Type = %q, want %q", got, problem.ContentType)
+		}
+	})
+
+	t.Run("panic after response started aborts", func(t *testing.T) {
+		handler := recoveryMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("partial"))
+			panic("boom")
+		}))
+
+		defer func() {
+			if recovered := recover(); recovered != http.ErrAbortHandler {
+				t.Errorf("recovered = %v, want http.ErrAbortHandler", recovered)
+			}
+		}()
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/todos", nil))
+	})
+}
+
+func TestTimeoutMiddleware(t *testing.T) {
+	deadlines := make(map[string]time.Duration)
+	waitForDeadline := func(w http.ResponseWriter, r *http.Request) {
+		deadline, ok := r.Context().Deadline()
+		if !ok {
+			deadlines[r.Pattern] = 0
+			return
+		}
+		deadlines[r.Pattern] = time.Until(deadline)
+
+		<-r.Context().Done()
+		if errors.Is(r.Context().Err(), context.DeadlineExceeded) {
+			w.WriteHeader(http.StatusGatewayTimeout)
+		}
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /todos", waitForDeadline)
+	mux.HandleFunc("POST /todos", waitForDeadline)
+	mux.HandleFunc("GET /ws", waitForDeadline)
+
+	cfg := TimeoutConfig{
+		Default: 20 * time.Millisecond,
+		Routes:  map[string]time.Duration{"POST /todos": 50 * time.Millisecond},
+	}
+	handler := timeoutMiddleware(cfg, mux, mux)
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		upgrade    bool
+		wantStatus int
+		wantMax    time.Duration
+	}{
+		{"default timeout", http.MethodGet, "/todos", false, http.StatusGatewayTimeout, 20 * time.Millisecond},
+		{"route timeout", http.MethodPost, "/todos", false, http.StatusGatewayTimeout, 50 * time.Millisecond},
+		{"websocket upgrade", http.MethodGet, "/ws", true, http.StatusOK, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.upgrade {
+				req.Header.Set("Upgrade", "websocket")
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if req.Pattern != tt.method+" "+tt.path {
+				t.Errorf("Pattern = %q, want route propagated to outer middleware", req.Pattern)
+			}
+
+			got := deadlines[req.Pattern]
+			if tt.wantMax == 0 && got != 0 {
+				t.Errorf("deadline = %v, want none", got)
+			}
+			if tt.wantMax > 0 && (got <= 0 || got > tt.wantMax) {
+				t.Errorf("deadline = %v, want within %v", got, tt.wantMax)
+			}
+		})
+	}
+}
+
+func TestMetricsMiddleware_MethodLabel(t *testing.T) {
+	registry := metrics.NewRegistry()
+	handler := metricsMiddleware(newHTTPMetrics(registry), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	for _, method := range []string{http.MethodGet, "PROPFIND", "get", "X-RANDOM-1"} {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(method, "/todos", nil))
+	}
+
+	var out strings.Builder
+	registry.WriteTo(&out)
+	for _, want := range []string{
+		`http_requests_total{method="GET",route="unmatched",status="200"} 1`,
+		`http_requests_total{method="other",route="unmatched",status="200"} 3`,
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("metrics missing %q:\n%s", want, out.String())
+		}
+	}
+}
@@ -0,0 +1,218 @@
+package server
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Ant-Tab-Shift/todos-service/internal/transport/http/identity"
+	"github.com/Ant-Tab-Shift/todos-service/internal/transport/http/problem"
+)
+
+// Limit задаёт token bucket: Rate запросов в секунду с запасом Burst.
+// Нулевой Rate отключает ограничение.
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+type RateLimitConfig struct {
+	Default Limit
+	// Routes переопределяет лимит для шаблонов маршрутов вида "POST /todos".
+	Routes         map[string]Limit
+	TrustedProxies []netip.Prefix
+	IdleTimeout    time.Duration
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+type RateLimiter struct {
+	mu        sync.Mutex
+	cfg       RateLimitConfig
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+func NewRateLimiter(cfg RateLimitConfig) *RateLimiter {
+	return &RateLimiter{
+		cfg:     cfg,
+		buckets: make(map[string]*bucket),
+		now:     time.Now,
+	}
+}
+
+// SetConfig применяет новые лимиты; накопленные токены сохраняются,
+// но не превышают новый Burst.
+func (l *RateLimiter) SetConfig(cfg RateLimitConfig) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.cfg = cfg
+}
+
+type decision struct {
+	allowed    bool
+	limit      int
+	remaining  int
+	reset      time.Duration
+	retryAfter time.Duration
+}
+
+func (l *RateLimiter) allow(route, client string) (decision, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	limit, scope := l.cfg.Default, "*"
+	if routeLimit, ok := l.cfg.Routes[route]; ok {
+		limit, scope = routeLimit, route
+	}
+	if limit.Rate <= 0 {
+		return decision{}, false
+	}
+
+	now := l.now()
+	l.sweep(now)
+
+	key := scope + "|" + client
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit.Burst), last: now}
+		l.buckets[key] = b
+	}
+
+	burst := float64(limit.Burst)
+	b.tokens = math.Min(burst, b.tokens+now.Sub(b.last).Seconds()*limit.Rate)
+	b.last = now
+
+	d := decision{limit: limit.Burst}
+	if b.tokens >= 1 {
+		b.tokens--
+		d.allowed = true
+	} else {
+		d.retryAfter = seconds((1 - b.tokens) / limit.Rate)
+	}
+	d.remaining = int(b.tokens)
+	d.reset = seconds((burst - b.tokens) / limit.Rate)
+
+	return d, true
+}
+
+// sweep удаляет давно не использованные корзины: к этому моменту они
+// заполнились бы полностью, поэтому удаление не меняет поведения.
+func (l *RateLimiter) sweep(now time.Time) {
+	idle := l.cfg.IdleTimeout
+	if idle <= 0 || now.Sub(l.lastSweep) < idle/2 {
+		return
+	}
+	l.lastSweep = now
+
+	for key, b := range l.buckets {
+		if now.Sub(b.last) > idle {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// clientKey определяет клиента: аутентифицированная личность важнее адреса.
+func (l *RateLimiter) clientKey(r *http.Request) string {
+	if id, ok := identity.FromContext(r.Context()); ok {
+		return "id:" + id
+	}
+
+	l.mu.Lock()
+	trusted := l.cfg.TrustedProxies
+	l.mu.Unlock()
+
+	return "ip:" + clientIP(r, trusted)
+}
+
+// clientIP доверяет X-Forwarded-For только если запрос пришёл от доверенного
+// прокси, и идёт по цепочке справа налево до первого недоверенного адреса.
+func clientIP(r *http.Request, trusted []netip.Prefix) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	addr, err := netip.ParseAddr(host)
+	if err != nil || !isTrusted(addr, trusted) {
+		return host
+	}
+
+	var hops []string
+	for _, header := range r.Header.Values("X-Forwarded-For") {
+		for _, hop := range strings.Split(header, ",") {
+			hops = append(hops, strings.TrimSpace(hop))
+		}
+	}
+
+	client := addr.Unmap().String()
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop, err := netip.ParseAddr(hops[i])
+		if err != nil {
+			// подделанное или битое звено: дальше цепочке верить нельзя
+			break
+		}
+		client = hop.Unmap().String()
+		if !isTrusted(hop, trusted) {
+			break
+		}
+	}
+
+	return client
+}
+
+func isTrusted(addr netip.Addr, trusted []netip.Prefix) bool {
+	addr = addr.Unmap()
+	for _, prefix := range trusted {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
+
+func ceilSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
+
+// rateLimitMiddleware определяет маршрут через mux до вызова обработчика,
+// чтобы применить лимит конкретного маршрута.
+func rateLimitMiddleware(l *RateLimiter, mux *http.ServeMux, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, route := mux.Handler(r)
+
+		d, limited := l.allow(route, l.clientKey(r))
+		if !limited {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Set("RateLimit-Limit", strconv.Itoa(d.limit))
+		w.Header().Set("RateLimit-Remaining", strconv.Itoa(d.remaining))
+		w.Header().Set("RateLimit-Reset", ceilSeconds(d.reset))
+
+		if !d.allowed {
+			// маршрут нужен метрикам и логам, хотя mux запрос не обработает
+			r.Pattern = route
+			w.Header().Set("Retry-After", ceilSeconds(d.retryAfter))
+			problem.Write(w, r, problem.New(http.StatusTooManyRequests, "rate limit exceeded, retry later"))
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,177 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/Ant-Tab-Shift/todos-service/internal/transport/http/identity"
+	"github.com/Ant-Tab-Shift/todos-service/internal/transport/http/problem"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func newLimitedMux(t *testing.T, cfg RateLimitConfig) (http.Handler, *RateLimiter, *fakeClock) {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	ok := func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) }
+	mux.HandleFunc("POST /todos", ok)
+	mux.HandleFunc("GET /todos", ok)
+
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	limiter := NewRateLimiter(cfg)
+	limiter.now = clock.Now
+
+	return rateLimitMiddleware(limiter, mux, mux), limiter, clock
+}
+
+func serve(handler http.Handler, method, remoteAddr string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, "/todos", nil)
+	req.RemoteAddr = remoteAddr
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestRateLimit_TokenBucket(t *testing.T) {
+	handler, _, clock := newLimitedMux(t, RateLimitConfig{Default: Limit{Rate: 0.5, Burst: 2}})
+
+	for i := range 2 {
+		rec := serve(handler, http.MethodGet, "192.0.2.1:1234")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("request %d: status = %d, want 200", i, rec.Code)
+		}
+		if got := rec.Header().Get("RateLimit-Limit"); got != "2" {
+			t.Errorf("RateLimit-Limit = %q, want 2", got)
+		}
+	}
+
+	rec := serve(handler, http.MethodGet, "192.0.2.1:1234")
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("status = %d, want 429", rec.Code)
+	}
+	if got := rec.Header().Get("Retry-After"); got != "2" {
+		t.Errorf("Retry-After = %q, want 2", got)
+	}
+	if got := rec.Header().Get("RateLimit-Remaining"); got != "0" {
+		t.Errorf("RateLimit-Remaining = %q, want 0", got)
+	}
+	if got := rec.Header().Get("RateLimit-Reset"); got != "4" {
+		t.Errorf("RateLimit-Reset = %q, want 4", got)
+	}
+	if got := rec.Header().Get("Content-Type"); got != problem.ContentType {
+		t.Errorf("Content-Type = %q, want %q", got, problem.ContentType)
+	}
+
+	if rec := serve(handler, http.MethodGet, "192.0.2.2:1234"); rec.Code != http.StatusOK {
+		t.Errorf("other client status = %d, want 200", rec.Code)
+	}
+
+	clock.now = clock.now.Add(2 * time.Second)
+	if rec := serve(handler, http.MethodGet, "192.0.2.1:1234"); rec.Code != http.StatusOK {
+		t.Errorf("status after refill = %d, want 200", rec.Code)
+	}
+}
+
+func TestRateLimit_PerRoute(t *testing.T) {
+	handler, _, _ := newLimitedMux(t, RateLimitConfig{
+		Routes: map[string]Limit{"POST /todos": {Rate: 1, Burst: 1}},
+	})
+
+	if rec := serve(handler, http.MethodPost, "192.0.2.1:1"); rec.Code != http.StatusOK {
+		t.Fatalf("first POST status = %d, want 200", rec.Code)
+	}
+	if rec := serve(handler, http.MethodPost, "192.0.2.1:1"); rec.Code != http.StatusTooManyRequests {
+		t.Errorf("second POST status = %d, want 429", rec.Code)
+	}
+
+	for range 5 {
+		rec := serve(handler, http.MethodGet, "192.0.2.1:1")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("GET status = %d, want 200 without default limit", rec.Code)
+		}
+		if rec.Header().Get("RateLimit-Limit") != "" {
+			t.Error("unlimited route must not send RateLimit headers")
+		}
+	}
+}
+
+func TestRateLimit_KeyedByIdentity(t *testing.T) {
+	handler, _, _ := newLimitedMux(t, RateLimitConfig{Default: Limit{Rate: 1, Burst: 1}})
+
+	for _, id := range []string{"CN=alice", "CN=bob"} {
+		req := httptest.NewRequest(http.MethodGet, "/todos", nil)
+		req.RemoteAddr = "192.0.2.1:1"
+		req = req.WithContext(identity.NewContext(context.Background(), id))
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Errorf("%s: status = %d, want 200", id, rec.Code)
+		}
+	}
+}
+
+func TestRateLimit_EvictsIdleBuckets(t *testing.T) {
+	handler, limiter, clock := newLimitedMux(t, RateLimitConfig{
+		Default:     Limit{Rate: 1, Burst: 1},
+		IdleTimeout: time.Minute,
+	})
+
+	serve(handler, http.MethodGet, "192.0.2.1:1")
+	serve(handler, http.MethodGet, "192.0.2.2:1")
+	if len(limiter.buckets) != 2 {
+		t.Fatalf("buckets = %d, want 2", len(limiter.buckets))
+	}
+
+	clock.now = clock.now.Add(2 * time.Minute)
+	serve(handler, http.MethodGet, "192.0.2.3:1")
+
+	if len(limiter.buckets) != 1 {
+		t.Errorf("buckets = %d after idle timeout, want 1", len(limiter.buckets))
+	}
+}
+
+func TestClientIP(t *testing.T) {
+	trusted := []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		forwarded  []string
+		want       string
+	}{
+		{"direct client", "192.0.2.1:1234", nil, "192.0.2.1"},
+		{"untrusted peer ignores header", "192.0.2.1:1234", []string{"198.51.100.7"}, "192.0.2.1"},
+		{"trusted proxy", "10.0.0.1:1234", []string{"198.51.100.7"}, "198.51.100.7"},
+		{"spoofed leftmost entry", "10.0.0.1:1234", []string{"1.1.1.1, 198.51.100.7, 10.0.0.2"}, "198.51.100.7"},
+		{"multiple headers", "10.0.0.1:1234", []string{"1.1.1.1", "198.51.100.7"}, "198.51.100.7"},
+		{"garbage stops the chain", "10.0.0.1:1234", []string{"198.51.100.7, nonsense"}, "10.0.0.1"},
+		{"only proxies", "10.0.0.1:1234", []string{"10.0.0.3, 10.0.0.2"}, "10.0.0.3"},
+		{"ipv6 client", "[2001:db8::1]:443", nil, "2001:db8::1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			for _, value := range tt.forwarded {
+				req.Header.Add("X-Forwarded-For", value)
+			}
+
+			if got := clientIP(req, trusted); got != tt.want {
+				t.Errorf("clientIP() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,43 +2,100 @@ package server
 
 import (
 	"context"
+	"crypto/tls"
 	"net"
 	"net/http"
 	"time"
 
+	"github.com/Ant-Tab-Shift/todos-service/internal/metrics"
 	"github.com/Ant-Tab-Shift/todos-service/internal/transport/http/models"
+	"github.com/Ant-Tab-Shift/todos-service/internal/transport/http/requestid"
 )
 
 type Handler interface {
 	Handlers() []models.Endpoint
 }
 
+type Config struct {
+	Addr         string
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+	IdleTimeout  time.Duration
+	// RateLimiter равен nil, если ограничение частоты запросов не нужно.
+	RateLimiter *RateLimiter
+	// CORS равен nil, если браузерные запросы с других origin не нужны.
+	CORS *CORS
+	// TLS равен nil для обычного HTTP.
+	TLS *tls.Config
+	// RequireClientCert отклоняет запросы без клиентского сертификата, кроме ClientCertExempt.
+	RequireClientCert bool
+	ClientCertExempt  []string
+	Timeouts          TimeoutConfig
+	// CompressionMinSize — минимальный размер сжимаемого ответа; 0 отключает сжатие.
+	CompressionMinSize int
+	// CacheControl сопоставляет шаблону маршрута значение заголовка Cache-Control.
+	CacheControl map[string]string
+	// Admin равен nil, если пути /admin/ закрыты для всех.
+	Admin *AdminAccess
+}
+
 type Server struct {
 	srv http.Server
+	mux *http.ServeMux
 }
 
-func New(baseContext context.Context, addr string) *Server {
-	return &Server{srv: http.Server{
-		Addr:         addr,
-		ReadTimeout:  5 * time.Second,
-		WriteTimeout: 5 * time.Second,
-		IdleTimeout:  10 * time.Second,
-		BaseContext: func(net.Listener) context.Context {
-			return baseContext
+func New(baseContext context.Context, cfg Config, registry *metrics.Registry) *Server {
+	mux := http.NewServeMux()
+
+	var handler http.Handler = recoveryMiddleware(timeoutMiddleware(cfg.Timeouts, mux, mux))
+	if len(cfg.CacheControl) > 0 {
+		handler = cacheControlMiddleware(cfg.CacheControl, mux, handler)
+	}
+	handler = adminMiddleware(cfg.Admin, mux, handler)
+	if cfg.RateLimiter != nil {
+		handler = rateLimitMiddleware(cfg.RateLimiter, mux, handler)
+	}
+	if cfg.RequireClientCert {
+		handler = clientCertMiddleware(cfg.ClientCertExempt, mux, handler)
+	}
+	// CORS снаружи лимита: preflight не расходует токены, а 429 доступен браузеру
+	if cfg.CORS != nil {
+		handler = corsMiddleware(cfg.CORS, mux, handler)
+	}
+	if cfg.CompressionMinSize > 0 {
+		handler = compressionMiddleware(cfg.CompressionMinSize, handler)
+	}
+	handler = metricsMiddleware(newHTTPMetrics(registry), handler)
+
+	return &Server{
+		srv: http.Server{
+			Addr:         cfg.Addr,
+			Handler:      identityMiddleware(requestid.Middleware(tracingMiddleware(loggingMiddleware(handler)))),
+			TLSConfig:    cfg.TLS,
+			ReadTimeout:  cfg.ReadTimeout,
+			WriteTimeout: cfg.WriteTimeout,
+			IdleTimeout:  cfg.IdleTimeout,
+			BaseContext: func(net.Listener) context.Context {
+				return baseContext
+			},
 		},
-	}}
+		mux: mux,
+	}
 }
 
-func (s *Server) RegisterHandlers(handler Handler) {
-	mux := http.NewServeMux()
-	for _, endpoint := range handler.Handlers() {
-		mux.HandleFunc(endpoint.Pattern, endpoint.Func)
+func (s *Server) RegisterHandlers(handlers ...Handler) {
+	for _, handler := range handlers {
+		for _, endpoint := range handler.Handlers() {
+			s.mux.HandleFunc(endpoint.Pattern, endpoint.Func)
+		}
 	}
-
-	s.srv.Handler = loggingMiddleware(mux)
 }
 
 func (s *Server) ListenAndServe() error {
+	if s.srv.TLSConfig != nil {
+		// сертификат отдаёт TLSConfig.GetCertificate, поэтому пути не нужны
+		return s.srv.ListenAndServeTLS("", "")
+	}
 	return s.srv.ListenAndServe()
 }
 
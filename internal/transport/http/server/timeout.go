@@ -0,0 +1,43 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type TimeoutConfig struct {
+	Default time.Duration
+	// Routes переопределяет таймаут для шаблонов маршрутов; 0 отключает его.
+	Routes map[string]time.Duration
+}
+
+func (cfg TimeoutConfig) forRoute(route string) time.Duration {
+	if timeout, ok := cfg.Routes[route]; ok {
+		return timeout
+	}
+	return cfg.Default
+}
+
+// timeoutMiddleware ограничивает r.Context() дедлайном маршрута: хранилище
+// проверяет ctx.Err() и прерывает работу, а обработчик отвечает 504.
+// WebSocket-соединения живут дольше любого запроса, поэтому не ограничиваются.
+func timeoutMiddleware(cfg TimeoutConfig, mux *http.ServeMux, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, route := mux.Handler(r)
+
+		timeout := cfg.forRoute(route)
+		if timeout <= 0 || strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+		// mux заполнил Pattern у копии запроса, а внешним middleware нужен маршрут
+		r.Pattern = route
+	})
+}
@@ -0,0 +1,184 @@
+package server
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/Ant-Tab-Shift/todos-service/internal/logging"
+	"github.com/Ant-Tab-Shift/todos-service/internal/transport/http/identity"
+	"github.com/Ant-Tab-Shift/todos-service/internal/transport/http/problem"
+)
+
+type TLSConfig struct {
+	CertFile string
+	KeyFile  string
+	// ClientCAFile включает mTLS: клиентские сертификаты проверяются этим CA.
+	ClientCAFile string
+	// ReloadInterval ограничивает, как часто проверяется изменение файлов сертификата.
+	ReloadInterval time.Duration
+}
+
+func NewTLSConfig(cfg TLSConfig) (*tls.Config, error) {
+	certs, err := NewCertReloader(cfg.CertFile, cfg.KeyFile, cfg.ReloadInterval)
+	if err != nil {
+		return nil, err
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: certs.GetCertificate,
+	}
+
+	if cfg.ClientCAFile != "" {
+		data, err := os.ReadFile(cfg.ClientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read client CA: %w", err)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("no certificates found in client CA file %s", cfg.ClientCAFile)
+		}
+
+		tlsConfig.ClientCAs = pool
+		// наличие сертификата проверяет clientCertMiddleware, чтобы пробы работали без него
+		tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+
+	return tlsConfig, nil
+}
+
+// CertReloader подхватывает обновлённые на диске сертификат и ключ без перезапуска.
+type CertReloader struct {
+	certFile string
+	keyFile  string
+	interval time.Duration
+
+	mu      sync.RWMutex
+	cert    *tls.Certificate
+	modTime time.Time
+	checked time.Time
+}
+
+func NewCertReloader(certFile, keyFile string, interval time.Duration) (*CertReloader, error) {
+	r := &CertReloader{certFile: certFile, keyFile: keyFile, interval: interval, checked: time.Now()}
+
+	modTime, err := r.stat()
+	if err != nil {
+		return nil, err
+	}
+	if err = r.load(modTime); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+func (r *CertReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	cert, due := r.cert, time.Since(r.checked) >= r.interval
+	r.mu.RUnlock()
+
+	if due {
+		r.reload()
+
+		r.mu.RLock()
+		cert = r.cert
+		r.mu.RUnlock()
+	}
+
+	return cert, nil
+}
+
+// reload не заменяет рабочий сертификат, если новый не загрузился:
+// файлы могут быть записаны не полностью в момент ротации.
+func (r *CertReloader) reload() {
+	modTime, err := r.stat()
+
+	r.mu.Lock()
+	r.checked = time.Now()
+	unchanged := err == nil && modTime.Equal(r.modTime)
+	r.mu.Unlock()
+
+	if unchanged {
+		return
+	}
+	if err == nil {
+		err = r.load(modTime)
+	}
+	if err != nil {
+		slog.Warn("failed to reload TLS certificate, keeping previous one", logging.Err(err))
+		return
+	}
+	slog.Info("TLS certificate reloaded", "cert_file", r.certFile)
+}
+
+func (r *CertReloader) load(modTime time.Time) error {
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load TLS key pair: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.cert = &cert
+	r.modTime = modTime
+
+	return nil
+}
+
+func (r *CertReloader) stat() (time.Time, error) {
+	var latest time.Time
+	for _, path := range []string{r.certFile, r.keyFile} {
+		info, err := os.Stat(path)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("failed to stat TLS file: %w", err)
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest, nil
+}
+
+// identityMiddleware делает субъект проверенного клиентского сертификата
+// личностью запроса; сертификаты без цепочки до CA не учитываются.
+func identityMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		subject := r.TLS.VerifiedChains[0][0].Subject.String()
+		ctx := identity.NewContext(r.Context(), subject)
+		ctx = logging.WithAttrs(ctx, slog.String("identity", subject))
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+var errClientCertRequired = errors.New("client certificate is required")
+
+// clientCertMiddleware отклоняет запросы без проверенного сертификата,
+// кроме путей из exempt (пробы оркестратора не умеют mTLS).
+func clientCertMiddleware(exempt []string, mux *http.ServeMux, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := identity.FromContext(r.Context()); ok || slices.Contains(exempt, r.URL.Path) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		_, r.Pattern = mux.Handler(r)
+		problem.Write(w, r, problem.New(http.StatusUnauthorized, errClientCertRequired.Error()))
+	})
+}
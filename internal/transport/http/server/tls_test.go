@@ -0,0 +1,229 @@
+package server
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"log"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Ant-Tab-Shift/todos-service/internal/transport/http/identity"
+)
+
+type testCert struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+func newTestCert(t *testing.T, cn string, serial int64, parent *testCert, usage x509.ExtKeyUsage) *testCert {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: cn, Organization: []string{"todos"}},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+	}
+
+	signer, signerKey := template, key
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.ExtKeyUsage = nil
+	} else {
+		signer, signerKey = parent.cert, parent.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("failed to parse certificate: %v", err)
+	}
+
+	return &testCert{cert: cert, key: key}
+}
+
+func (c *testCert) write(t *testing.T, dir, name string) (certFile, keyFile string) {
+	t.Helper()
+
+	keyDER, err := x509.MarshalECPrivateKey(c.key)
+	if err != nil {
+		t.Fatalf("failed to marshal key: %v", err)
+	}
+
+	certFile = filepath.Join(dir, name+".pem")
+	keyFile = filepath.Join(dir, name+".key")
+	writePEM(t, certFile, "CERTIFICATE", c.cert.Raw)
+	writePEM(t, keyFile, "EC PRIVATE KEY", keyDER)
+
+	return certFile, keyFile
+}
+
+func (c *testCert) tls() tls.Certificate {
+	return tls.Certificate{Certificate: [][]byte{c.cert.Raw}, PrivateKey: c.key}
+}
+
+func writePEM(t *testing.T, path, typ string, der []byte) {
+	t.Helper()
+
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: typ, Bytes: der}), 0o600); err != nil {
+		t.Fatalf("failed to write %s: %v", path, err)
+	}
+}
+
+func serveTLS(t *testing.T, tlsConfig *tls.Config, handler http.Handler) string {
+	t.Helper()
+
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", tlsConfig)
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	srv := &http.Server{Handler: handler, ErrorLog: log.New(io.Discard, "", 0)}
+	go srv.Serve(listener)
+	t.Cleanup(func() { srv.Close() })
+
+	return "https://" + listener.Addr().String()
+}
+
+func newClient(ca *testCert, certs ...tls.Certificate) *http.Client {
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.cert)
+
+	return &http.Client{
+		Timeout: 5 * time.Second,
+		Transport: &http.Transport{
+			DisableKeepAlives: true,
+			TLSClientConfig:   &tls.Config{RootCAs: pool, Certificates: certs},
+		},
+	}
+}
+
+func TestCertReloader_PicksUpRotatedCertificate(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCert(t, "test CA", 1, nil, x509.ExtKeyUsageServerAuth)
+	certFile, keyFile := newTestCert(t, "localhost", 2, ca, x509.ExtKeyUsageServerAuth).write(t, dir, "server")
+
+	tlsConfig, err := NewTLSConfig(TLSConfig{CertFile: certFile, KeyFile: keyFile})
+	if err != nil {
+		t.Fatalf("NewTLSConfig failed: %v", err)
+	}
+	url := serveTLS(t, tlsConfig, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	client := newClient(ca)
+
+	servedSerial := func() int64 {
+		t.Helper()
+		resp, err := client.Get(url)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		resp.Body.Close()
+		return resp.TLS.PeerCertificates[0].SerialNumber.Int64()
+	}
+
+	if got := servedSerial(); got != 2 {
+		t.Fatalf("serial = %d, want 2", got)
+	}
+
+	// битый файл во время ротации не должен ломать рукопожатия
+	if err = os.WriteFile(certFile, []byte("partial"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	future := time.Now().Add(time.Minute)
+	_ = os.Chtimes(certFile, future, future)
+	if got := servedSerial(); got != 2 {
+		t.Errorf("serial after broken rotation = %d, want previous 2", got)
+	}
+
+	newTestCert(t, "localhost", 3, ca, x509.ExtKeyUsageServerAuth).write(t, dir, "server")
+	future = future.Add(time.Minute)
+	_ = os.Chtimes(certFile, future, future)
+	if got := servedSerial(); got != 3 {
+		t.Errorf("serial after rotation = %d, want 3", got)
+	}
+}
+
+func TestMutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCert(t, "test CA", 1, nil, x509.ExtKeyUsageServerAuth)
+	certFile, keyFile := newTestCert(t, "localhost", 2, ca, x509.ExtKeyUsageServerAuth).write(t, dir, "server")
+	caFile, _ := ca.write(t, dir, "ca")
+
+	tlsConfig, err := NewTLSConfig(TLSConfig{CertFile: certFile, KeyFile: keyFile, ClientCAFile: caFile, ReloadInterval: time.Minute})
+	if err != nil {
+		t.Fatalf("NewTLSConfig failed: %v", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /whoami", func(w http.ResponseWriter, r *http.Request) {
+		id, _ := identity.FromContext(r.Context())
+		io.WriteString(w, id)
+	})
+	mux.HandleFunc("GET /readyz", func(w http.ResponseWriter, r *http.Request) {})
+	url := serveTLS(t, tlsConfig, identityMiddleware(clientCertMiddleware([]string{"/readyz"}, mux, mux)))
+
+	alice := newTestCert(t, "alice", 10, ca, x509.ExtKeyUsageClientAuth)
+	otherCA := newTestCert(t, "other CA", 20, nil, x509.ExtKeyUsageClientAuth)
+	mallory := newTestCert(t, "mallory", 21, otherCA, x509.ExtKeyUsageClientAuth)
+
+	resp, err := newClient(ca, alice.tls()).Get(url + "/whoami")
+	if err != nil {
+		t.Fatalf("request with client cert failed: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "CN=alice,O=todos" {
+		t.Errorf("identity = %q, want CN=alice,O=todos", body)
+	}
+
+	anonymous := newClient(ca)
+	resp, err = anonymous.Get(url + "/whoami")
+	if err != nil {
+		t.Fatalf("request without client cert failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("status without client cert = %d, want 401", resp.StatusCode)
+	}
+
+	resp, err = anonymous.Get(url + "/readyz")
+	if err != nil {
+		t.Fatalf("probe request failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("probe status = %d, want 200", resp.StatusCode)
+	}
+
+	// клиент сам не предложит сертификат чужого CA, поэтому отдаём его принудительно
+	forged := newClient(ca)
+	forged.Transport.(*http.Transport).TLSClientConfig.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+		cert := mallory.tls()
+		return &cert, nil
+	}
+	if _, err = forged.Get(url + "/whoami"); err == nil {
+		t.Error("certificate from unknown CA must fail the handshake")
+	}
+}
@@ -0,0 +1,285 @@
+package jsonrpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/Ant-Tab-Shift/todos-service/internal/domain"
+	"github.com/Ant-Tab-Shift/todos-service/internal/logging"
+	"github.com/Ant-Tab-Shift/todos-service/internal/transport/http/dto"
+	"github.com/Ant-Tab-Shift/todos-service/internal/transport/http/models"
+	"github.com/Ant-Tab-Shift/todos-service/internal/transport/http/problem"
+	"github.com/Ant-Tab-Shift/todos-service/internal/validation"
+)
+
+const maxBodySize = 1 << 20
+
+type TaskService interface {
+	Create(ctx context.Context, task domain.TaskSchema) (domain.Task, error)
+	GetByID(ctx context.Context, id uint64) (domain.Task, error)
+	GetAll(ctx context.Context) ([]domain.Task, error)
+	Update(ctx context.Context, id uint64, task domain.TaskSchema, keep ...domain.TaskField) error
+	Delete(ctx context.Context, id uint64) error
+}
+
+type method func(ctx context.Context, params json.RawMessage) (any, error)
+
+type Handler struct {
+	service TaskService
+	methods map[string]method
+}
+
+func NewHandler(service TaskService) *Handler {
+	h := &Handler{service: service}
+	h.methods = map[string]method{
+		"tasks.create": h.create,
+		"tasks.get":    h.get,
+		"tasks.list":   h.list,
+		"tasks.update": h.update,
+		"tasks.delete": h.delete,
+	}
+
+	return h
+}
+
+func (h *Handler) Serve(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodySize))
+	if err != nil {
+		writeJSON(r.Context(), w, errorResponse(nil, CodeInvalidRequest, "request body too large"))
+		return
+	}
+
+	body = bytes.TrimSpace(body)
+	if len(body) > 0 && body[0] == '[' {
+		h.serveBatch(w, r.Context(), body)
+		return
+	}
+
+	var req Request
+	if err = json.Unmarshal(body, &req); err != nil {
+		writeJSON(r.Context(), w, parseOrInvalid(body))
+		return
+	}
+
+	response, ok := h.call(r.Context(), req)
+	if !ok {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	writeJSON(r.Context(), w, response)
+}
+
+func (h *Handler) Handlers() []models.Endpoint {
+	return []models.Endpoint{
+		{
+			Pattern: "POST /rpc",
+			Func:    h.Serve,
+			Doc: &models.Doc{
+				Summary:     "Call task methods over JSON-RPC 2.0",
+				Description: "Accepts a single request or a batch. Methods: tasks.create, tasks.get, tasks.list, tasks.update, tasks.delete.",
+				Tags:        []string{"rpc"},
+				Request:     Request{},
+				Responses: map[int]models.Response{
+					http.StatusOK:        {Description: "JSON-RPC response or batch of responses", Body: Response{}},
+					http.StatusNoContent: {Description: "Only notifications were sent"},
+				},
+			},
+		},
+	}
+}
+
+func (h *Handler) serveBatch(w http.ResponseWriter, ctx context.Context, body []byte) {
+	var batch []json.RawMessage
+	if err := json.Unmarshal(body, &batch); err != nil {
+		writeJSON(ctx, w, errorResponse(nil, CodeParseError, "parse error"))
+		return
+	}
+	if len(batch) == 0 {
+		writeJSON(ctx, w, errorResponse(nil, CodeInvalidRequest, "invalid request"))
+		return
+	}
+
+	responses := make([]Response, 0, len(batch))
+	for _, raw := range batch {
+		var req Request
+		if err := json.Unmarshal(raw, &req); err != nil {
+			responses = append(responses, errorResponse(nil, CodeInvalidRequest, "invalid request"))
+			continue
+		}
+
+		if response, ok := h.call(ctx, req); ok {
+			responses = append(responses, response)
+		}
+	}
+
+	if len(responses) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	writeJSON(ctx, w, responses)
+}
+
+func (h *Handler) call(ctx context.Context, req Request) (Response, bool) {
+	if !req.valid() {
+		return errorResponse(req.ID, CodeInvalidRequest, "invalid request"), true
+	}
+
+	fn, ok := h.methods[req.Method]
+	if !ok {
+		if req.isNotification() {
+			return Response{}, false
+		}
+		return errorResponse(req.ID, CodeMethodNotFound, "method not found"), true
+	}
+
+	result, err := fn(ctx, req.Params)
+	if req.isNotification() {
+		return Response{}, false
+	}
+	if err != nil {
+		var rpcErr *Error
+		if !errors.As(err, &rpcErr) {
+			rpcErr = mapError(err)
+		}
+		return Response{JSONRPC: version, Error: rpcErr, ID: req.ID}, true
+	}
+
+	return Response{JSONRPC: version, Result: result, ID: req.ID}, true
+}
+
+type idParams struct {
+	ID uint64 `json:"id"`
+}
+
+type updateParams struct {
+	ID uint64 `json:"id"`
+	dto.UpdateTaskRequest
+}
+
+func (h *Handler) create(ctx context.Context, params json.RawMessage) (any, error) {
+	var req dto.CreateTaskRequest
+	if err := decodeParams(params, &req); err != nil {
+		return nil, err
+	}
+
+	task, err := h.service.Create(ctx, dto.FromCreateTaskRequest(req))
+	if err != nil {
+		return nil, err
+	}
+
+	return dto.ToTaskResponse(&task), nil
+}
+
+func (h *Handler) get(ctx context.Context, params json.RawMessage) (any, error) {
+	var req idParams
+	if err := decodeParams(params, &req); err != nil {
+		return nil, err
+	}
+
+	task, err := h.service.GetByID(ctx, req.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return dto.ToTaskResponse(&task), nil
+}
+
+func (h *Handler) list(ctx context.Context, params json.RawMessage) (any, error) {
+	tasks, err := h.service.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return dto.ToTaskListResponse(tasks), nil
+}
+
+func (h *Handler) update(ctx context.Context, params json.RawMessage) (any, error) {
+	var req updateParams
+	if err := decodeParams(params, &req); err != nil {
+		return nil, err
+	}
+
+	schema, keep := dto.FromUpdateTaskRequest(req.UpdateTaskRequest)
+	if err := h.service.Update(ctx, req.ID, schema, keep...); err != nil {
+		return nil, err
+	}
+
+	task, err := h.service.GetByID(ctx, req.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return dto.ToTaskResponse(&task), nil
+}
+
+func (h *Handler) delete(ctx context.Context, params json.RawMessage) (any, error) {
+	var req idParams
+	if err := decodeParams(params, &req); err != nil {
+		return nil, err
+	}
+
+	if err := h.service.Delete(ctx, req.ID); err != nil {
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+func decodeParams(params json.RawMessage, dst any) error {
+	trimmed := bytes.TrimSpace(params)
+	if len(trimmed) == 0 || trimmed[0] != '{' {
+		return &Error{Code: CodeInvalidParams, Message: "params must be an object"}
+	}
+
+	// как и HTTP-обработчики, неизвестные поля и неверные типы перечисляются все сразу
+	err := validation.DecodeJSON(trimmed, dst)
+	var invalid validation.Errors
+	if errors.As(err, &invalid) {
+		return &Error{Code: CodeInvalidParams, Message: "invalid params", Data: problem.FieldErrors(invalid)}
+	}
+
+	return err
+}
+
+func mapError(err error) *Error {
+	var invalid validation.Errors
+	switch {
+	case errors.As(err, &invalid):
+		return &Error{Code: CodeInvalidParams, Message: invalid.Error(), Data: problem.FieldErrors(invalid)}
+	case errors.Is(err, domain.ErrNotExists):
+		return &Error{Code: CodeNotFound, Message: "task not found"}
+	case errors.Is(err, domain.ErrAlreadyExists):
+		return &Error{Code: CodeConflict, Message: "task already exists"}
+	case errors.Is(err, domain.ErrEmptyTitle):
+		return &Error{Code: CodeInvalidParams, Message: domain.ErrEmptyTitle.Error()}
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return &Error{Code: CodeCanceled, Message: "request cancelled"}
+	default:
+		return &Error{Code: CodeInternalError, Message: "internal error"}
+	}
+}
+
+func parseOrInvalid(body []byte) Response {
+	if json.Valid(body) {
+		return errorResponse(nil, CodeInvalidRequest, "invalid request")
+	}
+
+	return errorResponse(nil, CodeParseError, "parse error")
+}
+
+func writeJSON(ctx context.Context, w http.ResponseWriter, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		// ответ уже начат, поэтому ошибку остаётся только записать в лог
+		slog.ErrorContext(ctx, "failed to encode response", logging.Err(err))
+	}
+}
@@ -0,0 +1,249 @@
+package jsonrpc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Ant-Tab-Shift/todos-service/internal/domain"
+)
+
+type mockTaskService struct {
+	tasks map[uint64]domain.Task
+}
+
+func (m *mockTaskService) Create(ctx context.Context, schema domain.TaskSchema) (domain.Task, error) {
+	if schema.Title == "" {
+		return domain.Task{}, domain.ErrEmptyTitle
+	}
+	task := domain.Task{ID: uint64(len(m.tasks) + 1), TaskSchema: schema}
+	m.tasks[task.ID] = task
+	return task, nil
+}
+
+func (m *mockTaskService) GetByID(ctx context.Context, id uint64) (domain.Task, error) {
+	task, ok := m.tasks[id]
+	if !ok {
+		return domain.Task{}, domain.ErrNotExists
+	}
+	return task, nil
+}
+
+func (m *mockTaskService) GetAll(ctx context.Context) ([]domain.Task, error) {
+	tasks := make([]domain.Task, 0, len(m.tasks))
+	for _, task := range m.tasks {
+		tasks = append(tasks, task)
+	}
+	return tasks, nil
+}
+
+func (m *mockTaskService) Update(ctx context.Context, id uint64, schema domain.TaskSchema, keep ...domain.TaskField) error {
+	if _, ok := m.tasks[id]; !ok {
+		return domain.ErrNotExists
+	}
+	m.tasks[id] = domain.Task{ID: id, TaskSchema: schema}
+	return nil
+}
+
+func (m *mockTaskService) Delete(ctx context.Context, id uint64) error {
+	// особые ID имитируют ошибки, которые обработчик переводит в свои коды
+	switch id {
+	case 409:
+		return domain.ErrAlreadyExists
+	case 499:
+		return fmt.Errorf("failed to delete task: %w", context.Canceled)
+	case 504:
+		return context.DeadlineExceeded
+	}
+	if _, ok := m.tasks[id]; !ok {
+		return domain.ErrNotExists
+	}
+	delete(m.tasks, id)
+	return nil
+}
+
+func call(t *testing.T, h *Handler, body string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodPost, "/rpc", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	h.Serve(rec, req)
+
+	return rec
+}
+
+func decodeResponse(t *testing.T, rec *httptest.ResponseRecorder) map[string]json.RawMessage {
+	t.Helper()
+
+	var resp map[string]json.RawMessage
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode response failed: %v, body = %s", err, rec.Body.String())
+	}
+
+	return resp
+}
+
+func errorCode(t *testing.T, resp map[string]json.RawMessage) int {
+	t.Helper()
+
+	var rpcErr Error
+	if err := json.Unmarshal(resp["error"], &rpcErr); err != nil {
+		t.Fatalf("response has no error: %v", resp)
+	}
+
+	return rpcErr.Code
+}
+
+func TestHandler_CreateAndGet(t *testing.T) {
+	h := NewHandler(&mockTaskService{tasks: map[uint64]domain.Task{}})
+
+	resp := decodeResponse(t, call(t, h, `{"jsonrpc":"2.0","method":"tasks.create","params":{"title":"Task"},"id":1}`))
+	if _, ok := resp["error"]; ok {
+		t.Fatalf("unexpected error: %s", resp["error"])
+	}
+	if string(resp["id"]) != "1" {
+		t.Errorf("id = %s, want 1", resp["id"])
+	}
+
+	resp = decodeResponse(t, call(t, h, `{"jsonrpc":"2.0","method":"tasks.get","params":{"id":1},"id":"abc"}`))
+	var task struct {
+		ID    uint64 `json:"id"`
+		Title string `json:"title"`
+	}
+	if err := json.Unmarshal(resp["result"], &task); err != nil {
+		t.Fatalf("decode result failed: %v", err)
+	}
+	if task.ID != 1 || task.Title != "Task" {
+		t.Errorf("task = %+v, want task 1 'Task'", task)
+	}
+	if string(resp["id"]) != `"abc"` {
+		t.Errorf("id = %s, want \"abc\"", resp["id"])
+	}
+}
+
+func TestHandler_ErrorCodes(t *testing.T) {
+	h := NewHandler(&mockTaskService{tasks: map[uint64]domain.Task{}})
+
+	tests := []struct {
+		name string
+		body string
+		code int
+	}{
+		{"parse error", `{"jsonrpc":"2.0","method"`, CodeParseError},
+		{"invalid version", `{"jsonrpc":"1.0","method":"tasks.list","id":1}`, CodeInvalidRequest},
+		{"not an object", `42`, CodeInvalidRequest},
+		{"unknown method", `{"jsonrpc":"2.0","method":"tasks.archive","id":1}`, CodeMethodNotFound},
+		{"positional params", `{"jsonrpc":"2.0","method":"tasks.get","params":[1],"id":1}`, CodeInvalidParams},
+		{"empty title", `{"jsonrpc":"2.0","method":"tasks.create","params":{"title":""},"id":1}`, CodeInvalidParams},
+		{"not found", `{"jsonrpc":"2.0","method":"tasks.delete","params":{"id":9},"id":1}`, CodeNotFound},
+		{"unknown param", `{"jsonrpc":"2.0","method":"tasks.get","params":{"id":1,"force":true},"id":1}`, CodeInvalidParams},
+		{"task exists", `{"jsonrpc":"2.0","method":"tasks.delete","params":{"id":409},"id":1}`, CodeConflict},
+		{"canceled", `{"jsonrpc":"2.0","method":"tasks.delete","params":{"id":499},"id":1}`, CodeCanceled},
+		{"timed out", `{"jsonrpc":"2.0","method":"tasks.delete","params":{"id":504},"id":1}`, CodeCanceled},
+		{"empty batch", `[]`, CodeInvalidRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := call(t, h, tt.body)
+			if rec.Code != http.StatusOK {
+				t.Errorf("status = %d, want 200", rec.Code)
+			}
+
+			resp := decodeResponse(t, rec)
+			if code := errorCode(t, resp); code != tt.code {
+				t.Errorf("code = %d, want %d", code, tt.code)
+			}
+			if _, ok := resp["result"]; ok {
+				t.Error("error response must not contain result")
+			}
+		})
+	}
+}
+
+func TestHandler_InvalidParamsData(t *testing.T) {
+	h := NewHandler(&mockTaskService{tasks: map[uint64]domain.Task{}})
+
+	resp := decodeResponse(t, call(t, h, `{"jsonrpc":"2.0","method":"tasks.create","params":{"title":1,"color":"red"},"id":1}`))
+	var rpcErr struct {
+		Code int `json:"code"`
+		Data []struct {
+			Field string `json:"field"`
+			Code  string `json:"code"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(resp["error"], &rpcErr); err != nil {
+		t.Fatalf("decode error failed: %v", err)
+	}
+
+	if rpcErr.Code != CodeInvalidParams {
+		t.Errorf("code = %d, want %d", rpcErr.Code, CodeInvalidParams)
+	}
+	// все неверные поля перечислены сразу, по алфавиту
+	want := "[{color unknown_field} {title invalid_type}]"
+	if got := fmt.Sprint(rpcErr.Data); got != want {
+		t.Errorf("data = %s, want %s", got, want)
+	}
+}
+
+func TestHandler_Notification(t *testing.T) {
+	service := &mockTaskService{tasks: map[uint64]domain.Task{}}
+	h := NewHandler(service)
+
+	rec := call(t, h, `{"jsonrpc":"2.0","method":"tasks.create","params":{"title":"Quiet"}}`)
+	if rec.Code != http.StatusNoContent {
+		t.Errorf("status = %d, want 204", rec.Code)
+	}
+	if rec.Body.Len() != 0 {
+		t.Errorf("body = %s, want empty", rec.Body.String())
+	}
+	if len(service.tasks) != 1 {
+		t.Error("notification should still be executed")
+	}
+}
+
+func TestHandler_Batch(t *testing.T) {
+	h := NewHandler(&mockTaskService{tasks: map[uint64]domain.Task{}})
+
+	body := `[
+		{"jsonrpc":"2.0","method":"tasks.create","params":{"title":"A"},"id":1},
+		{"jsonrpc":"2.0","method":"tasks.create","params":{"title":"B"}},
+		{"jsonrpc":"2.0","method":"tasks.get","params":{"id":42},"id":2},
+		1,
+		{"jsonrpc":"2.0","method":"tasks.delete","params":{"id":1},"id":null}
+	]`
+
+	var responses []map[string]json.RawMessage
+	if err := json.Unmarshal(call(t, h, body).Body.Bytes(), &responses); err != nil {
+		t.Fatalf("decode batch failed: %v", err)
+	}
+	if len(responses) != 4 {
+		t.Fatalf("len(responses) = %d, want 4", len(responses))
+	}
+
+	if _, ok := responses[0]["result"]; !ok {
+		t.Errorf("responses[0] = %v, want result", responses[0])
+	}
+	if code := errorCode(t, responses[1]); code != CodeNotFound {
+		t.Errorf("responses[1] code = %d, want %d", code, CodeNotFound)
+	}
+	if code := errorCode(t, responses[2]); code != CodeInvalidRequest {
+		t.Errorf("responses[2] code = %d, want %d", code, CodeInvalidRequest)
+	}
+	if string(responses[3]["result"]) != "null" || string(responses[3]["id"]) != "null" {
+		t.Errorf("responses[3] = %v, want null result for null id", responses[3])
+	}
+}
+
+func TestHandler_BatchOfNotifications(t *testing.T) {
+	h := NewHandler(&mockTaskService{tasks: map[uint64]domain.Task{}})
+
+	rec := call(t, h, `[{"jsonrpc":"2.0","method":"tasks.list"}]`)
+	if rec.Code != http.StatusNoContent {
+		t.Errorf("status = %d, want 204", rec.Code)
+	}
+}
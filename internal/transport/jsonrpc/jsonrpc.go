@@ -0,0 +1,95 @@
+package jsonrpc
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+const version = "2.0"
+
+const (
+	CodeParseError     = -32700
+	CodeInvalidRequest = -32600
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+	CodeInternalError  = -32603
+	CodeNotFound       = -32001
+	CodeConflict       = -32002
+	CodeCanceled       = -32003
+)
+
+type Request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	ID      json.RawMessage `json:"id,omitempty"`
+}
+
+type Response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Result  any             `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+	ID      json.RawMessage `json:"id"`
+}
+
+func (r Response) MarshalJSON() ([]byte, error) {
+	// по спецификации ответ содержит ровно одно из полей result и error
+	if r.Error != nil {
+		return json.Marshal(struct {
+			JSONRPC string          `json:"jsonrpc"`
+			Error   *Error          `json:"error"`
+			ID      json.RawMessage `json:"id"`
+		}{r.JSONRPC, r.Error, r.ID})
+	}
+
+	return json.Marshal(struct {
+		JSONRPC string          `json:"jsonrpc"`
+		Result  any             `json:"result"`
+		ID      json.RawMessage `json:"id"`
+	}{r.JSONRPC, r.Result, r.ID})
+}
+
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    any    `json:"data,omitempty"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (r *Request) isNotification() bool {
+	return r.ID == nil
+}
+
+func (r *Request) valid() bool {
+	if r.JSONRPC != version || r.Method == "" {
+		return false
+	}
+	if r.ID == nil {
+		return true
+	}
+
+	switch trimmed := bytes.TrimSpace(r.ID); {
+	case bytes.Equal(trimmed, []byte("null")):
+		return true
+	case len(trimmed) > 0 && trimmed[0] == '"':
+		return true
+	default:
+		var number json.Number
+		return json.Unmarshal(trimmed, &number) == nil
+	}
+}
+
+func errorResponse(id json.RawMessage, code int, message string) Response {
+	if id == nil {
+		id = json.RawMessage("null")
+	}
+
+	return Response{
+		JSONRPC: version,
+		Error:   &Error{Code: code, Message: message},
+		ID:      id,
+	}
+}
@@ -0,0 +1,249 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/Ant-Tab-Shift/todos-service/internal/domain"
+	"github.com/Ant-Tab-Shift/todos-service/internal/logging"
+	"github.com/Ant-Tab-Shift/todos-service/internal/transport/http/dto"
+	"github.com/Ant-Tab-Shift/todos-service/internal/transport/http/models"
+	"github.com/Ant-Tab-Shift/todos-service/internal/transport/http/problem"
+	"github.com/Ant-Tab-Shift/todos-service/internal/validation"
+)
+
+const (
+	pongWait   = 60 * time.Second
+	pingPeriod = pongWait * 9 / 10
+)
+
+var (
+	errInvalidData = errors.New("invalid command data")
+	errUnknownOp   = errors.New("unknown command op")
+)
+
+type TaskService interface {
+	Create(ctx context.Context, task domain.TaskSchema) (domain.Task, error)
+	GetByID(ctx context.Context, id uint64) (domain.Task, error)
+	Update(ctx context.Context, id uint64, task domain.TaskSchema, keep ...domain.TaskField) error
+	Delete(ctx context.Context, id uint64) error
+}
+
+type Command struct {
+	RequestID string          `json:"request_id,omitempty"`
+	Op        string          `json:"op"`
+	ID        uint64          `json:"id,omitempty"`
+	Data      json.RawMessage `json:"data,omitempty"`
+}
+
+type Reply struct {
+	Type      string            `json:"type"`
+	RequestID string            `json:"request_id,omitempty"`
+	Task      *dto.TaskResponse `json:"task,omitempty"`
+	Error     string            `json:"error,omitempty"`
+}
+
+type Handler struct {
+	service     TaskService
+	hub         *Hub
+	allowOrigin func(origin string) bool
+}
+
+// NewHandler создаёт обработчик; allowOrigin решает, каким сторонним origin можно
+// открывать соединение, nil разрешает только запросы со своего хоста и не из браузера.
+func NewHandler(service TaskService, hub *Hub, allowOrigin func(origin string) bool) *Handler {
+	return &Handler{
+		service:     service,
+		hub:         hub,
+		allowOrigin: allowOrigin,
+	}
+}
+
+func (h *Handler) Serve(w http.ResponseWriter, r *http.Request) {
+	conn, err := Upgrade(w, r, h.allowOrigin)
+	if err != nil {
+		if errors.Is(err, ErrForbiddenOrigin) {
+			problem.Write(w, r, problem.New(http.StatusForbidden, "origin is not allowed"))
+			return
+		}
+		if errors.Is(err, ErrBadHandshake) {
+			problem.Write(w, r, problem.New(http.StatusBadRequest, err.Error()))
+			return
+		}
+		slog.ErrorContext(r.Context(), "websocket: upgrade failed", logging.Err(err))
+		return
+	}
+	conn.SetReadTimeout(pongWait)
+
+	c := h.hub.register(conn)
+	defer h.hub.unregister(c)
+
+	go h.writeLoop(r.Context(), c)
+	h.readLoop(r.Context(), c)
+}
+
+func (h *Handler) Handlers() []models.Endpoint {
+	return []models.Endpoint{
+		{
+			Pattern: "GET /ws",
+			Func:    h.Serve,
+			Doc: &models.Doc{
+				Summary: "Open a WebSocket sync channel",
+				Description: "RFC 6455 upgrade. The server pushes task events and accepts create/update/delete commands. " +
+					"Browser handshakes from another origin must match cors.allowed_origins.",
+				Tags: []string{"realtime"},
+				Responses: map[int]models.Response{
+					http.StatusSwitchingProtocols: {Description: "Connection upgraded to WebSocket"},
+					http.StatusBadRequest:         {Description: "Invalid WebSocket handshake", Body: problem.Problem{}},
+					http.StatusForbidden:          {Description: "Origin is not allowed", Body: problem.Problem{}},
+				},
+			},
+		},
+	}
+}
+
+func (h *Handler) readLoop(ctx context.Context, c *client) {
+	defer c.close()
+
+	for {
+		opcode, message, err := c.conn.ReadMessage()
+		if err != nil {
+			var closeErr *CloseError
+			if !errors.As(err, &closeErr) && !isClosedConnError(err) {
+				slog.WarnContext(ctx, "websocket: read failed", logging.Err(err))
+			}
+			return
+		}
+
+		if opcode != OpText {
+			_ = c.conn.WriteClose(CloseUnsupportedData, "only text messages are supported")
+			return
+		}
+
+		reply := h.execute(ctx, message)
+		encoded, err := json.Marshal(reply)
+		if err != nil {
+			slog.ErrorContext(ctx, "websocket: failed to encode reply", logging.Err(err))
+			continue
+		}
+
+		select {
+		case c.send <- encoded:
+		case <-c.done:
+			return
+		}
+	}
+}
+
+func (h *Handler) writeLoop(ctx context.Context, c *client) {
+	ticker := time.NewTicker(pingPeriod)
+	defer func() {
+		ticker.Stop()
+		c.conn.Close()
+	}()
+
+	for {
+		select {
+		case message := <-c.send:
+			if err := c.conn.WriteMessage(OpText, message); err != nil {
+				c.close()
+				return
+			}
+		case <-ticker.C:
+			if err := c.conn.WritePing(nil); err != nil {
+				c.close()
+				return
+			}
+		case <-ctx.Done():
+			_ = c.conn.WriteClose(CloseGoingAway, "server shutting down")
+			return
+		case <-c.done:
+			_ = c.conn.WriteClose(CloseNormal, "")
+			return
+		}
+	}
+}
+
+func (h *Handler) execute(ctx context.Context, message []byte) Reply {
+	var cmd Command
+	if err := json.Unmarshal(message, &cmd); err != nil {
+		return Reply{Type: "error", Error: "invalid command"}
+	}
+
+	task, err := h.dispatch(ctx, cmd)
+	if err != nil {
+		return Reply{Type: "error", RequestID: cmd.RequestID, Error: errorMessage(err)}
+	}
+
+	return Reply{Type: "result", RequestID: cmd.RequestID, Task: task}
+}
+
+func (h *Handler) dispatch(ctx context.Context, cmd Command) (*dto.TaskResponse, error) {
+	switch cmd.Op {
+	case "create":
+		var req dto.CreateTaskRequest
+		if err := json.Unmarshal(cmd.Data, &req); err != nil {
+			return nil, errInvalidData
+		}
+
+		task, err := h.service.Create(ctx, dto.FromCreateTaskRequest(req))
+		if err != nil {
+			return nil, err
+		}
+		response := dto.ToTaskResponse(&task)
+
+		return &response, nil
+	case "update":
+		var req dto.UpdateTaskRequest
+		if err := json.Unmarshal(cmd.Data, &req); err != nil {
+			return nil, errInvalidData
+		}
+
+		schema, keep := dto.FromUpdateTaskRequest(req)
+		if err := h.service.Update(ctx, cmd.ID, schema, keep...); err != nil {
+			return nil, err
+		}
+
+		task, err := h.service.GetByID(ctx, cmd.ID)
+		if err != nil {
+			return nil, err
+		}
+		response := dto.ToTaskResponse(&task)
+
+		return &response, nil
+	case "delete":
+		if err := h.service.Delete(ctx, cmd.ID); err != nil {
+			return nil, err
+		}
+
+		return nil, nil
+	default:
+		return nil, errUnknownOp
+	}
+}
+
+func isClosedConnError(err error) bool {
+	return errors.Is(err, io.EOF) || errors.Is(err, net.ErrClosed) || errors.Is(err, ErrConnectionDone)
+}
+
+func errorMessage(err error) string {
+	var invalid validation.Errors
+	switch {
+	case errors.As(err, &invalid):
+		return invalid.Error()
+	case errors.Is(err, domain.ErrNotExists):
+		return "task not found"
+	case errors.Is(err, domain.ErrEmptyTitle):
+		return domain.ErrEmptyTitle.Error()
+	case errors.Is(err, errInvalidData), errors.Is(err, errUnknownOp):
+		return err.Error()
+	default:
+		return "internal server error"
+	}
+}
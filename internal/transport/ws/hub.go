@@ -0,0 +1,100 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"sync"
+
+	"github.com/Ant-Tab-Shift/todos-service/internal/domain"
+	"github.com/Ant-Tab-Shift/todos-service/internal/logging"
+	"github.com/Ant-Tab-Shift/todos-service/internal/transport/http/dto"
+)
+
+const sendBufferSize = 64
+
+type Event struct {
+	Type string            `json:"type"`
+	ID   uint64            `json:"id"`
+	Task *dto.TaskResponse `json:"task,omitempty"`
+}
+
+type client struct {
+	conn *Conn
+	send chan []byte
+	done chan struct{}
+	once sync.Once
+}
+
+func (c *client) close() {
+	c.once.Do(func() {
+		close(c.done)
+	})
+}
+
+type Hub struct {
+	mu      sync.RWMutex
+	clients map[*client]struct{}
+}
+
+func NewHub() *Hub {
+	return &Hub{clients: make(map[*client]struct{})}
+}
+
+func (h *Hub) HandleEvent(ctx context.Context, event domain.Event) error {
+	message := Event{
+		Type: string(event.Kind),
+		ID:   event.TaskID,
+	}
+	if event.Kind != domain.TaskDeleted {
+		task := domain.Task{ID: event.TaskID, TaskSchema: event.Task}
+		response := dto.ToTaskResponse(&task)
+		message.Task = &response
+	}
+
+	h.Broadcast(message)
+
+	return nil
+}
+
+func (h *Hub) Broadcast(event Event) {
+	message, err := json.Marshal(event)
+	if err != nil {
+		slog.Error("websocket: failed to encode event", "kind", event.Type, logging.Err(err))
+		return
+	}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for c := range h.clients {
+		select {
+		case c.send <- message:
+		default:
+			// клиент не успевает читать - отключаем, чтобы не блокировать остальных
+			c.close()
+		}
+	}
+}
+
+func (h *Hub) register(conn *Conn) *client {
+	c := &client{
+		conn: conn,
+		send: make(chan []byte, sendBufferSize),
+		done: make(chan struct{}),
+	}
+
+	h.mu.Lock()
+	h.clients[c] = struct{}{}
+	h.mu.Unlock()
+
+	return c
+}
+
+func (h *Hub) unregister(c *client) {
+	h.mu.Lock()
+	delete(h.clients, c)
+	h.mu.Unlock()
+
+	c.close()
+}
@@ -0,0 +1,381 @@
+package ws
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+const acceptGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+const (
+	OpContinuation byte = 0x0
+	OpText         byte = 0x1
+	OpBinary       byte = 0x2
+	OpClose        byte = 0x8
+	OpPing         byte = 0x9
+	OpPong         byte = 0xA
+)
+
+const (
+	CloseNormal          = 1000
+	CloseGoingAway       = 1001
+	CloseProtocolError   = 1002
+	CloseUnsupportedData = 1003
+	CloseNoStatus        = 1005
+	CloseInvalidPayload  = 1007
+	CloseMessageTooBig   = 1009
+)
+
+const (
+	maxControlPayload     = 125
+	defaultMaxMessageSize = 64 << 10
+	writeWait             = 5 * time.Second
+)
+
+var (
+	ErrBadHandshake    = errors.New("websocket: bad handshake")
+	ErrForbiddenOrigin = errors.New("websocket: origin not allowed")
+	ErrProtocol        = errors.New("websocket: protocol error")
+	ErrMessageTooBig   = errors.New("websocket: message too big")
+	ErrConnectionDone  = errors.New("websocket: connection closed")
+)
+
+type CloseError struct {
+	Code   int
+	Reason string
+}
+
+func (e *CloseError) Error() string {
+	return fmt.Sprintf("websocket: closed with code %d %s", e.Code, e.Reason)
+}
+
+type Conn struct {
+	conn           net.Conn
+	br             *bufio.Reader
+	wmu            sync.Mutex
+	closeSent      bool
+	readTimeout    time.Duration
+	maxMessageSize int64
+}
+
+// Upgrade переключает соединение на WebSocket. Браузер не применяет CORS к рукопожатию,
+// поэтому Origin проверяет сам сервер: разрешены запросы без Origin (не из браузера),
+// со своего хоста и те, что одобрит allowOrigin.
+func Upgrade(w http.ResponseWriter, r *http.Request, allowOrigin func(origin string) bool) (*Conn, error) {
+	if r.Method != http.MethodGet {
+		return nil, fmt.Errorf("%w: method must be GET", ErrBadHandshake)
+	}
+	if !headerContainsToken(r.Header, "Connection", "upgrade") {
+		return nil, fmt.Errorf("%w: missing 'Connection: upgrade'", ErrBadHandshake)
+	}
+	if !headerContainsToken(r.Header, "Upgrade", "websocket") {
+		return nil, fmt.Errorf("%w: missing 'Upgrade: websocket'", ErrBadHandshake)
+	}
+	if r.Header.Get("Sec-WebSocket-Version") != "13" {
+		w.Header().Set("Sec-WebSocket-Version", "13")
+		return nil, fmt.Errorf("%w: unsupported version", ErrBadHandshake)
+	}
+
+	key := r.Header.Get("Sec-WebSocket-Key")
+	decoded, err := base64.StdEncoding.DecodeString(key)
+	if err != nil || len(decoded) != 16 {
+		return nil, fmt.Errorf("%w: invalid Sec-WebSocket-Key", ErrBadHandshake)
+	}
+	if origin := r.Header.Get("Origin"); origin != "" && !sameOrigin(origin, r.Host) && (allowOrigin == nil || !allowOrigin(origin)) {
+		return nil, fmt.Errorf("%w: %s", ErrForbiddenOrigin, origin)
+	}
+
+	hj, ok := w.(http.Hijacker)
+	if !ok {
+		return nil, errors.New("websocket: response writer does not support hijacking")
+	}
+
+	netConn, rw, err := hj.Hijack()
+	if err != nil {
+		return nil, fmt.Errorf("websocket: hijack failed: %w", err)
+	}
+
+	// сбрасываем дедлайны, выставленные http.Server для обычных запросов
+	if err = netConn.SetDeadline(time.Time{}); err != nil {
+		netConn.Close()
+		return nil, err
+	}
+
+	response := "HTTP/1.1 101 Switching Protocols\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + acceptKey(key) + "\r\n\r\n"
+	if _, err = netConn.Write([]byte(response)); err != nil {
+		netConn.Close()
+		return nil, err
+	}
+
+	return &Conn{
+		conn:           netConn,
+		br:             rw.Reader,
+		maxMessageSize: defaultMaxMessageSize,
+	}, nil
+}
+
+func (c *Conn) SetReadTimeout(timeout time.Duration) {
+	c.readTimeout = timeout
+}
+
+func (c *Conn) SetMaxMessageSize(size int64) {
+	c.maxMessageSize = size
+}
+
+func (c *Conn) ReadMessage() (byte, []byte, error) {
+	var (
+		opcode  byte
+		message []byte
+	)
+
+	for {
+		fin, op, payload, err := c.readFrame()
+		if err != nil {
+			return 0, nil, c.failRead(err)
+		}
+
+		switch op {
+		case OpPing:
+			if err = c.writeFrame(OpPong, payload); err != nil {
+				return 0, nil, err
+			}
+			continue
+		case OpPong:
+			continue
+		case OpClose:
+			return 0, nil, c.handleClose(payload)
+		case OpText, OpBinary:
+			if opcode != 0 {
+				return 0, nil, c.failRead(fmt.Errorf("%w: new message inside fragmented one", ErrProtocol))
+			}
+			opcode = op
+		case OpContinuation:
+			if opcode == 0 {
+				return 0, nil, c.failRead(fmt.Errorf("%w: unexpected continuation frame", ErrProtocol))
+			}
+		default:
+			return 0, nil, c.failRead(fmt.Errorf("%w: unknown opcode %d", ErrProtocol, op))
+		}
+
+		if int64(len(message)+len(payload)) > c.maxMessageSize {
+			return 0, nil, c.failRead(ErrMessageTooBig)
+		}
+		message = append(message, payload...)
+
+		if fin {
+			if opcode == OpText && !utf8.Valid(message) {
+				_ = c.WriteClose(CloseInvalidPayload, "invalid utf-8")
+				return 0, nil, fmt.Errorf("%w: invalid utf-8 in text message", ErrProtocol)
+			}
+			return opcode, message, nil
+		}
+	}
+}
+
+func (c *Conn) WriteMessage(opcode byte, data []byte) error {
+	if opcode != OpText && opcode != OpBinary {
+		return fmt.Errorf("%w: invalid data opcode %d", ErrProtocol, opcode)
+	}
+
+	return c.writeFrame(opcode, data)
+}
+
+func (c *Conn) WritePing(data []byte) error {
+	return c.writeFrame(OpPing, data)
+}
+
+func (c *Conn) WriteClose(code int, reason string) error {
+	payload := make([]byte, 2, 2+len(reason))
+	binary.BigEndian.PutUint16(payload, uint16(code))
+	payload = append(payload, reason...)
+	if len(payload) > maxControlPayload {
+		payload = payload[:maxControlPayload]
+	}
+
+	return c.writeFrame(OpClose, payload)
+}
+
+func (c *Conn) Close() error {
+	return c.conn.Close()
+}
+
+func (c *Conn) readFrame() (bool, byte, []byte, error) {
+	if c.readTimeout > 0 {
+		if err := c.conn.SetReadDeadline(time.Now().Add(c.readTimeout)); err != nil {
+			return false, 0, nil, err
+		}
+	}
+
+	var head [2]byte
+	if _, err := io.ReadFull(c.br, head[:]); err != nil {
+		return false, 0, nil, err
+	}
+
+	fin := head[0]&0x80 != 0
+	if head[0]&0x70 != 0 {
+		return false, 0, nil, fmt.Errorf("%w: reserved bits set", ErrProtocol)
+	}
+	opcode := head[0] & 0x0F
+
+	if head[1]&0x80 == 0 {
+		return false, 0, nil, fmt.Errorf("%w: client frames must be masked", ErrProtocol)
+	}
+
+	length := uint64(head[1] & 0x7F)
+	switch length {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(c.br, ext[:]); err != nil {
+			return false, 0, nil, err
+		}
+		length = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(c.br, ext[:]); err != nil {
+			return false, 0, nil, err
+		}
+		length = binary.BigEndian.Uint64(ext[:])
+		if length>>63 != 0 {
+			return false, 0, nil, fmt.Errorf("%w: invalid payload length", ErrProtocol)
+		}
+	}
+
+	if opcode >= OpClose && (!fin || length > maxControlPayload) {
+		return false, 0, nil, fmt.Errorf("%w: invalid control frame", ErrProtocol)
+	}
+	if length > uint64(c.maxMessageSize) {
+		return false, 0, nil, ErrMessageTooBig
+	}
+
+	var mask [4]byte
+	if _, err := io.ReadFull(c.br, mask[:]); err != nil {
+		return false, 0, nil, err
+	}
+
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(c.br, payload); err != nil {
+		return false, 0, nil, err
+	}
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+
+	return fin, opcode, payload, nil
+}
+
+func (c *Conn) writeFrame(opcode byte, payload []byte) error {
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+
+	if c.closeSent {
+		return ErrConnectionDone
+	}
+	if opcode == OpClose {
+		c.closeSent = true
+	}
+
+	header := make([]byte, 2, 10)
+	header[0] = 0x80 | opcode
+	switch length := len(payload); {
+	case length <= 125:
+		header[1] = byte(length)
+	case length <= 0xFFFF:
+		header[1] = 126
+		header = binary.BigEndian.AppendUint16(header, uint16(length))
+	default:
+		header[1] = 127
+		header = binary.BigEndian.AppendUint64(header, uint64(length))
+	}
+
+	if err := c.conn.SetWriteDeadline(time.Now().Add(writeWait)); err != nil {
+		return err
+	}
+
+	buffers := net.Buffers{header, payload}
+	_, err := buffers.WriteTo(c.conn)
+
+	return err
+}
+
+func (c *Conn) handleClose(payload []byte) error {
+	closeErr := &CloseError{Code: CloseNoStatus}
+	switch {
+	case len(payload) == 1:
+		_ = c.WriteClose(CloseProtocolError, "")
+		return fmt.Errorf("%w: invalid close payload", ErrProtocol)
+	case len(payload) >= 2:
+		closeErr.Code = int(binary.BigEndian.Uint16(payload))
+		closeErr.Reason = string(payload[2:])
+		if !validCloseCode(closeErr.Code) {
+			_ = c.WriteClose(CloseProtocolError, "")
+			return fmt.Errorf("%w: invalid close code %d", ErrProtocol, closeErr.Code)
+		}
+	}
+
+	if closeErr.Code == CloseNoStatus {
+		_ = c.writeFrame(OpClose, nil)
+	} else {
+		_ = c.WriteClose(closeErr.Code, "")
+	}
+
+	return closeErr
+}
+
+// validCloseCode сообщает, может ли код прийти в кадре закрытия (RFC 6455, 7.4).
+// 1005, 1006 и 1015 только обозначают состояние соединения и по сети не передаются.
+func validCloseCode(code int) bool {
+	switch {
+	case code >= 1000 && code <= 1014:
+		return code != 1004 && code != CloseNoStatus && code != 1006
+	default:
+		return code >= 3000 && code <= 4999
+	}
+}
+
+func (c *Conn) failRead(err error) error {
+	switch {
+	case errors.Is(err, ErrMessageTooBig):
+		_ = c.WriteClose(CloseMessageTooBig, "message too big")
+	case errors.Is(err, ErrProtocol):
+		_ = c.WriteClose(CloseProtocolError, "protocol error")
+	}
+
+	return err
+}
+
+func acceptKey(key string) string {
+	hash := sha1.Sum([]byte(key + acceptGUID))
+	return base64.StdEncoding.EncodeToString(hash[:])
+}
+
+func sameOrigin(origin, host string) bool {
+	u, err := url.Parse(origin)
+	return err == nil && strings.EqualFold(u.Host, host)
+}
+
+func headerContainsToken(header http.Header, name, token string) bool {
+	for _, value := range header.Values(name) {
+		for _, part := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(part), token) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
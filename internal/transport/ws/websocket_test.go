@@ -0,0 +1,367 @@
+package ws
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Ant-Tab-Shift/todos-service/internal/domain"
+)
+
+type mockTaskService struct {
+	tasks  map[uint64]domain.Task
+	nextID uint64
+	hub    *Hub
+}
+
+func newMockTaskService(hub *Hub) *mockTaskService {
+	return &mockTaskService{tasks: make(map[uint64]domain.Task), nextID: 1, hub: hub}
+}
+
+func (m *mockTaskService) Create(ctx context.Context, schema domain.TaskSchema) (domain.Task, error) {
+	if schema.Title == "" {
+		return domain.Task{}, domain.ErrEmptyTitle
+	}
+	task := domain.Task{ID: m.nextID, TaskSchema: schema}
+	m.tasks[task.ID] = task
+	m.nextID++
+	_ = m.hub.HandleEvent(ctx, domain.Event{Kind: domain.TaskCreated, TaskID: task.ID, Task: task.TaskSchema})
+	return task, nil
+}
+
+func (m *mockTaskService) GetByID(ctx context.Context, id uint64) (domain.Task, error) {
+	task, ok := m.tasks[id]
+	if !ok {
+		return domain.Task{}, domain.ErrNotExists
+	}
+	return task, nil
+}
+
+func (m *mockTaskService) Update(ctx context.Context, id uint64, schema domain.TaskSchema, keep ...domain.TaskField) error {
+	if _, ok := m.tasks[id]; !ok {
+		return domain.ErrNotExists
+	}
+	m.tasks[id] = domain.Task{ID: id, TaskSchema: schema}
+	return nil
+}
+
+func (m *mockTaskService) Delete(ctx context.Context, id uint64) error {
+	if _, ok := m.tasks[id]; !ok {
+		return domain.ErrNotExists
+	}
+	delete(m.tasks, id)
+	return nil
+}
+
+type testClient struct {
+	conn net.Conn
+	br   *bufio.Reader
+}
+
+func dial(t *testing.T, url string) *testClient {
+	t.Helper()
+
+	conn, err := net.Dial("tcp", strings.TrimPrefix(url, "http://"))
+	if err != nil {
+		t.Fatalf("dial failed: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	key := "dGhlIHNhbXBsZSBub25jZQ=="
+	request := "GET /ws HTTP/1.1\r\n" +
+		"Host: localhost\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Key: " + key + "\r\n" +
+		"Sec-WebSocket-Version: 13\r\n\r\n"
+	if _, err = conn.Write([]byte(request)); err != nil {
+		t.Fatalf("write handshake failed: %v", err)
+	}
+
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, nil)
+	if err != nil {
+		t.Fatalf("read handshake failed: %v", err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("status = %d, want 101", resp.StatusCode)
+	}
+	if got := resp.Header.Get("Sec-WebSocket-Accept"); got != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Fatalf("Sec-WebSocket-Accept = %q", got)
+	}
+
+	_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	return &testClient{conn: conn, br: br}
+}
+
+func (c *testClient) writeFrame(t *testing.T, fin bool, opcode byte, payload []byte) {
+	t.Helper()
+
+	first := opcode
+	if fin {
+		first |= 0x80
+	}
+	frame := []byte{first}
+	switch {
+	case len(payload) <= 125:
+		frame = append(frame, 0x80|byte(len(payload)))
+	default:
+		frame = append(frame, 0x80|126)
+		frame = binary.BigEndian.AppendUint16(frame, uint16(len(payload)))
+	}
+
+	mask := [4]byte{1, 2, 3, 4}
+	frame = append(frame, mask[:]...)
+	for i, b := range payload {
+		frame = append(frame, b^mask[i%4])
+	}
+
+	if _, err := c.conn.Write(frame); err != nil {
+		t.Fatalf("write frame failed: %v", err)
+	}
+}
+
+func (c *testClient) readFrame(t *testing.T) (byte, []byte) {
+	t.Helper()
+
+	var head [2]byte
+	if _, err := io.ReadFull(c.br, head[:]); err != nil {
+		t.Fatalf("read frame failed: %v", err)
+	}
+	if head[1]&0x80 != 0 {
+		t.Fatal("server frames must not be masked")
+	}
+
+	length := int(head[1] & 0x7F)
+	if length == 126 {
+		var ext [2]byte
+		_, _ = io.ReadFull(c.br, ext[:])
+		length = int(binary.BigEndian.Uint16(ext[:]))
+	}
+
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(c.br, payload); err != nil {
+		t.Fatalf("read payload failed: %v", err)
+	}
+
+	return head[0] & 0x0F, payload
+}
+
+func newTestServer(t *testing.T) (*httptest.Server, *mockTaskService) {
+	t.Helper()
+
+	hub := NewHub()
+	service := newMockTaskService(hub)
+	handler := NewHandler(service, hub, func(origin string) bool { return origin == "https://app.example.com" })
+
+	mux := http.NewServeMux()
+	for _, endpoint := range handler.Handlers() {
+		mux.HandleFunc(endpoint.Pattern, endpoint.Func)
+	}
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	return srv, service
+}
+
+func TestUpgrade_BadHandshake(t *testing.T) {
+	srv, _ := newTestServer(t)
+
+	resp, err := http.Get(srv.URL + "/ws")
+	if err != nil {
+		t.Fatalf("GET failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400", resp.StatusCode)
+	}
+}
+
+func TestUpgrade_Origin(t *testing.T) {
+	srv, _ := newTestServer(t)
+	host := strings.TrimPrefix(srv.URL, "http://")
+
+	tests := []struct {
+		name   string
+		origin string
+		want   int
+	}{
+		{"no origin", "", http.StatusSwitchingProtocols},
+		{"same host", "http://" + host, http.StatusSwitchingProtocols},
+		{"allowed origin", "https://app.example.com", http.StatusSwitchingProtocols},
+		{"other origin", "https://evil.example.com", http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, srv.URL+"/ws", nil)
+			req.Header.Set("Connection", "Upgrade")
+			req.Header.Set("Upgrade", "websocket")
+			req.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+			req.Header.Set("Sec-WebSocket-Version", "13")
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.want {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandler_CreateCommandBroadcastsEvent(t *testing.T) {
+	srv, _ := newTestServer(t)
+
+	actor := dial(t, srv.URL)
+	observer := dial(t, srv.URL)
+
+	// ping гарантирует, что observer уже зарегистрирован в хабе
+	observer.writeFrame(t, true, OpPing, []byte("hi"))
+	if op, payload := observer.readFrame(t); op != OpPong || string(payload) != "hi" {
+		t.Fatalf("got opcode %d payload %q, want pong 'hi'", op, payload)
+	}
+
+	command := []byte(`{"request_id":"r1","op":"create","data":{"title":"Task","description":"Desc"}}`)
+	actor.writeFrame(t, false, OpText, command[:10])
+	actor.writeFrame(t, true, OpContinuation, command[10:])
+
+	var event Event
+	op, payload := observer.readFrame(t)
+	if op != OpText {
+		t.Fatalf("opcode = %d, want text", op)
+	}
+	if err := json.Unmarshal(payload, &event); err != nil {
+		t.Fatalf("decode event failed: %v", err)
+	}
+	if event.Type != string(domain.TaskCreated) || event.ID != 1 || event.Task == nil || event.Task.Title != "Task" {
+		t.Errorf("event = %+v, want task.created for task 1", event)
+	}
+
+	var reply Reply
+	for reply.Type != "result" {
+		_, payload = actor.readFrame(t)
+		if err := json.Unmarshal(payload, &reply); err != nil {
+			t.Fatalf("decode reply failed: %v", err)
+		}
+	}
+	if reply.RequestID != "r1" || reply.Task == nil || reply.Task.ID != 1 {
+		t.Errorf("reply = %+v, want result for r1", reply)
+	}
+}
+
+func TestHandler_CommandErrors(t *testing.T) {
+	srv, _ := newTestServer(t)
+	client := dial(t, srv.URL)
+
+	tests := []struct {
+		command string
+		want    string
+	}{
+		{`{"op":"create","data":{"title":""}}`, domain.ErrEmptyTitle.Error()},
+		{`{"op":"delete","id":42}`, "task not found"},
+		{`{"op":"archive"}`, errUnknownOp.Error()},
+		{`not json`, "invalid command"},
+	}
+
+	for _, tt := range tests {
+		client.writeFrame(t, true, OpText, []byte(tt.command))
+
+		var reply Reply
+		_, payload := client.readFrame(t)
+		if err := json.Unmarshal(payload, &reply); err != nil {
+			t.Fatalf("decode reply failed: %v", err)
+		}
+		if reply.Type != "error" || reply.Error != tt.want {
+			t.Errorf("command %s: reply = %+v, want error %q", tt.command, reply, tt.want)
+		}
+	}
+}
+
+func TestConn_CloseHandshake(t *testing.T) {
+	srv, _ := newTestServer(t)
+	client := dial(t, srv.URL)
+
+	payload := binary.BigEndian.AppendUint16(nil, CloseNormal)
+	client.writeFrame(t, true, OpClose, payload)
+
+	op, reply := client.readFrame(t)
+	if op != OpClose {
+		t.Fatalf("opcode = %d, want close", op)
+	}
+	if code := binary.BigEndian.Uint16(reply); code != CloseNormal {
+		t.Errorf("close code = %d, want %d", code, CloseNormal)
+	}
+}
+
+func TestConn_CloseHandshake_ReplyCode(t *testing.T) {
+	tests := []struct {
+		name string
+		code uint16
+		want uint16
+	}{
+		{"application code", 4000, 4000},
+		{"registered code", 1011, 1011},
+		{"reserved no status", CloseNoStatus, CloseProtocolError},
+		{"reserved abnormal closure", 1006, CloseProtocolError},
+		{"reserved tls handshake", 1015, CloseProtocolError},
+		{"below range", 999, CloseProtocolError},
+		{"unassigned", 2000, CloseProtocolError},
+		{"beyond range", 5000, CloseProtocolError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, _ := newTestServer(t)
+			client := dial(t, srv.URL)
+
+			client.writeFrame(t, true, OpClose, binary.BigEndian.AppendUint16(nil, tt.code))
+
+			op, reply := client.readFrame(t)
+			if op != OpClose {
+				t.Fatalf("opcode = %d, want close", op)
+			}
+			if code := binary.BigEndian.Uint16(reply); code != tt.want {
+				t.Errorf("close code = %d, want %d", code, tt.want)
+			}
+		})
+	}
+}
+
+func TestConn_UnmaskedFrameIsProtocolError(t *testing.T) {
+	srv, _ := newTestServer(t)
+	client := dial(t, srv.URL)
+
+	if _, err := client.conn.Write([]byte{0x81, 0x02, 'h', 'i'}); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+
+	op, reply := client.readFrame(t)
+	if op != OpClose {
+		t.Fatalf("opcode = %d, want close", op)
+	}
+	if code := binary.BigEndian.Uint16(reply); code != CloseProtocolError {
+		t.Errorf("close code = %d, want %d", code, CloseProtocolError)
+	}
+
+	if _, err := client.br.ReadByte(); !errors.Is(err, io.EOF) {
+		t.Errorf("connection should be closed, got %v", err)
+	}
+}
@@ -0,0 +1,225 @@
+package rules
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/Ant-Tab-Shift/todos-service/internal/domain"
+	"github.com/Ant-Tab-Shift/todos-service/internal/validation"
+)
+
+type Config struct {
+	MaxTitleLength                  int    `json:"max_title_length"`
+	MaxDescriptionLength            int    `json:"max_description_length"`
+	ForbiddenCharacters             string `json:"forbidden_characters"`
+	HighPriorityRequiresDescription bool   `json:"high_priority_requires_description"`
+	UniqueOpenTitles                bool   `json:"unique_open_titles"`
+}
+
+func Load(path string) (Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, fmt.Errorf("failed to read validation rules: %w", err)
+	}
+
+	var cfg Config
+	if err = validation.DecodeJSON(data, &cfg); err != nil {
+		return Config{}, fmt.Errorf("invalid validation rules in %s: %w", path, err)
+	}
+	if err = cfg.Validate(); err != nil {
+		return Config{}, fmt.Errorf("invalid validation rules in %s: %w", path, err)
+	}
+
+	return cfg, nil
+}
+
+func (c Config) Validate() error {
+	var v validation.Validator
+
+	v.Check(c.MaxTitleLength >= 0, &validation.FieldError{
+		Field: "max_title_length", Code: validation.CodeInvalidValue, Message: "must not be negative",
+	})
+	v.Check(c.MaxDescriptionLength >= 0, &validation.FieldError{
+		Field: "max_description_length", Code: validation.CodeInvalidValue, Message: "must not be negative",
+	})
+
+	return v.Err()
+}
+
+type TaskLister interface {
+	GetAll(ctx context.Context) ([]domain.Elem[domain.TaskSchema], error)
+}
+
+// Candidate - задача, которую собираются сохранить; ID равен 0 при создании.
+type Candidate struct {
+	ID   uint64
+	Task domain.TaskSchema
+}
+
+// Rule возвращает нарушения в виде FieldError с доменной ошибкой в Err,
+// а error - только при сбое, не позволившем выполнить проверку.
+type Rule func(ctx context.Context, c Candidate) ([]*validation.FieldError, error)
+
+type Engine struct {
+	rules []Rule
+}
+
+// Default содержит только встроенные правила, которые действуют при любой конфигурации.
+func Default() *Engine {
+	return &Engine{rules: []Rule{titleRequired, validPriority, tagsNotBlank}}
+}
+
+func New(cfg Config, tasks TaskLister) (*Engine, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	e := Default()
+	if cfg.MaxTitleLength > 0 {
+		e.rules = append(e.rules, maxLength("title", cfg.MaxTitleLength, domain.ErrTitleTooLong,
+			func(t domain.TaskSchema) string { return t.Title }))
+	}
+	if cfg.MaxDescriptionLength > 0 {
+		e.rules = append(e.rules, maxLength("description", cfg.MaxDescriptionLength, domain.ErrDescriptionTooLong,
+			func(t domain.TaskSchema) string { return t.Description }))
+	}
+	if cfg.ForbiddenCharacters != "" {
+		e.rules = append(e.rules, forbiddenCharacters(cfg.ForbiddenCharacters))
+	}
+	if cfg.HighPriorityRequiresDescription {
+		e.rules = append(e.rules, descriptionRequiredForHighPriority)
+	}
+	if cfg.UniqueOpenTitles {
+		if tasks == nil {
+			return nil, errors.New("unique_open_titles requires a task lister")
+		}
+		e.rules = append(e.rules, uniqueOpenTitle(tasks))
+	}
+
+	return e, nil
+}
+
+func (e *Engine) Validate(ctx context.Context, c Candidate) error {
+	var v validation.Validator
+
+	for _, rule := range e.rules {
+		violations, err := rule(ctx, c)
+		if err != nil {
+			return err
+		}
+		for _, violation := range violations {
+			v.Add(violation)
+		}
+	}
+
+	return v.Err()
+}
+
+func titleRequired(_ context.Context, c Candidate) ([]*validation.FieldError, error) {
+	if validation.NotBlank(c.Task.Title) {
+		return nil, nil
+	}
+
+	return []*validation.FieldError{{
+		Field: "title", Code: validation.CodeRequired, Message: "must not be blank", Err: domain.ErrEmptyTitle,
+	}}, nil
+}
+
+func validPriority(_ context.Context, c Candidate) ([]*validation.FieldError, error) {
+	if c.Task.Priority.Valid() {
+		return nil, nil
+	}
+
+	return []*validation.FieldError{{
+		Field: "priority", Code: validation.CodeInvalidValue, Message: "must be one of low, normal, high", Err: domain.ErrInvalidPriority,
+	}}, nil
+}
+
+func tagsNotBlank(_ context.Context, c Candidate) ([]*validation.FieldError, error) {
+	for _, tag := range c.Task.Tags {
+		if !validation.NotBlank(tag) {
+			return []*validation.FieldError{{
+				Field: "tags", Code: validation.CodeInvalidValue, Message: "must not contain blank tags", Err: domain.ErrBlankTag,
+			}}, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func maxLength(field string, limit int, err error, value func(domain.TaskSchema) string) Rule {
+	return func(_ context.Context, c Candidate) ([]*validation.FieldError, error) {
+		if validation.MaxLength(value(c.Task), limit) {
+			return nil, nil
+		}
+
+		return []*validation.FieldError{{
+			Field: field, Code: validation.CodeTooLong, Message: fmt.Sprintf("must be at most %d characters", limit), Err: err,
+		}}, nil
+	}
+}
+
+func forbiddenCharacters(chars string) Rule {
+	return func(_ context.Context, c Candidate) ([]*validation.FieldError, error) {
+		var violations []*validation.FieldError
+
+		fields := []struct{ name, value string }{
+			{"title", c.Task.Title},
+			{"description", c.Task.Description},
+		}
+		for _, field := range fields {
+			if strings.ContainsAny(field.value, chars) {
+				violations = append(violations, &validation.FieldError{
+					Field:   field.name,
+					Code:    validation.CodeForbiddenCharacters,
+					Message: fmt.Sprintf("must not contain any of %q", chars),
+					Err:     domain.ErrForbiddenCharacters,
+				})
+			}
+		}
+
+		return violations, nil
+	}
+}
+
+func descriptionRequiredForHighPriority(_ context.Context, c Candidate) ([]*validation.FieldError, error) {
+	if c.Task.Priority != domain.PriorityHigh || validation.NotBlank(c.Task.Description) {
+		return nil, nil
+	}
+
+	return []*validation.FieldError{{
+		Field: "description", Code: validation.CodeRequired, Message: "is required for high priority tasks", Err: domain.ErrDescriptionRequired,
+	}}, nil
+}
+
+// uniqueOpenTitle проверяет по снимку хранилища, поэтому два одновременных
+// создания с одним заголовком могут оба пройти проверку.
+func uniqueOpenTitle(tasks TaskLister) Rule {
+	return func(ctx context.Context, c Candidate) ([]*validation.FieldError, error) {
+		if c.Task.IsDone {
+			return nil, nil
+		}
+
+		elems, err := tasks.GetAll(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check title uniqueness: %w", err)
+		}
+
+		title := strings.TrimSpace(c.Task.Title)
+		for _, elem := range elems {
+			if elem.ID == c.ID || elem.Value.IsDone {
+				continue
+			}
+			if strings.EqualFold(strings.TrimSpace(elem.Value.Title), title) {
+				return []*validation.FieldError{{
+					Field: "title", Code: validation.CodeDuplicate, Message: "an open task with this title already exists", Err: domain.ErrDuplicateTitle,
+				}}, nil
+			}
+		}
+
+		return nil, nil
+	}
+}
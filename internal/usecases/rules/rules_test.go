@@ -0,0 +1,159 @@
+package rules
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Ant-Tab-Shift/todos-service/internal/domain"
+	"github.com/Ant-Tab-Shift/todos-service/internal/validation"
+)
+
+type staticLister []domain.Elem[domain.TaskSchema]
+
+func (s staticLister) GetAll(ctx context.Context) ([]domain.Elem[domain.TaskSchema], error) {
+	return s, nil
+}
+
+func TestEngine_Validate(t *testing.T) {
+	existing := staticLister{
+		{ID: 1, Value: domain.TaskSchema{Title: "Buy milk", Priority: domain.PriorityNormal}},
+		{ID: 2, Value: domain.TaskSchema{Title: "Old report", IsDone: true, Priority: domain.PriorityNormal}},
+	}
+	engine, err := New(Config{
+		MaxTitleLength:                  10,
+		MaxDescriptionLength:            20,
+		ForbiddenCharacters:             "<>",
+		HighPriorityRequiresDescription: true,
+		UniqueOpenTitles:                true,
+	}, existing)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		candidate Candidate
+		wantErrs  []error
+	}{
+		{
+			name:      "valid",
+			candidate: Candidate{Task: domain.TaskSchema{Title: "Walk dog", Priority: domain.PriorityLow}},
+		},
+		{
+			name:      "blank title and bad priority",
+			candidate: Candidate{Task: domain.TaskSchema{Title: " ", Priority: "urgent"}},
+			wantErrs:  []error{domain.ErrEmptyTitle, domain.ErrInvalidPriority},
+		},
+		{
+			name:      "too long",
+			candidate: Candidate{Task: domain.TaskSchema{Title: "Very long title", Description: "a description over twenty", Priority: domain.PriorityNormal}},
+			wantErrs:  []error{domain.ErrTitleTooLong, domain.ErrDescriptionTooLong},
+		},
+		{
+			name:      "forbidden characters",
+			candidate: Candidate{Task: domain.TaskSchema{Title: "<b>", Description: "x>y", Priority: domain.PriorityNormal}},
+			wantErrs:  []error{domain.ErrForbiddenCharacters, domain.ErrForbiddenCharacters},
+		},
+		{
+			name:      "blank tag",
+			candidate: Candidate{Task: domain.TaskSchema{Title: "Tagged", Priority: domain.PriorityNormal, Tags: []string{"work", " "}}},
+			wantErrs:  []error{domain.ErrBlankTag},
+		},
+		{
+			name:      "high priority without description",
+			candidate: Candidate{Task: domain.TaskSchema{Title: "Fix prod", Priority: domain.PriorityHigh}},
+			wantErrs:  []error{domain.ErrDescriptionRequired},
+		},
+		{
+			name:      "duplicate open title",
+			candidate: Candidate{Task: domain.TaskSchema{Title: " buy MILK", Priority: domain.PriorityNormal}},
+			wantErrs:  []error{domain.ErrDuplicateTitle},
+		},
+		{
+			name:      "same task keeps its title",
+			candidate: Candidate{ID: 1, Task: domain.TaskSchema{Title: "Buy milk", Priority: domain.PriorityNormal}},
+		},
+		{
+			name:      "done tasks do not count",
+			candidate: Candidate{Task: domain.TaskSchema{Title: "Old report", Priority: domain.PriorityNormal}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := engine.Validate(context.Background(), tt.candidate)
+			if len(tt.wantErrs) == 0 {
+				if err != nil {
+					t.Fatalf("Validate() = %v, want nil", err)
+				}
+				return
+			}
+
+			var errs validation.Errors
+			if !errors.As(err, &errs) {
+				t.Fatalf("Validate() = %v, want validation.Errors", err)
+			}
+			if len(errs) != len(tt.wantErrs) {
+				t.Fatalf("Validate() = %v, want %d violations", errs, len(tt.wantErrs))
+			}
+			for i, want := range tt.wantErrs {
+				if !errors.Is(errs[i], want) {
+					t.Errorf("violation %d = %v, want %v", i, errs[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestDefault_OnlyBuiltinRules(t *testing.T) {
+	task := domain.TaskSchema{Title: "<script>", Priority: domain.PriorityHigh}
+	if err := Default().Validate(context.Background(), Candidate{Task: task}); err != nil {
+		t.Errorf("Validate() = %v, want nil", err)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+
+	valid := filepath.Join(dir, "valid.json")
+	if err := os.WriteFile(valid, []byte(`{"max_title_length": 80, "unique_open_titles": true}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := Load(valid)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.MaxTitleLength != 80 || !cfg.UniqueOpenTitles {
+		t.Errorf("cfg = %+v", cfg)
+	}
+
+	for name, body := range map[string]string{
+		"unknown.json":  `{"max_title_lenght": 80}`,
+		"negative.json": `{"max_description_length": -1}`,
+	} {
+		path := filepath.Join(dir, name)
+		if err = os.WriteFile(path, []byte(body), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err = Load(path); err == nil {
+			t.Errorf("Load(%s) succeeded, want error", name)
+		}
+	}
+
+	if _, err = New(Config{UniqueOpenTitles: true}, nil); err == nil {
+		t.Error("New without lister succeeded, want error")
+	}
+}
+
+func TestLoad_ShippedRulesDisabled(t *testing.T) {
+	cfg, err := Load("../../../configs/validation.json")
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg != (Config{}) {
+		t.Errorf("cfg = %+v, want every optional rule disabled", cfg)
+	}
+}
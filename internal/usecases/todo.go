@@ -1,43 +1,91 @@
 package usecases
 
 import (
+	"cmp"
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
+	"sync/atomic"
+	"time"
 
 	"github.com/Ant-Tab-Shift/todos-service/internal/domain"
-	"github.com/Ant-Tab-Shift/todos-service/internal/usecases/utils"
+	"github.com/Ant-Tab-Shift/todos-service/internal/tracing"
+	"github.com/Ant-Tab-Shift/todos-service/internal/usecases/rules"
+	"github.com/Ant-Tab-Shift/todos-service/internal/validation"
 )
 
 type TaskStorage interface {
-	Save(ctx context.Context, task domain.TaskSchema, id uint64) (uint64, error)
+	Save(ctx context.Context, task domain.TaskSchema, id uint64, record domain.Recorder) (uint64, error)
 	GetByID(ctx context.Context, id uint64) (domain.TaskSchema, error)
+	GetByIDWithRevision(ctx context.Context, id uint64) (domain.TaskSchema, domain.Revision, error)
+	Revision(ctx context.Context) (domain.Revision, error)
 	GetAll(ctx context.Context) ([]domain.Elem[domain.TaskSchema], error)
-	Delete(ctx context.Context, id uint64) error
+	GetPage(ctx context.Context, after uint64, limit int) ([]domain.Elem[domain.TaskSchema], error)
+	Insert(ctx context.Context, task domain.TaskSchema, id uint64, record domain.Recorder) error
+	Delete(ctx context.Context, id uint64, record domain.Recorder) error
+}
+
+// exportPageSize — сколько задач читается из хранилища за одно обращение при выгрузке.
+const exportPageSize = 256
+
+// maxParentDepth ограничивает обход предков: пока он идёт, параллельные запросы
+// могут менять цепочку, и одной проверки на повтор для остановки мало.
+const maxParentDepth = 1000
+
+// EventPublisher принимает события изменения задач. Сервис публикует их через
+// хранилище в той же операции, что и само изменение, поэтому publisher должен быть
+// надёжным, например журналом исходящих событий.
+type EventPublisher interface {
+	Publish(ctx context.Context, event domain.Event) error
 }
 
 type TaskService struct {
-	repo     TaskStorage
+	repo   TaskStorage
+	events EventPublisher
+	rules  atomic.Pointer[rules.Engine]
+}
+
+// NewTaskService создаёт сервис со встроенными правилами; при events == nil события не публикуются.
+func NewTaskService(repo TaskStorage, events EventPublisher) *TaskService {
+	return NewTaskServiceWithRules(repo, events, rules.Default())
 }
 
-func NewTaskService(repo TaskStorage) *TaskService {
-	return &TaskService{repo: repo}
+func NewTaskServiceWithRules(repo TaskStorage, events EventPublisher, engine *rules.Engine) *TaskService {
+	s := &TaskService{repo: repo, events: events}
+	s.rules.Store(engine)
+
+	return s
+}
+
+// SetRules заменяет правила валидации; запросы в процессе обработки
+// дорабатывают с прежними правилами.
+func (s *TaskService) SetRules(engine *rules.Engine) {
+	s.rules.Store(engine)
 }
 
-func (s *TaskService) Create(ctx context.Context, title, description string) (domain.Task, error) {
-	task := domain.TaskSchema{
-		Title: title,
-		Description: description,
-		IsDone: false,
+func (s *TaskService) Create(ctx context.Context, task domain.TaskSchema) (_ domain.Task, err error) {
+	ctx, span := tracing.Start(ctx, "TaskService.Create")
+	defer func() { span.Finish(err) }()
+
+	task.IsDone = false
+	if task.Priority == "" {
+		task.Priority = domain.PriorityNormal
+	}
+
+	err = s.rules.Load().Validate(ctx, rules.Candidate{Task: task})
+	if err == nil {
+		err = s.checkParent(ctx, 0, task.ParentID)
 	}
-	err := utils.Validate(&task)
 	if err != nil {
 		return domain.Task{}, fmt.Errorf("validation failed: %w", err)
 	}
 
-	id, err := s.repo.Save(ctx, task, 0)
+	id, err := s.repo.Save(ctx, task, 0, s.publish(ctx, domain.TaskCreated, task))
 	if err != nil {
 		return domain.Task{}, fmt.Errorf("failed to save task: %w", err)
 	}
+	slog.InfoContext(ctx, "task created", "task_id", id, "priority", task.Priority)
 
 	return domain.Task{
 		ID: id,
@@ -45,7 +93,10 @@ func (s *TaskService) Create(ctx context.Context, title, description string) (do
 	}, nil
 }
 
-func (s *TaskService) GetByID(ctx context.Context, id uint64) (domain.Task, error) {
+func (s *TaskService) GetByID(ctx context.Context, id uint64) (_ domain.Task, err error) {
+	ctx, span := tracing.Start(ctx, "TaskService.GetByID")
+	defer func() { span.Finish(err) }()
+
 	task, err := s.repo.GetByID(ctx, id)
 	if err != nil {
 		return domain.Task{}, err
@@ -57,7 +108,36 @@ func (s *TaskService) GetByID(ctx context.Context, id uint64) (domain.Task, erro
 	}, nil
 }
 
-func (s *TaskService) GetAll(ctx context.Context) ([]domain.Task, error) {
+// GetByIDWithRevision возвращает задачу и её версию для условных запросов.
+func (s *TaskService) GetByIDWithRevision(ctx context.Context, id uint64) (_ domain.Task, _ domain.Revision, err error) {
+	ctx, span := tracing.Start(ctx, "TaskService.GetByIDWithRevision")
+	defer func() { span.Finish(err) }()
+
+	task, revision, err := s.repo.GetByIDWithRevision(ctx, id)
+	if err != nil {
+		return domain.Task{}, domain.Revision{}, err
+	}
+
+	return domain.Task{ID: id, TaskSchema: task}, revision, nil
+}
+
+// Revision возвращает версию списка задач.
+func (s *TaskService) Revision(ctx context.Context) (_ domain.Revision, err error) {
+	ctx, span := tracing.Start(ctx, "TaskService.Revision")
+	defer func() { span.Finish(err) }()
+
+	revision, err := s.repo.Revision(ctx)
+	if err != nil {
+		return domain.Revision{}, fmt.Errorf("failed to get tasks revision: %w", err)
+	}
+
+	return revision, nil
+}
+
+func (s *TaskService) GetAll(ctx context.Context) (_ []domain.Task, err error) {
+	ctx, span := tracing.Start(ctx, "TaskService.GetAll")
+	defer func() { span.Finish(err) }()
+
 	elems, err := s.repo.GetAll(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get tasks: %w", err)
@@ -74,31 +154,213 @@ func (s *TaskService) GetAll(ctx context.Context) ([]domain.Task, error) {
 	return tasks, nil
 }
 
-func (s *TaskService) Update(ctx context.Context, id uint64, title, description string, isDone bool) error {
-	task, err := s.repo.GetByID(ctx, id)
+// Update заменяет задачу id на task; поля из keep остаются прежними.
+func (s *TaskService) Update(ctx context.Context, id uint64, task domain.TaskSchema, keep ...domain.TaskField) (err error) {
+	ctx, span := tracing.Start(ctx, "TaskService.Update")
+	defer func() { span.Finish(err) }()
+
+	current, err := s.repo.GetByID(ctx, id)
 	if err != nil {
 		return err
 	}
 
-	task.Title = title
-	task.Description = description
-	task.IsDone = isDone
+	// клиенты, не знающие о приоритете, не должны сбрасывать его при обновлении
+	if task.Priority == "" {
+		task.Priority = cmp.Or(current.Priority, domain.PriorityNormal)
+	}
+	for _, field := range keep {
+		switch field {
+		case domain.FieldDueDate:
+			task.DueDate = current.DueDate
+		case domain.FieldTags:
+			task.Tags = current.Tags
+		case domain.FieldParentID:
+			task.ParentID = current.ParentID
+		}
+	}
 
-	if err := utils.Validate(&task); err != nil {
+	err = s.rules.Load().Validate(ctx, rules.Candidate{ID: id, Task: task})
+	// прежний родитель мог быть удалён, но это не должно мешать обновлять задачу
+	if err == nil && task.ParentID != current.ParentID {
+		err = s.checkParent(ctx, id, task.ParentID)
+	}
+	if err != nil {
 		return fmt.Errorf("validation failed: %w", err)
 	}
 
-	if _, err := s.repo.Save(ctx, task, id); err != nil {
+	if _, err := s.repo.Save(ctx, task, id, s.publish(ctx, domain.TaskUpdated, task)); err != nil {
 		return fmt.Errorf("failed to update task: %w", err)
 	}
+	slog.InfoContext(ctx, "task updated", "task_id", id, "is_done", task.IsDone)
 
 	return nil
 }
 
-func (s *TaskService) Delete(ctx context.Context, id uint64) error {
-	if err := s.repo.Delete(ctx, id); err != nil {
+func (s *TaskService) Delete(ctx context.Context, id uint64) (err error) {
+	ctx, span := tracing.Start(ctx, "TaskService.Delete")
+	defer func() { span.Finish(err) }()
+
+	if err := s.repo.Delete(ctx, id, s.publish(ctx, domain.TaskDeleted, domain.TaskSchema{})); err != nil {
 		return err
 	}
+	slog.InfoContext(ctx, "task deleted", "task_id", id)
 
 	return nil
 }
+
+// Export передаёт задачи в fn по возрастанию ID. Задачи читаются страницами, и
+// хранилище не блокируется, пока клиент принимает выгрузку, но изменения,
+// сделанные во время неё, могут попасть в выгрузку частично.
+func (s *TaskService) Export(ctx context.Context, fn func(domain.Task) error) (err error) {
+	ctx, span := tracing.Start(ctx, "TaskService.Export")
+	defer func() { span.Finish(err) }()
+
+	var after uint64
+	for {
+		elems, err := s.repo.GetPage(ctx, after, exportPageSize)
+		if err != nil {
+			return fmt.Errorf("failed to read tasks: %w", err)
+		}
+
+		for _, elem := range elems {
+			if err := fn(domain.Task{ID: elem.ID, TaskSchema: elem.Value}); err != nil {
+				return err
+			}
+		}
+
+		if len(elems) < exportPageSize {
+			return nil
+		}
+		after = elems[len(elems)-1].ID
+	}
+}
+
+// Import создаёт задачу из выгрузки, сохраняя её статус. С preserveID задача
+// получает свой прежний ID, и занятый ID даёт domain.ErrAlreadyExists; иначе ID выдаётся заново.
+// С preserveID родитель может появиться позже в той же выгрузке, поэтому его наличие не проверяется.
+func (s *TaskService) Import(ctx context.Context, task domain.Task, preserveID bool) (_ domain.Task, err error) {
+	ctx, span := tracing.Start(ctx, "TaskService.Import")
+	defer func() { span.Finish(err) }()
+
+	if task.Priority == "" {
+		task.Priority = domain.PriorityNormal
+	}
+
+	err = s.rules.Load().Validate(ctx, rules.Candidate{Task: task.TaskSchema})
+	if err == nil && preserveID && task.ParentID == task.ID {
+		err = invalidParent("must not be the task itself")
+	} else if err == nil && !preserveID {
+		err = s.checkParent(ctx, 0, task.ParentID)
+	}
+	if err != nil {
+		return domain.Task{}, fmt.Errorf("validation failed: %w", err)
+	}
+
+	if preserveID {
+		err = s.repo.Insert(ctx, task.TaskSchema, task.ID, s.publish(ctx, domain.TaskCreated, task.TaskSchema))
+	} else {
+		task.ID, err = s.repo.Save(ctx, task.TaskSchema, 0, s.publish(ctx, domain.TaskCreated, task.TaskSchema))
+	}
+	if err != nil {
+		return domain.Task{}, fmt.Errorf("failed to import task: %w", err)
+	}
+	slog.DebugContext(ctx, "task imported", "task_id", task.ID, "preserve_id", preserveID)
+
+	return task, nil
+}
+
+// DetachUnresolvedParents проверяет родителей задач ids после импорта с сохранением ID,
+// когда родитель мог прийти позже подзадачи. Ссылка на отсутствующую задачу или
+// замыкающая цикл снимается; возвращаются ошибки валидации по ID таких задач.
+func (s *TaskService) DetachUnresolvedParents(ctx context.Context, ids []uint64) (_ map[uint64]error, err error) {
+	ctx, span := tracing.Start(ctx, "TaskService.DetachUnresolvedParents")
+	defer func() { span.Finish(err) }()
+
+	detached := make(map[uint64]error)
+	for _, id := range ids {
+		task, err := s.repo.GetByID(ctx, id)
+		if errors.Is(err, domain.ErrNotExists) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to check parent: %w", err)
+		}
+		if task.ParentID == 0 {
+			continue
+		}
+
+		parentErr := s.checkParent(ctx, id, task.ParentID)
+		var invalid validation.Errors
+		if !errors.As(parentErr, &invalid) {
+			if parentErr != nil {
+				return nil, parentErr
+			}
+			continue
+		}
+
+		task.ParentID = 0
+		if _, err = s.repo.Save(ctx, task, id, s.publish(ctx, domain.TaskUpdated, task)); err != nil {
+			return nil, fmt.Errorf("failed to detach task: %w", err)
+		}
+		detached[id] = fmt.Errorf("validation failed: %w", parentErr)
+		slog.WarnContext(ctx, "imported task detached from unresolved parent", "task_id", id)
+	}
+
+	return detached, nil
+}
+
+// checkParent проверяет, что родитель существует и задача id не становится предком самой себя.
+// Как и правила валидации, проверка идёт вне блокировки хранилища и не исключает
+// гонок: два параллельных обновления могут вместе замкнуть цикл.
+func (s *TaskService) checkParent(ctx context.Context, id, parentID uint64) error {
+	seen := make(map[uint64]bool)
+	for ancestor, depth := parentID, 0; ancestor != 0 && !seen[ancestor]; depth++ {
+		if ancestor == id {
+			return invalidParent("must not be the task itself or one of its subtasks")
+		}
+		if depth == maxParentDepth {
+			return invalidParent(fmt.Sprintf("must not be nested deeper than %d levels", maxParentDepth))
+		}
+		seen[ancestor] = true
+
+		parent, err := s.repo.GetByID(ctx, ancestor)
+		if errors.Is(err, domain.ErrNotExists) {
+			if ancestor == parentID {
+				return invalidParent("must refer to an existing task")
+			}
+			// цепочка обрывается на удалённой задаче
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to check parent: %w", err)
+		}
+		ancestor = parent.ParentID
+	}
+
+	return nil
+}
+
+func invalidParent(message string) error {
+	return validation.Errors{{Field: "parent_id", Code: validation.CodeInvalidValue, Message: message, Err: domain.ErrInvalidParent}}
+}
+
+// publish возвращает Recorder, который публикует событие kind для задачи с ID,
+// выбранным хранилищем.
+func (s *TaskService) publish(ctx context.Context, kind domain.EventKind, task domain.TaskSchema) domain.Recorder {
+	if s.events == nil {
+		return nil
+	}
+
+	return func(id uint64) error {
+		return s.events.Publish(ctx, newEvent(kind, id, task))
+	}
+}
+
+func newEvent(kind domain.EventKind, id uint64, task domain.TaskSchema) domain.Event {
+	return domain.Event{
+		Kind:       kind,
+		TaskID:     id,
+		Task:       task,
+		OccurredAt: time.Now(),
+	}
+}
@@ -3,9 +3,12 @@ package usecases
 import (
 	"context"
 	"errors"
+	"reflect"
 	"testing"
+	"time"
 
 	"github.com/Ant-Tab-Shift/todos-service/internal/domain"
+	"github.com/Ant-Tab-Shift/todos-service/internal/usecases/rules"
 )
 
 type mockTaskStorage struct {
@@ -13,13 +16,20 @@ type mockTaskStorage struct {
 	getByIDFunc func(ctx context.Context, id uint64) (domain.TaskSchema, error)
 	getAllFunc  func(ctx context.Context) ([]domain.Elem[domain.TaskSchema], error)
 	deleteFunc  func(ctx context.Context, id uint64) error
+	getPageFunc func(ctx context.Context, after uint64, limit int) ([]domain.Elem[domain.TaskSchema], error)
+	insertFunc  func(ctx context.Context, task domain.TaskSchema, id uint64) error
+	revision    domain.Revision
 }
 
-func (m *mockTaskStorage) Save(ctx context.Context, task domain.TaskSchema, id uint64) (uint64, error) {
-	if m.saveFunc != nil {
-		return m.saveFunc(ctx, task, id)
+func (m *mockTaskStorage) Save(ctx context.Context, task domain.TaskSchema, id uint64, record domain.Recorder) (uint64, error) {
+	if m.saveFunc == nil {
+		return 0, nil
 	}
-	return 0, nil
+	id, err := m.saveFunc(ctx, task, id)
+	if err == nil && record != nil {
+		err = record(id)
+	}
+	return id, err
 }
 
 func (m *mockTaskStorage) GetByID(ctx context.Context, id uint64) (domain.TaskSchema, error) {
@@ -29,6 +39,18 @@ func (m *mockTaskStorage) GetByID(ctx context.Context, id uint64) (domain.TaskSc
 	return domain.TaskSchema{}, nil
 }
 
+func (m *mockTaskStorage) GetByIDWithRevision(ctx context.Context, id uint64) (domain.TaskSchema, domain.Revision, error) {
+	task, err := m.GetByID(ctx, id)
+	if err != nil {
+		return domain.TaskSchema{}, domain.Revision{}, err
+	}
+	return task, m.revision, nil
+}
+
+func (m *mockTaskStorage) Revision(ctx context.Context) (domain.Revision, error) {
+	return m.revision, nil
+}
+
 func (m *mockTaskStorage) GetAll(ctx context.Context) ([]domain.Elem[domain.TaskSchema], error) {
 	if m.getAllFunc != nil {
 		return m.getAllFunc(ctx)
@@ -36,16 +58,54 @@ func (m *mockTaskStorage) GetAll(ctx context.Context) ([]domain.Elem[domain.Task
 	return nil, nil
 }
 
-func (m *mockTaskStorage) Delete(ctx context.Context, id uint64) error {
+func (m *mockTaskStorage) GetPage(ctx context.Context, after uint64, limit int) ([]domain.Elem[domain.TaskSchema], error) {
+	if m.getPageFunc != nil {
+		return m.getPageFunc(ctx, after, limit)
+	}
+	return nil, nil
+}
+
+func (m *mockTaskStorage) Insert(ctx context.Context, task domain.TaskSchema, id uint64, record domain.Recorder) error {
+	if m.insertFunc != nil {
+		if err := m.insertFunc(ctx, task, id); err != nil {
+			return err
+		}
+	}
+	if record != nil {
+		return record(id)
+	}
+	return nil
+}
+
+func (m *mockTaskStorage) Delete(ctx context.Context, id uint64, record domain.Recorder) error {
 	if m.deleteFunc != nil {
-		return m.deleteFunc(ctx, id)
+		if err := m.deleteFunc(ctx, id); err != nil {
+			return err
+		}
+	}
+	if record != nil {
+		return record(id)
+	}
+	return nil
+}
+
+type mockPublisher struct {
+	events []domain.Event
+	err    error
+}
+
+func (m *mockPublisher) Publish(ctx context.Context, event domain.Event) error {
+	if m.err != nil {
+		return m.err
 	}
+	m.events = append(m.events, event)
 	return nil
 }
 
 func TestNewTaskService(t *testing.T) {
 	repo := &mockTaskStorage{}
-	service := NewTaskService(repo)
+	events := &mockPublisher{}
+	service := NewTaskService(repo, events)
 
 	if service == nil {
 		t.Fatal("NewTaskService returned nil")
@@ -53,6 +113,9 @@ func TestNewTaskService(t *testing.T) {
 	if service.repo != repo {
 		t.Error("repo not set correctly")
 	}
+	if service.events != events {
+		t.Error("events not set correctly")
+	}
 }
 
 func TestTaskService_Create_Success(t *testing.T) {
@@ -75,10 +138,10 @@ func TestTaskService_Create_Success(t *testing.T) {
 		},
 	}
 
-	service := NewTaskService(repo)
+	service := NewTaskService(repo, nil)
 	ctx := context.Background()
 
-	task, err := service.Create(ctx, "Test Task", "Test Description")
+	task, err := service.Create(ctx, domain.TaskSchema{Title: "Test Task", Description: "Test Description"})
 	if err != nil {
 		t.Fatalf("Create failed: %v", err)
 	}
@@ -105,10 +168,10 @@ func TestTaskService_Create_ValidationError(t *testing.T) {
 		},
 	}
 
-	service := NewTaskService(repo)
+	service := NewTaskService(repo, nil)
 	ctx := context.Background()
 
-	_, err := service.Create(ctx, "", "Description")
+	_, err := service.Create(ctx, domain.TaskSchema{Title: "", Description: "Description"})
 	if err == nil {
 		t.Error("Create should fail with empty title")
 	}
@@ -122,10 +185,10 @@ func TestTaskService_Create_SaveError(t *testing.T) {
 		},
 	}
 
-	service := NewTaskService(repo)
+	service := NewTaskService(repo, nil)
 	ctx := context.Background()
 
-	_, err := service.Create(ctx, "Valid Title", "Valid Description")
+	_, err := service.Create(ctx, domain.TaskSchema{Title: "Valid Title", Description: "Valid Description"})
 	if err == nil {
 		t.Fatal("Create should fail when Save fails")
 	}
@@ -151,7 +214,7 @@ func TestTaskService_GetByID_Success(t *testing.T) {
 		},
 	}
 
-	service := NewTaskService(repo)
+	service := NewTaskService(repo, nil)
 	ctx := context.Background()
 
 	task, err := service.GetByID(ctx, expectedID)
@@ -181,7 +244,7 @@ func TestTaskService_GetByID_NotFound(t *testing.T) {
 		},
 	}
 
-	service := NewTaskService(repo)
+	service := NewTaskService(repo, nil)
 	ctx := context.Background()
 
 	_, err := service.GetByID(ctx, 999)
@@ -227,7 +290,7 @@ func TestTaskService_GetAll_Success(t *testing.T) {
 		},
 	}
 
-	service := NewTaskService(repo)
+	service := NewTaskService(repo, nil)
 	ctx := context.Background()
 
 	tasks, err := service.GetAll(ctx)
@@ -263,7 +326,7 @@ func TestTaskService_GetAll_Empty(t *testing.T) {
 		},
 	}
 
-	service := NewTaskService(repo)
+	service := NewTaskService(repo, nil)
 	ctx := context.Background()
 
 	tasks, err := service.GetAll(ctx)
@@ -284,7 +347,7 @@ func TestTaskService_GetAll_Error(t *testing.T) {
 		},
 	}
 
-	service := NewTaskService(repo)
+	service := NewTaskService(repo, nil)
 	ctx := context.Background()
 
 	_, err := service.GetAll(ctx)
@@ -333,10 +396,10 @@ func TestTaskService_Update_Success(t *testing.T) {
 		},
 	}
 
-	service := NewTaskService(repo)
+	service := NewTaskService(repo, nil)
 	ctx := context.Background()
 
-	err := service.Update(ctx, taskID, "Updated Title", "Updated Description", true)
+	err := service.Update(ctx, taskID, domain.TaskSchema{Title: "Updated Title", Description: "Updated Description", IsDone: true})
 	if err != nil {
 		t.Fatalf("Update failed: %v", err)
 	}
@@ -349,6 +412,54 @@ func TestTaskService_Update_Success(t *testing.T) {
 	}
 }
 
+func TestTaskService_Update_KeepsFields(t *testing.T) {
+	due := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	current := domain.TaskSchema{Title: "Original", Priority: domain.PriorityHigh, DueDate: due, Tags: []string{"work"}, ParentID: 3}
+
+	var saved domain.TaskSchema
+	repo := &mockTaskStorage{
+		getByIDFunc: func(ctx context.Context, id uint64) (domain.TaskSchema, error) {
+			if id == 10 {
+				return current, nil
+			}
+			return domain.TaskSchema{Title: "Parent"}, nil
+		},
+		saveFunc: func(ctx context.Context, task domain.TaskSchema, id uint64) (uint64, error) {
+			saved = task
+			return id, nil
+		},
+	}
+	service := NewTaskService(repo, nil)
+
+	err := service.Update(context.Background(), 10, domain.TaskSchema{Title: "Updated", Tags: []string{"home"}}, domain.FieldDueDate, domain.FieldParentID)
+	if err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	want := domain.TaskSchema{Title: "Updated", Priority: domain.PriorityHigh, DueDate: due, Tags: []string{"home"}, ParentID: 3}
+	if !reflect.DeepEqual(saved, want) {
+		t.Errorf("saved = %+v, want %+v", saved, want)
+	}
+}
+
+func TestTaskService_Create_ParentChainIsBounded(t *testing.T) {
+	calls := 0
+	repo := &mockTaskStorage{
+		// цепочка без повторов, которая не заканчивается
+		getByIDFunc: func(ctx context.Context, id uint64) (domain.TaskSchema, error) {
+			calls++
+			return domain.TaskSchema{Title: "ancestor", ParentID: id + 1}, nil
+		},
+	}
+
+	_, err := NewTaskService(repo, nil).Create(context.Background(), domain.TaskSchema{Title: "Task", ParentID: 1})
+	if !errors.Is(err, domain.ErrInvalidParent) {
+		t.Errorf("Create error = %v, want ErrInvalidParent", err)
+	}
+	if calls != maxParentDepth {
+		t.Errorf("GetByID called %d times, want %d", calls, maxParentDepth)
+	}
+}
+
 func TestTaskService_Update_NotFound(t *testing.T) {
 	expectedErr := domain.ErrNotExists
 	repo := &mockTaskStorage{
@@ -361,10 +472,10 @@ func TestTaskService_Update_NotFound(t *testing.T) {
 		},
 	}
 
-	service := NewTaskService(repo)
+	service := NewTaskService(repo, nil)
 	ctx := context.Background()
 
-	err := service.Update(ctx, 999, "Title", "Description", false)
+	err := service.Update(ctx, 999, domain.TaskSchema{Title: "Title", Description: "Description", IsDone: false})
 	if err == nil {
 		t.Fatal("Update should fail for non-existent task")
 	}
@@ -389,10 +500,10 @@ func TestTaskService_Update_ValidationError(t *testing.T) {
 		},
 	}
 
-	service := NewTaskService(repo)
+	service := NewTaskService(repo, nil)
 	ctx := context.Background()
 
-	err := service.Update(ctx, taskID, "", "Description", false)
+	err := service.Update(ctx, taskID, domain.TaskSchema{Title: "", Description: "Description", IsDone: false})
 	if err == nil {
 		t.Error("Update should fail with invalid data")
 	}
@@ -414,10 +525,10 @@ func TestTaskService_Update_SaveError(t *testing.T) {
 		},
 	}
 
-	service := NewTaskService(repo)
+	service := NewTaskService(repo, nil)
 	ctx := context.Background()
 
-	err := service.Update(ctx, taskID, "Valid Title", "Valid Description", true)
+	err := service.Update(ctx, taskID, domain.TaskSchema{Title: "Valid Title", Description: "Valid Description", IsDone: true})
 	if err == nil {
 		t.Fatal("Update should fail when Save fails")
 	}
@@ -440,7 +551,7 @@ func TestTaskService_Delete_Success(t *testing.T) {
 		},
 	}
 
-	service := NewTaskService(repo)
+	service := NewTaskService(repo, nil)
 	ctx := context.Background()
 
 	err := service.Delete(ctx, taskID)
@@ -461,7 +572,7 @@ func TestTaskService_Delete_NotFound(t *testing.T) {
 		},
 	}
 
-	service := NewTaskService(repo)
+	service := NewTaskService(repo, nil)
 	ctx := context.Background()
 
 	err := service.Delete(ctx, 999)
@@ -481,7 +592,7 @@ func TestTaskService_Delete_Error(t *testing.T) {
 		},
 	}
 
-	service := NewTaskService(repo)
+	service := NewTaskService(repo, nil)
 	ctx := context.Background()
 
 	err := service.Delete(ctx, 1)
@@ -492,3 +603,294 @@ func TestTaskService_Delete_Error(t *testing.T) {
 		t.Errorf("error = %v, want %v", err, expectedErr)
 	}
 }
+
+func TestTaskService_RecordsEvents(t *testing.T) {
+	repo := &mockTaskStorage{
+		saveFunc: func(ctx context.Context, task domain.TaskSchema, id uint64) (uint64, error) {
+			if id == 0 {
+				return 7, nil
+			}
+			return id, nil
+		},
+		getByIDFunc: func(ctx context.Context, id uint64) (domain.TaskSchema, error) {
+			return domain.TaskSchema{Title: "Original"}, nil
+		},
+	}
+	events := &mockPublisher{}
+	service := NewTaskService(repo, events)
+	ctx := context.Background()
+
+	if _, err := service.Create(ctx, domain.TaskSchema{Title: "Title", Description: "Description"}); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if err := service.Update(ctx, 7, domain.TaskSchema{Title: "Updated", Description: "Description", IsDone: true}); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if err := service.Delete(ctx, 7); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+
+	want := []struct {
+		kind domain.EventKind
+		id   uint64
+	}{
+		{domain.TaskCreated, 7},
+		{domain.TaskUpdated, 7},
+		{domain.TaskDeleted, 7},
+	}
+	if len(events.events) != len(want) {
+		t.Fatalf("len(events) = %d, want %d", len(events.events), len(want))
+	}
+	for i, event := range events.events {
+		if event.Kind != want[i].kind {
+			t.Errorf("events[%d].Kind = %s, want %s", i, event.Kind, want[i].kind)
+		}
+		if event.TaskID != want[i].id {
+			t.Errorf("events[%d].TaskID = %d, want %d", i, event.TaskID, want[i].id)
+		}
+		if event.OccurredAt.IsZero() {
+			t.Errorf("events[%d].OccurredAt not set", i)
+		}
+	}
+	if events.events[1].Task.Title != "Updated" || !events.events[1].Task.IsDone {
+		t.Errorf("update event task = %+v, want updated task", events.events[1].Task)
+	}
+}
+
+func TestTaskService_PublishFailure(t *testing.T) {
+	repo := &mockTaskStorage{
+		saveFunc: func(ctx context.Context, task domain.TaskSchema, id uint64) (uint64, error) {
+			return 7, nil
+		},
+	}
+	expectedErr := errors.New("disk full")
+	service := NewTaskService(repo, &mockPublisher{err: expectedErr})
+
+	if _, err := service.Create(context.Background(), domain.TaskSchema{Title: "Title"}); !errors.Is(err, expectedErr) {
+		t.Errorf("Create error = %v, want %v", err, expectedErr)
+	}
+}
+
+func TestTaskService_AppliesRules(t *testing.T) {
+	stored := map[uint64]domain.TaskSchema{
+		1: {Title: "Buy milk", Priority: domain.PriorityHigh, Description: "2%"},
+	}
+	repo := &mockTaskStorage{
+		saveFunc: func(ctx context.Context, task domain.TaskSchema, id uint64) (uint64, error) {
+			if id == 0 {
+				id = uint64(len(stored) + 1)
+			}
+			stored[id] = task
+			return id, nil
+		},
+		getByIDFunc: func(ctx context.Context, id uint64) (domain.TaskSchema, error) {
+			task, ok := stored[id]
+			if !ok {
+				return domain.TaskSchema{}, domain.ErrNotExists
+			}
+			return task, nil
+		},
+		getAllFunc: func(ctx context.Context) ([]domain.Elem[domain.TaskSchema], error) {
+			elems := make([]domain.Elem[domain.TaskSchema], 0, len(stored))
+			for id, task := range stored {
+				elems = append(elems, domain.Elem[domain.TaskSchema]{ID: id, Value: task})
+			}
+			return elems, nil
+		},
+	}
+
+	engine, err := rules.New(rules.Config{UniqueOpenTitles: true, HighPriorityRequiresDescription: true}, repo)
+	if err != nil {
+		t.Fatalf("rules.New failed: %v", err)
+	}
+	service := NewTaskServiceWithRules(repo, nil, engine)
+	ctx := context.Background()
+
+	if _, err = service.Create(ctx, domain.TaskSchema{Title: "buy milk"}); !errors.Is(err, domain.ErrDuplicateTitle) {
+		t.Errorf("Create duplicate error = %v, want ErrDuplicateTitle", err)
+	}
+
+	task, err := service.Create(ctx, domain.TaskSchema{Title: "Walk dog"})
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if task.Priority != domain.PriorityNormal {
+		t.Errorf("task.Priority = %q, want default %q", task.Priority, domain.PriorityNormal)
+	}
+
+	// приоритет не передан - сохраняется текущий high, поэтому описание обязательно
+	err = service.Update(ctx, 1, domain.TaskSchema{Title: "Buy milk"})
+	if !errors.Is(err, domain.ErrDescriptionRequired) {
+		t.Errorf("Update error = %v, want ErrDescriptionRequired", err)
+	}
+}
+
+func TestTaskService_SetRules(t *testing.T) {
+	service := NewTaskService(&mockTaskStorage{}, nil)
+	ctx := context.Background()
+	long := domain.TaskSchema{Title: "A rather long title"}
+
+	if _, err := service.Create(ctx, long); err != nil {
+		t.Fatalf("Create with default rules failed: %v", err)
+	}
+
+	engine, err := rules.New(rules.Config{MaxTitleLength: 5}, nil)
+	if err != nil {
+		t.Fatalf("rules.New failed: %v", err)
+	}
+	service.SetRules(engine)
+
+	if _, err = service.Create(ctx, long); !errors.Is(err, domain.ErrTitleTooLong) {
+		t.Errorf("Create error after SetRules = %v, want ErrTitleTooLong", err)
+	}
+}
+
+func TestTaskService_Export_ReadsPages(t *testing.T) {
+	total := exportPageSize + 3
+	var afters []uint64
+	repo := &mockTaskStorage{
+		getPageFunc: func(ctx context.Context, after uint64, limit int) ([]domain.Elem[domain.TaskSchema], error) {
+			afters = append(afters, after)
+			var page []domain.Elem[domain.TaskSchema]
+			for id := after + 1; id <= uint64(total) && len(page) < limit; id++ {
+				page = append(page, domain.Elem[domain.TaskSchema]{ID: id, Value: domain.TaskSchema{Title: "task"}})
+			}
+			return page, nil
+		},
+	}
+
+	var ids []uint64
+	err := NewTaskService(repo, nil).Export(context.Background(), func(task domain.Task) error {
+		ids = append(ids, task.ID)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+
+	if len(ids) != total || ids[0] != 1 || ids[len(ids)-1] != uint64(total) {
+		t.Errorf("exported %d tasks from %d to %d, want 1..%d", len(ids), ids[0], ids[len(ids)-1], total)
+	}
+	if len(afters) != 2 || afters[0] != 0 || afters[1] != exportPageSize {
+		t.Errorf("pages requested after %v, want [0 %d]", afters, exportPageSize)
+	}
+}
+
+func TestTaskService_Export_StopsOnCallbackError(t *testing.T) {
+	repo := &mockTaskStorage{
+		getPageFunc: func(ctx context.Context, after uint64, limit int) ([]domain.Elem[domain.TaskSchema], error) {
+			return []domain.Elem[domain.TaskSchema]{{ID: 1}, {ID: 2}}, nil
+		},
+	}
+
+	expectedErr := errors.New("client gone")
+	calls := 0
+	err := NewTaskService(repo, nil).Export(context.Background(), func(task domain.Task) error {
+		calls++
+		return expectedErr
+	})
+	if !errors.Is(err, expectedErr) || calls != 1 {
+		t.Errorf("Export = %v after %d calls, want %v after 1 call", err, calls, expectedErr)
+	}
+}
+
+func TestTaskService_Import(t *testing.T) {
+	t.Run("reassigns id and keeps status", func(t *testing.T) {
+		repo := &mockTaskStorage{
+			saveFunc: func(ctx context.Context, task domain.TaskSchema, id uint64) (uint64, error) {
+				if id != 0 {
+					t.Errorf("Save called with id = %d, want 0", id)
+				}
+				if !task.IsDone || task.Priority != domain.PriorityNormal {
+					t.Errorf("saved task = %+v, want done with normal priority", task)
+				}
+				return 7, nil
+			},
+		}
+
+		events := &mockPublisher{}
+		task, err := NewTaskService(repo, events).Import(context.Background(), domain.Task{ID: 3, TaskSchema: domain.TaskSchema{Title: "Task", IsDone: true}}, false)
+		if err != nil {
+			t.Fatalf("Import failed: %v", err)
+		}
+		if task.ID != 7 {
+			t.Errorf("task.ID = %d, want 7", task.ID)
+		}
+		if len(events.events) != 1 || events.events[0].Kind != domain.TaskCreated || events.events[0].TaskID != 7 {
+			t.Errorf("events = %+v, want one TaskCreated for task 7", events.events)
+		}
+	})
+
+	t.Run("preserves id", func(t *testing.T) {
+		repo := &mockTaskStorage{
+			insertFunc: func(ctx context.Context, task domain.TaskSchema, id uint64) error {
+				if id != 3 {
+					t.Errorf("Insert called with id = %d, want 3", id)
+				}
+				return domain.ErrAlreadyExists
+			},
+		}
+
+		_, err := NewTaskService(repo, nil).Import(context.Background(), domain.Task{ID: 3, TaskSchema: domain.TaskSchema{Title: "Task"}}, true)
+		if !errors.Is(err, domain.ErrAlreadyExists) {
+			t.Errorf("error = %v, want ErrAlreadyExists", err)
+		}
+	})
+
+	t.Run("validates task", func(t *testing.T) {
+		repo := &mockTaskStorage{
+			saveFunc: func(ctx context.Context, task domain.TaskSchema, id uint64) (uint64, error) {
+				t.Error("invalid task must not be saved")
+				return 0, nil
+			},
+		}
+
+		_, err := NewTaskService(repo, nil).Import(context.Background(), domain.Task{TaskSchema: domain.TaskSchema{Title: " "}}, false)
+		if !errors.Is(err, domain.ErrEmptyTitle) {
+			t.Errorf("error = %v, want ErrEmptyTitle", err)
+		}
+	})
+}
+
+func TestTaskService_ParentChecks(t *testing.T) {
+	// 1 ← 2 ← 3, у задачи 4 родитель 9 удалён
+	stored := map[uint64]domain.TaskSchema{
+		1: {Title: "root", Priority: domain.PriorityNormal},
+		2: {Title: "child", Priority: domain.PriorityNormal, ParentID: 1},
+		3: {Title: "grandchild", Priority: domain.PriorityNormal, ParentID: 2},
+		4: {Title: "orphan", Priority: domain.PriorityNormal, ParentID: 9},
+	}
+	repo := &mockTaskStorage{
+		getByIDFunc: func(ctx context.Context, id uint64) (domain.TaskSchema, error) {
+			task, ok := stored[id]
+			if !ok {
+				return domain.TaskSchema{}, domain.ErrNotExists
+			}
+			return task, nil
+		},
+	}
+	service := NewTaskService(repo, nil)
+	ctx := context.Background()
+
+	if _, err := service.Create(ctx, domain.TaskSchema{Title: "new", ParentID: 3}); err != nil {
+		t.Errorf("Create under existing parent failed: %v", err)
+	}
+	if _, err := service.Create(ctx, domain.TaskSchema{Title: "new", ParentID: 42}); !errors.Is(err, domain.ErrInvalidParent) {
+		t.Errorf("Create under missing parent = %v, want ErrInvalidParent", err)
+	}
+	if err := service.Update(ctx, 1, domain.TaskSchema{Title: "root", ParentID: 3}); !errors.Is(err, domain.ErrInvalidParent) {
+		t.Errorf("Update making a cycle = %v, want ErrInvalidParent", err)
+	}
+	if err := service.Update(ctx, 2, domain.TaskSchema{Title: "child", ParentID: 2}); !errors.Is(err, domain.ErrInvalidParent) {
+		t.Errorf("Update to own parent = %v, want ErrInvalidParent", err)
+	}
+	if err := service.Update(ctx, 4, domain.TaskSchema{Title: "orphan renamed", ParentID: 9}); err != nil {
+		t.Errorf("Update keeping a deleted parent failed: %v", err)
+	}
+	if _, err := service.Import(ctx, domain.Task{ID: 8, TaskSchema: domain.TaskSchema{Title: "later parent", ParentID: 10}}, true); err != nil {
+		t.Errorf("Import with a parent from later in the file failed: %v", err)
+	}
+	if _, err := service.Import(ctx, domain.Task{TaskSchema: domain.TaskSchema{Title: "imported", ParentID: 10}}, false); !errors.Is(err, domain.ErrInvalidParent) {
+		t.Errorf("Import under missing parent = %v, want ErrInvalidParent", err)
+	}
+}
@@ -0,0 +1,108 @@
+package validation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+)
+
+// DecodeJSON строго декодирует объект в структуру dst: неизвестные поля и
+// несовпадения типов собираются в Errors по всем полям сразу, а не по первому.
+func DecodeJSON(data []byte, dst any) error {
+	target := reflect.ValueOf(dst)
+	if target.Kind() != reflect.Pointer || target.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("decode target must be a pointer to struct, got %T", dst)
+	}
+	target = target.Elem()
+
+	if len(strings.TrimSpace(string(data))) == 0 {
+		return Errors{{Code: CodeInvalidJSON, Message: "request body must not be empty"}}
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			return Errors{{Code: CodeInvalidJSON, Message: "request body must be a JSON object"}}
+		}
+		return Errors{{Code: CodeInvalidJSON, Message: "request body is not valid JSON"}}
+	}
+	if raw == nil {
+		return Errors{{Code: CodeInvalidJSON, Message: "request body must be a JSON object"}}
+	}
+
+	fields := jsonFields(target.Type())
+
+	var v Validator
+	for _, name := range sortedKeys(raw) {
+		index, ok := fields[name]
+		if !ok {
+			v.Add(&FieldError{Field: name, Code: CodeUnknownField, Message: "unknown field"})
+			continue
+		}
+
+		field := target.FieldByIndex(index)
+		if err := json.Unmarshal(raw[name], field.Addr().Interface()); err != nil {
+			v.Add(&FieldError{Field: name, Code: CodeInvalidType, Message: "must be " + jsonType(field.Type())})
+		}
+	}
+
+	return v.Err()
+}
+
+func jsonFields(t reflect.Type) map[string][]int {
+	fields := make(map[string][]int, t.NumField())
+	for _, field := range reflect.VisibleFields(t) {
+		if !field.IsExported() || field.Anonymous {
+			continue
+		}
+
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fields[name] = field.Index
+	}
+
+	return fields
+}
+
+func jsonType(t reflect.Type) string {
+	if t == reflect.TypeFor[time.Time]() {
+		return "an RFC 3339 timestamp"
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return jsonType(t.Elem())
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "an integer"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	default:
+		return "an object"
+	}
+}
+
+func sortedKeys(m map[string]json.RawMessage) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,83 @@
+package validation
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	CodeRequired            = "required"
+	CodeTooLong             = "too_long"
+	CodeInvalidType         = "invalid_type"
+	CodeInvalidValue        = "invalid_value"
+	CodeUnknownField        = "unknown_field"
+	CodeInvalidJSON         = "invalid_json"
+	CodeForbiddenCharacters = "forbidden_characters"
+	CodeDuplicate           = "duplicate"
+)
+
+type FieldError struct {
+	Field   string
+	Code    string
+	Message string
+	// Err - доменная ошибка, по которой вызывающий код может сделать errors.Is
+	Err error
+}
+
+func (e *FieldError) Error() string {
+	if e.Field == "" {
+		return e.Message
+	}
+	return e.Field + ": " + e.Message
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+type Errors []*FieldError
+
+func (e Errors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+func (e Errors) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i, err := range e {
+		errs[i] = err
+	}
+	return errs
+}
+
+type Validator struct {
+	errs Errors
+}
+
+func (v *Validator) Add(err *FieldError) {
+	v.errs = append(v.errs, err)
+}
+
+func (v *Validator) Check(ok bool, err *FieldError) {
+	if !ok {
+		v.Add(err)
+	}
+}
+
+func (v *Validator) Err() error {
+	if len(v.errs) == 0 {
+		return nil
+	}
+	return v.errs
+}
+
+func NotBlank(value string) bool {
+	return strings.TrimSpace(value) != ""
+}
+
+func MaxLength(value string, limit int) bool {
+	return utf8.RuneCountInString(value) <= limit
+}
@@ -0,0 +1,105 @@
+package validation
+
+import (
+	"errors"
+	"testing"
+)
+
+type request struct {
+	Title  string `json:"title"`
+	IsDone bool   `json:"is_done"`
+	Note   string
+	Secret string `json:"-"`
+}
+
+func TestDecodeJSON(t *testing.T) {
+	tests := []struct {
+		name      string
+		body      string
+		want      request
+		wantCodes map[string]string
+	}{
+		{
+			name: "valid",
+			body: `{"title": "buy milk", "is_done": true, "Note": "2%"}`,
+			want: request{Title: "buy milk", IsDone: true, Note: "2%"},
+		},
+		{
+			name:      "reports every invalid field",
+			body:      `{"title": 42, "is_done": "yes", "owner": "bob", "Secret": "x"}`,
+			wantCodes: map[string]string{"title": CodeInvalidType, "is_done": CodeInvalidType, "owner": CodeUnknownField, "Secret": CodeUnknownField},
+		},
+		{
+			name:      "empty body",
+			body:      "  ",
+			wantCodes: map[string]string{"": CodeInvalidJSON},
+		},
+		{
+			name:      "not an object",
+			body:      `["title"]`,
+			wantCodes: map[string]string{"": CodeInvalidJSON},
+		},
+		{
+			name:      "null",
+			body:      `null`,
+			wantCodes: map[string]string{"": CodeInvalidJSON},
+		},
+		{
+			name:      "trailing data",
+			body:      `{"title": "a"} {}`,
+			wantCodes: map[string]string{"": CodeInvalidJSON},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got request
+			err := DecodeJSON([]byte(tt.body), &got)
+
+			if tt.wantCodes == nil {
+				if err != nil {
+					t.Fatalf("DecodeJSON failed: %v", err)
+				}
+				if got != tt.want {
+					t.Errorf("decoded = %+v, want %+v", got, tt.want)
+				}
+				return
+			}
+
+			var errs Errors
+			if !errors.As(err, &errs) {
+				t.Fatalf("error = %v, want Errors", err)
+			}
+			if len(errs) != len(tt.wantCodes) {
+				t.Fatalf("errors = %v, want %d entries", errs, len(tt.wantCodes))
+			}
+			for _, fieldErr := range errs {
+				if code, ok := tt.wantCodes[fieldErr.Field]; !ok || code != fieldErr.Code {
+					t.Errorf("unexpected error for field %q: code %q", fieldErr.Field, fieldErr.Code)
+				}
+			}
+		})
+	}
+}
+
+func TestValidator(t *testing.T) {
+	sentinel := errors.New("title is blank")
+
+	var v Validator
+	v.Check(NotBlank("ok"), &FieldError{Field: "title", Code: CodeRequired})
+	if err := v.Err(); err != nil {
+		t.Fatalf("Err() = %v, want nil", err)
+	}
+
+	v.Check(NotBlank(" \t"), &FieldError{Field: "title", Code: CodeRequired, Message: "must not be blank", Err: sentinel})
+	v.Check(MaxLength("абв", 2), &FieldError{Field: "description", Code: CodeTooLong, Message: "too long"})
+	v.Check(MaxLength("абв", 3), &FieldError{Field: "description", Code: CodeTooLong})
+
+	err := v.Err()
+	if !errors.Is(err, sentinel) {
+		t.Errorf("errors.Is(%v, sentinel) = false", err)
+	}
+	if got, want := err.Error(), "title: must not be blank; description: too long"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+}